@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/thiagolcmelo/webcrawler/src/analysis"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+	"github.com/thiagolcmelo/webcrawler/src/sitemap"
+)
+
+var (
+	analyzeFormat  string
+	analyzeOutput  string
+	analyzeSitemap string
+)
+
+// analyzeCmd represents the analyze command
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze [flags] crawl-output",
+	Short: "It analyses the link graph of a finished crawl",
+	Long: `It analyses the link graph of a finished crawl
+The crawl output must be written by get in the json, json-formatted or ndjson formats, or be
+the bare array of pages of older versions, pages at depth zero are taken as the seeds.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		f, err := os.Open(args[0])
+		if err != nil {
+			fmt.Printf("could not open crawl output: %v\n", err)
+			return
+		}
+		defer f.Close()
+
+		r, err := report.Read(f)
+		if err != nil {
+			fmt.Printf("could not read crawl output: %v\n", err)
+			return
+		}
+
+		var sitemapURLs []string
+		if analyzeSitemap != "" {
+			sitemapURLs, err = sitemap.Load(analyzeSitemap)
+			if err != nil {
+				fmt.Printf("could not load sitemap: %v\n", err)
+				return
+			}
+		}
+
+		a := analysis.Analyze(report.Links(r.Pages), report.Seeds(r.Pages), sitemapURLs)
+
+		var outputWriter io.Writer = os.Stdout
+		if analyzeOutput != "" {
+			out, err := os.OpenFile(analyzeOutput, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			if err != nil {
+				fmt.Printf("could not open output: %v\n", err)
+				return
+			}
+			defer out.Close()
+			outputWriter = out
+		}
+
+		if err := writeAnalysis(outputWriter, a, analyzeFormat); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
+
+// writeAnalysis writes the analysis as text, json or json-formatted
+func writeAnalysis(w io.Writer, a analysis.Analysis, format string) error {
+	switch format {
+	case "text":
+		return a.WriteText(w)
+	case "json":
+		return json.NewEncoder(w).Encode(a)
+	case "json-formatted":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(a)
+	default:
+		return fmt.Errorf("unknown format [%s], it can be text, json or json-formatted", format)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(analyzeCmd)
+	analyzeCmd.Flags().StringVarP(&analyzeFormat, "format", "f", "text", "output format can be text, json or json-formatted")
+	analyzeCmd.Flags().StringVarP(&analyzeOutput, "output", "o", "", "filename to write output to, if empty, it will print to stdout")
+	analyzeCmd.Flags().StringVarP(&analyzeSitemap, "sitemap", "s", "", "sitemap file or url whose urls no page links to are listed as orphans")
+}
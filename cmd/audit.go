@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thiagolcmelo/webcrawler/src"
+	"github.com/thiagolcmelo/webcrawler/src/audit"
+	"github.com/thiagolcmelo/webcrawler/src/memory"
+)
+
+var (
+	auditConfig  string
+	auditFormat  string
+	auditOutput  string
+	auditRetries int
+	auditTimeout time.Duration
+	auditVerbose bool
+	auditWorkers int
+)
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit [flags] domain",
+	Short: "It crawls a domain and checks its pages against SEO rules",
+	Long: `It crawls a domain and checks its pages against SEO rules
+The domain must be provided as a position argument. The command exits with status 1
+when any finding is an error and with status 2 when the audit cannot run, like when the
+crawl stored no pages or the domain itself failed.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// a wrong format is reported before the crawl instead of after it
+		if err := checkAuditFormat(auditFormat); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		rules, err := auditRules(auditConfig)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		if !auditVerbose {
+			log.SetOutput(io.Discard)
+		}
+
+		result, err := runAudit(cmd.Context(), args[0], rules)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		if err := writeAuditResult(result); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if result.HasErrors() {
+			os.Exit(1)
+		}
+	},
+}
+
+// runAudit crawls a domain and checks its pages against the rules, it fails when the crawl
+// stored nothing to audit, so a site that is down does not pass
+func runAudit(ctx context.Context, domain string, rules []audit.Rule) (audit.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, auditTimeout)
+	defer cancel()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	orchestrator := src.NewOrchestrator(
+		ctx,
+		auditWorkers,
+		memory.NewFrontier(),
+		memory.NewStorage(),
+		memory.NewEvents(),
+		auditRetries,
+		500*time.Millisecond,
+		2,
+	)
+	orchestrator.GracePeriod(5 * time.Second)
+	orchestrator.Start(domain)
+	orchestrator.Drain()
+	if orchestrator.IsPartial() {
+		fmt.Fprintln(os.Stderr, "the crawl was interrupted, only the pages stored so far were audited")
+	}
+
+	r := orchestrator.Report(false)
+	if err := audit.Crawled(r, orchestrator.Seeds()); err != nil {
+		return audit.Result{}, err
+	}
+	site := audit.Site{Pages: r.Pages, Statuses: orchestrator.Statuses()}
+	return audit.Run(site, rules), nil
+}
+
+// auditRules returns the built-in rules configured by the file, if any
+func auditRules(filename string) ([]audit.Rule, error) {
+	if filename == "" {
+		return audit.Builtins(), nil
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not open audit config: %w", err)
+	}
+	defer f.Close()
+
+	config, err := audit.LoadConfig(f, filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read audit config: %w", err)
+	}
+	return audit.Configure(audit.Builtins(), config)
+}
+
+// checkAuditFormat fails when the format is not one the audit result can be written in
+func checkAuditFormat(format string) error {
+	switch format {
+	case "text", "json", "json-formatted":
+		return nil
+	default:
+		return fmt.Errorf("unknown format [%s], it can be text, json or json-formatted", format)
+	}
+}
+
+// writeAuditResult writes the audit result to the output in the requested format
+func writeAuditResult(result audit.Result) error {
+	var w io.Writer = os.Stdout
+	if auditOutput != "" {
+		f, err := os.OpenFile(auditOutput, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch auditFormat {
+	case "text":
+		return result.WriteText(w)
+	case "json":
+		return json.NewEncoder(w).Encode(result)
+	case "json-formatted":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(result)
+	default:
+		return checkAuditFormat(auditFormat)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.Flags().StringVarP(&auditConfig, "config", "c", "", "YAML or JSON file overriding the severity (off, notice, warning or error) and limit of the rules")
+	auditCmd.Flags().StringVarP(&auditFormat, "format", "f", "text", "output format can be text, json or json-formatted")
+	auditCmd.Flags().StringVarP(&auditOutput, "output", "o", "", "filename to write output to, if empty, it will print to stdout")
+	auditCmd.Flags().IntVarP(&auditRetries, "retries", "r", 1, "how many times the client should attempt to retry a failed request per individual download")
+	auditCmd.Flags().DurationVarP(&auditTimeout, "timeout", "t", 10*time.Second, "for how long the webcrawler will explore the domain")
+	auditCmd.Flags().BoolVarP(&auditVerbose, "verbose", "v", false, "use it to print logs")
+	auditCmd.Flags().IntVarP(&auditWorkers, "workers", "w", 3, "number of concurrent workers")
+}
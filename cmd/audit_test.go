@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/thiagolcmelo/webcrawler/src/audit"
+)
+
+func TestRunAudit_ServerDown(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	retries := auditRetries
+	defer func() { auditRetries = retries }()
+	auditRetries = 1
+	if _, err := runAudit(context.Background(), server.URL, audit.Builtins()); !errors.Is(err, audit.ErrNothingToAudit) {
+		t.Errorf("expected %v, got %v", audit.ErrNothingToAudit, err)
+	}
+}
+
+func TestCheckAuditFormat(t *testing.T) {
+	type testCase struct {
+		testName string
+		format   string
+		valid    bool
+	}
+
+	testCases := []testCase{
+		{testName: "text", format: "text", valid: true},
+		{testName: "json", format: "json", valid: true},
+		{testName: "json_formatted", format: "json-formatted", valid: true},
+		{testName: "unknown", format: "csv", valid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if err := checkAuditFormat(tc.format); (err == nil) != tc.valid {
+				t.Errorf("expected format [%s] to be valid %v, got error %v", tc.format, tc.valid, err)
+			}
+		})
+	}
+}
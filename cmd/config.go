@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/thiagolcmelo/webcrawler/src/config"
+)
+
+// configCmd groups the commands about config files
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "It works with the config files taken by get",
+}
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:   "validate config-file",
+	Short: "It reports unknown, invalid or conflicting keys in a config file",
+	Long: `It reports unknown, invalid or conflicting keys in a config file
+The file and every profile in it are checked, on top of the built in profile with the same
+name if any. The command exits with status 1 when any problem is found.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		f, err := config.ReadFile(args[0])
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		problems := f.Check()
+		for _, problem := range problems {
+			fmt.Printf("%s: %v\n", f.Name, problem)
+		}
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+		fmt.Printf("%s: ok\n", f.Name)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+}
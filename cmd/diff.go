@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/thiagolcmelo/webcrawler/src/diff"
+)
+
+var (
+	diffFormat     string
+	diffOutput     string
+	diffThresholds = diff.NoThresholds()
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff [flags] old-output new-output",
+	Short: "It compares two crawls of a site",
+	Long: `It compares two crawls of a site
+The crawl outputs can be anything ui reads: json, json-formatted or ndjson written by get, a job
+report kept by serve or a WARC archive. It lists the pages added and removed, the status, content,
+title and description changes and the links added and removed. The command exits with status 1
+when a threshold is crossed and with status 2 when the crawls cannot be compared.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		old, err := readResults(cmd.Context(), args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		newer, err := readResults(cmd.Context(), args[1])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		d := diff.Compare(old, newer)
+		if err := writeDiff(d); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		violations := d.Violations(diffThresholds)
+		for _, violation := range violations {
+			fmt.Fprintf(os.Stderr, "threshold crossed: %s\n", violation)
+		}
+		if len(violations) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// writeDiff writes the diff to the output in the requested format
+func writeDiff(d diff.Diff) error {
+	var w io.Writer = os.Stdout
+	if diffOutput != "" {
+		f, err := os.OpenFile(diffOutput, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch diffFormat {
+	case "text":
+		return d.WriteText(w)
+	case "json":
+		return json.NewEncoder(w).Encode(d)
+	case "json-formatted":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(d)
+	default:
+		return fmt.Errorf("unknown format [%s], it can be text, json or json-formatted", diffFormat)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringVarP(&diffFormat, "format", "f", "text", "output format can be text, json or json-formatted")
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", "", "filename to write output to, if empty, it will print to stdout")
+	diffCmd.Flags().IntVar(&diffThresholds.Removed, "max-removed", diffThresholds.Removed, "most pages that can be removed, negative for no limit")
+	diffCmd.Flags().IntVar(&diffThresholds.Regressions, "max-regressions", diffThresholds.Regressions, "most pages that can start failing with a 4xx or 5xx status, negative for no limit")
+	diffCmd.Flags().IntVar(&diffThresholds.ContentChanges, "max-content-changes", diffThresholds.ContentChanges, "most pages whose content can change, negative for no limit")
+	diffCmd.Flags().IntVar(&diffThresholds.EdgesRemoved, "max-links-removed", diffThresholds.EdgesRemoved, "most links between pages that can be removed, negative for no limit")
+}
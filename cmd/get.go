@@ -2,113 +2,483 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
-	"time"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/thiagolcmelo/webcrawler/src"
+	"github.com/thiagolcmelo/webcrawler/src/basic"
+	"github.com/thiagolcmelo/webcrawler/src/config"
+	"github.com/thiagolcmelo/webcrawler/src/events"
+	"github.com/thiagolcmelo/webcrawler/src/hooks"
+	"github.com/thiagolcmelo/webcrawler/src/jsonl"
 	"github.com/thiagolcmelo/webcrawler/src/memory"
+	"github.com/thiagolcmelo/webcrawler/src/metrics"
+	"github.com/thiagolcmelo/webcrawler/src/progress"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+	"github.com/thiagolcmelo/webcrawler/src/scraper"
+	"github.com/thiagolcmelo/webcrawler/src/sitemap"
+	"github.com/thiagolcmelo/webcrawler/src/warc"
 )
 
-var (
-	backoff           time.Duration
-	backoffMultiplier int
-	format            string
-	output            string
-	retries           int
-	timeout           time.Duration
-	verbose           bool
-	workers           int
-)
+// newGetCmd creates the get command, its flags are bound to a config of its own
+func newGetCmd() *cobra.Command {
+	cfg := config.Default()
+	var configFile, profile string
+
+	getCmd := &cobra.Command{
+		Use:   "get [flags] domain...",
+		Short: "It triggers the webcrawler to explore one or more domains",
+		Long: `It triggers the webcrawler to explore one or more domains
+The domains must be provided as position arguments or listed in a seeds file, one per
+line, each one crawled within its own host. Options are taken from the flags,
+then from WEBCRAWLER_* environment variables, then from the profile and then from the
+config file, e.g. WEBCRAWLER_GRACE_PERIOD=10s sets --grace-period.`,
+		Args: cobra.ArbitraryArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := resolveConfig(&cfg, cmd.Flags(), configFile, profile, os.Environ()); err != nil {
+				fmt.Println(err)
+				return
+			}
 
-// getCmd represents the get command
-var getCmd = &cobra.Command{
-	Use:   "get [flags] domain",
-	Short: "It triggers the webcrawler to explore a domain",
-	Long: `It triggers the webcrawler to explore a domain
-The domain must be provided as a position argument.`,
-	Args: cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		seed := args[0]
-		if seed == "" {
-			fmt.Println("expected a domain to explore")
-			return
-		}
-
-		if format != "json" && format != "json-formatted" && format != "raw" {
-			fmt.Println("output format can be json, json-formatted or raw")
-			return
-		}
-
-		if !verbose {
-			log.SetOutput(io.Discard)
-		}
-
-		ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
-		defer cancel()
-
-		frontier := memory.NewFrontier()
-		storage := memory.NewStorage()
-		events := memory.NewEvents()
-
-		orchestrator := src.NewOrchestrator(
-			ctx,
-			workers,
-			frontier,
-			storage,
-			events,
-			retries,
-			backoff,
-			backoffMultiplier,
-		)
-		orchestrator.Start(seed)
-
-		if output != "" {
-			// open output file
-			outputWriter, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE, 0600)
+			seeds, err := collectSeeds(args, cfg.SeedsFile, cmd.InOrStdin())
 			if err != nil {
-				panic(err)
+				fmt.Println(err)
+				return
 			}
-			// close fo on exit and check for its returned error
-			defer func() {
-				if err := outputWriter.Close(); err != nil {
-					panic(err)
-				}
+			if len(seeds) == 0 {
+				fmt.Println("expected a domain to explore")
+				return
+			}
+
+			ctx, interrupt := context.WithCancelCause(cmd.Context())
+			defer interrupt(nil)
+
+			// the first signal interrupts the crawl, the second one forces the exit
+			signals := make(chan os.Signal, 2)
+			signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+			defer signal.Stop(signals)
+			go func() {
+				sig := <-signals
+				fmt.Fprintf(os.Stderr, "received %v, finishing in-flight urls (send it again to force exit)\n", sig)
+				interrupt(fmt.Errorf("received %v", sig))
+				<-signals
+				os.Exit(130)
 			}()
 
-			switch format {
-			case "raw":
-				orchestrator.PrintReport(outputWriter, false, false)
-			case "json":
-				orchestrator.PrintReport(outputWriter, true, false)
-			case "json-formatted":
-				orchestrator.PrintReport(outputWriter, true, true)
+			if err := runGet(ctx, cfg, seeds, os.Stdout, os.Stderr); err != nil {
+				fmt.Println(err)
 			}
-		} else {
-			switch format {
-			case "raw":
-				orchestrator.PrintReport(os.Stdout, false, false)
-			case "json":
-				orchestrator.PrintReport(os.Stdout, true, false)
-			case "json-formatted":
-				orchestrator.PrintReport(os.Stdout, true, true)
+		},
+	}
+
+	flags := getCmd.Flags()
+	flags.StringVarP(&configFile, "config", "c", os.Getenv(config.EnvPrefix+"CONFIG"), "yaml, toml or json file with the options of the crawl, see config validate")
+	flags.StringVar(&profile, "profile", os.Getenv(config.EnvPrefix+"PROFILE"), "named set of options, polite, audit or one from the config file")
+	flags.BoolVar(&cfg.Analysis, "analysis", cfg.Analysis, "include the link graph analysis (PageRank, inlinks, outlinks, click depth, dead ends and orphans) in the output")
+	flags.StringVar(&cfg.AnalysisSitemap, "analysis-sitemap", cfg.AnalysisSitemap, "sitemap file or url whose urls no page links to are listed as orphans by --analysis")
+	flags.DurationVarP(&cfg.Backoff, "backoff", "b", cfg.Backoff, "how long the client should wait before attempting a retry after a failed request")
+	flags.IntVarP(&cfg.BackoffMultiplier, "backoff-multiplier", "m", cfg.BackoffMultiplier, "how much the backoff duration should increase between each retry attempt")
+	flags.StringVarP(&cfg.EventsOutput, "events-output", "e", cfg.EventsOutput, "filename to stream events to as JSON lines, - for stdout, disabled if empty")
+	flags.StringVarP(&cfg.Format, "format", "f", cfg.Format, "output format can be json, json-formatted, raw (dummy tree structure), csv (a row per page), csv-edges (a row per link), ndjson, dot, graphml, gexf or sitemap (sitemap.xml)")
+	flags.DurationVarP(&cfg.GracePeriod, "grace-period", "g", cfg.GracePeriod, "how long in-flight urls are awaited after the crawl is interrupted or times out")
+	flags.BoolVar(&cfg.IgnoreScheme, "ignore-scheme", cfg.IgnoreScheme, "treat the http and https variants of a url as the same url, crawled under the scheme its host answered first")
+	flags.StringVar(&cfg.MetricsAddr, "metrics-addr", cfg.MetricsAddr, "address to serve Prometheus metrics under /metrics, e.g. :9090, disabled if empty")
+	flags.StringVarP(&cfg.Output, "output", "o", cfg.Output, "filename to write output to, if empty, it will print to stdout")
+	flags.BoolVarP(&cfg.Progress, "progress", "p", cfg.Progress, "show the crawl progress on stderr")
+	flags.StringVar(&cfg.ScrapeRules, "scrape-rules", cfg.ScrapeRules, "JSON file mapping url patterns to fields scraped with CSS selectors or XPath expressions")
+	flags.StringVarP(&cfg.SeedsFile, "seeds-file", "i", cfg.SeedsFile, "file listing domains to explore besides the arguments, one per line, - for stdin")
+	flags.StringVar(&cfg.SitemapBaseURL, "sitemap-base-url", cfg.SitemapBaseURL, "where sitemap parts are hosted when the sitemap index is needed, defaults to the seed root")
+	flags.IntVarP(&cfg.Retries, "retries", "r", cfg.Retries, "how many times the client should attempt to retry a failed request per individual download")
+	flags.BoolVar(&cfg.Sorted, "sorted", cfg.Sorted, "write csv, csv-edges and ndjson sorted by url once the crawl finishes instead of streaming pages as they are stored")
+	flags.BoolVar(&cfg.Stats, "stats", cfg.Stats, "include a summary of the crawl statistics in the output, on stderr for csv, csv-edges and ndjson")
+	flags.DurationVarP(&cfg.Timeout, "timeout", "t", cfg.Timeout, "for how long the webcrawler will explore the domain")
+	flags.BoolVarP(&cfg.Verbose, "verbose", "v", cfg.Verbose, "use it to print logs")
+	flags.StringVar(&cfg.WARC, "warc", cfg.WARC, "filename to archive every request and response to in the WARC 1.1 format, gzip compressed per record, disabled if empty")
+	flags.StringVar(&cfg.Webhook, "webhook", cfg.Webhook, "url to post the events of the crawl to as JSON batches, disabled if empty")
+	flags.IntVar(&cfg.WebhookBatchSize, "webhook-batch-size", cfg.WebhookBatchSize, "most events posted to the webhook at once")
+	flags.DurationVar(&cfg.WebhookFlush, "webhook-flush-interval", cfg.WebhookFlush, "longest an event waits for its batch to fill up before being posted to the webhook")
+	flags.IntVar(&cfg.WebhookMinStatus, "webhook-min-status", cfg.WebhookMinStatus, "only post events with a status code of at least this to the webhook, like 500 for server errors")
+	flags.IntVar(&cfg.WebhookRetries, "webhook-retries", cfg.WebhookRetries, "how many times a batch is posted again after a network error, a 429 or a 5xx response")
+	flags.StringVar(&cfg.WebhookStages, "webhook-stages", cfg.WebhookStages, "comma separated stages posted to the webhook, on-discover, after-download, after-parse, on-store and on-error, all if empty")
+	flags.IntVarP(&cfg.Workers, "workers", "w", cfg.Workers, "number of concurrent workers")
+	return getCmd
+}
+
+// resolveConfig fills the options not given as flags from the environment, the profile and the config file,
+// in this order of precedence, and checks that they do not contradict each other
+func resolveConfig(cfg *config.Config, flags *pflag.FlagSet, configFile string, profile string, environ []string) error {
+	f := &config.File{}
+	if configFile != "" {
+		var err error
+		if f, err = config.ReadFile(configFile); err != nil {
+			return err
+		}
+	}
+	values, err := f.ValuesFor(profile)
+	if err != nil {
+		return err
+	}
+	for key, value := range config.Env(environ) {
+		values[key] = value
+	}
+
+	changed := func(key string) bool {
+		flag := flags.Lookup(key)
+		return flag != nil && flag.Changed
+	}
+	if err := cfg.Apply(values, changed); err != nil {
+		return err
+	}
+	return cfg.Validate()
+}
+
+// collectSeeds lists the seeds given as arguments followed by the ones in the seeds file, or in
+// stdin when the file is -, blank lines and lines starting with # are skipped
+func collectSeeds(args []string, seedsFile string, stdin io.Reader) ([]string, error) {
+	seeds := []string{}
+	for _, arg := range args {
+		if arg = strings.TrimSpace(arg); arg != "" {
+			seeds = append(seeds, arg)
+		}
+	}
+	if seedsFile == "" {
+		return seeds, nil
+	}
+
+	r := stdin
+	if seedsFile != "-" {
+		f, err := os.Open(seedsFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not open seeds file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			seeds = append(seeds, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read seeds file: %w", err)
+	}
+	return seeds, nil
+}
+
+// runGet crawls the seeds with the options of the config and writes the output, stdout is
+// where the output goes unless a file is given and stderr gets the progress and summaries
+func runGet(ctx context.Context, cfg config.Config, seeds []string, stdout io.Writer, stderr io.Writer) error {
+	if cfg.Format == "sitemap" && len(sitemapHosts(seeds)) > 1 {
+		return fmt.Errorf("the sitemap format takes seeds of a single host, got %s", strings.Join(sitemapHosts(seeds), ", "))
+	}
+
+	var scrapeFields []string
+	var pageScraper *basic.Scraper
+	if cfg.ScrapeRules != "" {
+		var err error
+		pageScraper, scrapeFields, err = loadScraper(cfg.ScrapeRules)
+		if err != nil {
+			return err
+		}
+	}
+
+	var reportWriter report.Writer
+	if cfg.Format != "sitemap" {
+		var err error
+		reportWriter, err = report.NewWriter(cfg.Format, scrapeFields...)
+		if err != nil {
+			return fmt.Errorf("%v or sitemap", err)
+		}
+	}
+
+	if !cfg.Verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	var sitemapURLs []string
+	if cfg.AnalysisSitemap != "" {
+		var err error
+		sitemapURLs, err = sitemap.Load(cfg.AnalysisSitemap)
+		if err != nil {
+			return fmt.Errorf("could not load sitemap: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	frontier := memory.NewFrontier()
+	storage := memory.NewStorage()
+	var baseEvents events.Events = memory.NewEvents()
+	if cfg.EventsOutput != "" {
+		eventsWriter := stdout
+		if cfg.EventsOutput != "-" {
+			f, err := os.OpenFile(cfg.EventsOutput, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			if err != nil {
+				return fmt.Errorf("could not open events output: %w", err)
 			}
+			defer f.Close()
+			eventsWriter = f
+		}
+		baseEvents = events.NewTee(baseEvents, jsonl.NewEvents(eventsWriter))
+	}
+
+	counter := progress.NewCounter(baseEvents, len(seeds))
+
+	var crawlEvents events.Events = counter
+	if cfg.MetricsAddr != "" {
+		metricsEvents := metrics.NewEvents(counter)
+		crawlEvents = metricsEvents
+		server := serveMetrics(cfg.MetricsAddr, metricsEvents)
+		defer server.Close()
+	}
+
+	orchestrator := src.NewOrchestrator(
+		ctx,
+		cfg.Workers,
+		frontier,
+		storage,
+		crawlEvents,
+		cfg.Retries,
+		cfg.Backoff,
+		cfg.BackoffMultiplier,
+	)
+
+	outputWriter := stdout
+	if cfg.Output != "" {
+		f, err := os.OpenFile(cfg.Output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("could not open output: %w", err)
+		}
+		// close the output on exit and check for its returned error
+		defer func() {
+			if err := f.Close(); err != nil {
+				fmt.Fprintf(stderr, "could not close output: %v\n", err)
+			}
+		}()
+		outputWriter = f
+	}
+
+	if pageScraper != nil {
+		orchestrator.Scrape(pageScraper)
+	}
+
+	if cfg.IgnoreScheme {
+		orchestrator.IgnoreScheme()
+	}
+
+	if cfg.Webhook != "" {
+		stages, err := hooks.ParseStages(cfg.WebhookStages)
+		if err != nil {
+			return err
 		}
-	},
+		webhook := hooks.NewWebhook(cfg.Webhook, hooks.WebhookOptions{
+			BatchSize:     cfg.WebhookBatchSize,
+			FlushInterval: cfg.WebhookFlush,
+			Retries:       cfg.WebhookRetries,
+		})
+		webhook.Subscribe(orchestrator.Hook, stages, cfg.WebhookMinStatus)
+		// the crawl is drained before the deferred call, so every event is posted
+		defer func() {
+			if err := webhook.Close(); err != nil {
+				fmt.Fprintln(stderr, err)
+			}
+		}()
+	}
+
+	if cfg.WARC != "" {
+		f, err := os.OpenFile(cfg.WARC, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("could not open warc output: %w", err)
+		}
+		defer f.Close()
+		archive := warc.NewWriter(f)
+		if err := archive.WriteInfo(warcInfo(seeds)); err != nil {
+			return fmt.Errorf("could not write warc output: %w", err)
+		}
+		orchestrator.Archive(archive)
+	}
+
+	// formats that can be streamed are written as pages are stored, unless sorted
+	streaming := false
+	if !cfg.Sorted {
+		if streamWriter, err := report.NewStreamWriter(cfg.Format, outputWriter, scrapeFields...); err == nil {
+			orchestrator.Stream(streamWriter)
+			streaming = true
+		}
+	}
+
+	var reporter *progress.Reporter
+	if cfg.Progress {
+		// logs would break a line redrawn in place, so verbose runs get log lines
+		f, isFile := stderr.(*os.File)
+		reporter = progress.NewReporter(stderr, counter, cfg.Timeout, isFile && isTerminal(f) && !cfg.Verbose)
+		reporter.Start()
+	}
+
+	orchestrator.GracePeriod(cfg.GracePeriod)
+	orchestrator.Start(seeds...)
+	orchestrator.Drain()
+
+	if reporter != nil {
+		reporter.Stop()
+	}
+
+	if cfg.Format == "sitemap" {
+		var partWriter sitemap.PartWriter
+		if cfg.Output != "" {
+			// sitemap parts are written next to the sitemap index
+			partWriter = func(name string) (io.WriteCloser, error) {
+				return os.OpenFile(filepath.Join(filepath.Dir(cfg.Output), name), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			}
+		}
+		return orchestrator.PrintSitemap(outputWriter, sitemapBase(seeds[0], cfg.SitemapBaseURL), filepath.Base(cfg.Output), partWriter)
+	}
+
+	var err error
+	if streaming {
+		err = orchestrator.FlushStream()
+	} else {
+		r := orchestrator.Report(cfg.Stats)
+		if cfg.Analysis {
+			a := orchestrator.Analyze(sitemapURLs)
+			r.Analysis = &a
+		}
+		err = reportWriter.Write(outputWriter, r)
+	}
+
+	if isStreamFormat(cfg.Format) {
+		printStreamSummary(stderr, orchestrator, cfg, sitemapURLs)
+	}
+	return err
+}
+
+// loadScraper reads a scraping rules file, it returns the scraper and the names of the fields it scrapes
+func loadScraper(filename string) (*basic.Scraper, []string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open scrape rules: %w", err)
+	}
+	defer f.Close()
+
+	rules, err := scraper.LoadRules(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read scrape rules: %w", err)
+	}
+	s, err := basic.NewScraper(rules)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not compile scrape rules: %w", err)
+	}
+	return s, rules.FieldNames(), nil
+}
+
+// warcInfo describes the crawl in the warcinfo record that opens the WARC output, with its seeds
+func warcInfo(seeds []string) map[string]string {
+	return map[string]string{
+		"software":    "webcrawler",
+		"format":      "WARC File Format 1.1",
+		"conformsTo":  "http://iipc.github.io/warc-specifications/specifications/warc-format/warc-1.1/",
+		"description": fmt.Sprintf("crawl of %s", strings.Join(seeds, ", ")),
+		// replay starts from every seed
+		"seeds": strings.Join(seeds, " "),
+	}
+}
+
+// isStreamFormat informs if a format writes only pages, with no room for the crawl state or stats
+func isStreamFormat(format string) bool {
+	for _, f := range report.StreamFormats() {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// printStreamSummary writes what stream formats leave out, whether the crawl is partial, its stats and analysis
+func printStreamSummary(w io.Writer, orchestrator *src.Orchestrator, cfg config.Config, sitemapURLs []string) {
+	if orchestrator.IsPartial() {
+		fmt.Fprintln(w, "partial report: the crawl was interrupted before all urls were processed")
+	}
+	if cfg.Stats {
+		if err := orchestrator.Stats().WriteText(w); err != nil {
+			fmt.Fprintln(w, err)
+		}
+	}
+	if cfg.Analysis {
+		if err := orchestrator.Analyze(sitemapURLs).WriteText(w); err != nil {
+			fmt.Fprintln(w, err)
+		}
+	}
+}
+
+// sitemapBase is where sitemap parts are expected to be hosted, the seed root unless provided
+func sitemapBase(seed string, baseURL string) string {
+	if baseURL != "" {
+		return baseURL
+	}
+	u, err := url.Parse(seed)
+	if err != nil || u.Host == "" {
+		// seeds without scheme are parsed as a path
+		return fmt.Sprintf("https://%s", seed)
+	}
+	return fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+}
+
+// sitemapHosts lists the distinct hosts of the seeds, in the order they are given
+func sitemapHosts(seeds []string) []string {
+	hosts := []string{}
+	seen := map[string]bool{}
+	for _, seed := range seeds {
+		// seeds without scheme are parsed as a path
+		host := strings.SplitN(seed, "/", 2)[0]
+		if u, err := url.Parse(seed); err == nil && u.Host != "" {
+			host = u.Hostname()
+		}
+		if !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// serveMetrics exposes the metrics in the Prometheus text format under /metrics
+func serveMetrics(addr string, handler http.Handler) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", handler)
+	server := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "metrics server failed: %v\n", err)
+		}
+	}()
+	return server
+}
+
+// isTerminal informs if a file is a character device, like an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
 
 func init() {
-	rootCmd.AddCommand(getCmd)
-	getCmd.Flags().DurationVarP(&backoff, "backoff", "b", 500*time.Millisecond, "how long the client should wait before attempting a retry after a failed request")
-	getCmd.Flags().IntVarP(&backoffMultiplier, "backoff-multiplier", "m", 2, "how much the backoff duration should increase between each retry attempt")
-	getCmd.Flags().StringVarP(&format, "format", "f", "json", "output format can be json, json-formatted or raw (dummy tree structure)")
-	getCmd.Flags().StringVarP(&output, "output", "o", "", "filename to write output to, if empty, it will print to stdout")
-	getCmd.Flags().IntVarP(&retries, "retries", "r", 1, "how many times the client should attempt to retry a failed request per individual download")
-	getCmd.Flags().DurationVarP(&timeout, "timeout", "t", 10*time.Second, "for how long the webcrawler will explore the domain")
-	getCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "use it to print logs")
-	getCmd.Flags().IntVarP(&workers, "workers", "w", 3, "number of concurrent workers")
+	rootCmd.AddCommand(newGetCmd())
 }
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/config"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+)
+
+func TestResolveConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "crawl.yaml")
+	data := "workers: 2\nretries: 2\nbackoff: 1s\ntimeout: 1m\nprofiles:\n  polite:\n    retries: 5\n"
+	if err := os.WriteFile(configFile, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newGetCmd()
+	if err := cmd.Flags().Parse([]string{"--workers", "9"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.Workers = 9
+	environ := []string{"WEBCRAWLER_BACKOFF=3s"}
+	if err := resolveConfig(&cfg, cmd.Flags(), configFile, "polite", environ); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := config.Default()
+	expected.Workers = 9                // flag
+	expected.Backoff = 3 * time.Second  // environment
+	expected.Retries = 5                // profile in the file
+	expected.Timeout = 10 * time.Minute // built in profile
+	expected.BackoffMultiplier = 2      // built in profile
+	if diff := cmp.Diff(expected, cfg); diff != "" {
+		t.Errorf("unexpected config (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestResolveConfig_Errors(t *testing.T) {
+	type testCase struct {
+		testName    string
+		profile     string
+		environ     []string
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{testName: "unknown_profile", profile: "fast", expectedErr: config.ErrUnknownProfile},
+		{testName: "unknown_env_key", environ: []string{"WEBCRAWLER_COLOUR=red"}, expectedErr: config.ErrUnknownKey},
+		{testName: "conflicting_env", environ: []string{"WEBCRAWLER_SORTED=true"}, expectedErr: config.ErrConflictingKeys},
+		{testName: "webhook_stages_without_webhook", environ: []string{"WEBCRAWLER_WEBHOOK_STAGES=on-error"}, expectedErr: config.ErrConflictingKeys},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			cfg := config.Default()
+			err := resolveConfig(&cfg, newGetCmd().Flags(), "", tc.profile, tc.environ)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestRunGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if r.RequestURI == "/" {
+			w.Write([]byte(`<a href="/page">page</a>`))
+			return
+		}
+		w.Write([]byte(`<a href="/">home</a>`))
+	}))
+	defer server.Close()
+
+	cfg := config.Default()
+	cfg.Stats = true
+
+	var stdout, stderr bytes.Buffer
+	if err := runGet(context.Background(), cfg, []string{server.URL}, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+
+	var r report.Report
+	if err := json.Unmarshal(stdout.Bytes(), &r); err != nil {
+		t.Fatal(err)
+	}
+	actual := []string{}
+	for _, page := range r.Pages {
+		actual = append(actual, page.URL)
+	}
+	expected := []string{fmt.Sprintf("%s/", server.URL), fmt.Sprintf("%s/page", server.URL)}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected pages (-expected +actual):\n%s", diff)
+	}
+	if r.Stats == nil {
+		t.Errorf("expected stats in the report")
+	}
+	// progress is off by default, so scripts and cron jobs get a quiet stderr
+	if stderr.Len() != 0 {
+		t.Errorf("expected nothing on stderr, got %q", stderr.String())
+	}
+}
+
+func TestCollectSeeds(t *testing.T) {
+	seedsFile := filepath.Join(t.TempDir(), "seeds.txt")
+	if err := os.WriteFile(seedsFile, []byte("# shops\nhttps://b.com/\n\n  https://c.com/  \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		testName  string
+		args      []string
+		seedsFile string
+		stdin     string
+		expected  []string
+	}
+
+	testCases := []testCase{
+		{testName: "arguments", args: []string{"https://a.com/", "https://b.com/"}, expected: []string{"https://a.com/", "https://b.com/"}},
+		{testName: "arguments_and_file", args: []string{"https://a.com/"}, seedsFile: seedsFile, expected: []string{"https://a.com/", "https://b.com/", "https://c.com/"}},
+		{testName: "stdin", seedsFile: "-", stdin: "https://d.com/\n# skipped\nhttps://e.com/", expected: []string{"https://d.com/", "https://e.com/"}},
+		{testName: "nothing", expected: []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			actual, err := collectSeeds(tc.args, tc.seedsFile, strings.NewReader(tc.stdin))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected seeds (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunGet_SitemapOfSeveralHosts(t *testing.T) {
+	cfg := config.Default()
+	cfg.Format = "sitemap"
+	var stdout, stderr bytes.Buffer
+	if err := runGet(context.Background(), cfg, []string{"https://a.com/", "b.com"}, &stdout, &stderr); err == nil {
+		t.Errorf("expected an error for a sitemap of several hosts")
+	}
+}
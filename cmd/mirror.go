@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thiagolcmelo/webcrawler/src"
+	"github.com/thiagolcmelo/webcrawler/src/memory"
+	"github.com/thiagolcmelo/webcrawler/src/mirror"
+)
+
+var (
+	mirrorGracePeriod time.Duration
+	mirrorNoPrune     bool
+	mirrorRetries     int
+	mirrorTimeout     time.Duration
+	mirrorVerbose     bool
+	mirrorWorkers     int
+)
+
+// mirrorCmd represents the mirror command
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror [flags] domain directory",
+	Short: "It saves a domain to a local directory that can be browsed offline",
+	Long: `It saves a domain to a local directory that can be browsed offline
+The pages and the resources they load are written with their links rewritten to relative
+paths. Running it again on the same directory updates the mirror, asking the server whether
+each url changed since it was mirrored, writing only the files that changed and removing the
+ones no longer found, unless the crawl is interrupted.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		seed, dir := args[0], args[1]
+
+		m, err := mirror.Open(dir)
+		if err != nil {
+			fmt.Printf("could not open mirror: %v\n", err)
+			return
+		}
+
+		if !mirrorVerbose {
+			log.SetOutput(io.Discard)
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), mirrorTimeout)
+		defer cancel()
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		storage := memory.NewStorage()
+		orchestrator := src.NewOrchestrator(
+			ctx,
+			mirrorWorkers,
+			memory.NewFrontier(),
+			storage,
+			memory.NewEvents(),
+			mirrorRetries,
+			500*time.Millisecond,
+			2,
+		)
+		orchestrator.UseDownloader(mirror.NewDownloader(m, mirrorRetries, 500*time.Millisecond, 2))
+		orchestrator.FollowResources()
+		orchestrator.GracePeriod(mirrorGracePeriod)
+		orchestrator.Start(seed)
+		orchestrator.Drain()
+
+		// an interrupted crawl misses pages that still exist, so they are kept
+		prune := !mirrorNoPrune && !orchestrator.IsPartial()
+		summary, err := m.Update(storage.GetAllContent(), prune)
+		if err != nil {
+			fmt.Printf("could not update mirror: %v\n", err)
+			return
+		}
+		if orchestrator.IsPartial() {
+			fmt.Println("partial mirror: the crawl was interrupted before all urls were processed")
+		}
+		fmt.Println(summary)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mirrorCmd)
+	mirrorCmd.Flags().DurationVarP(&mirrorGracePeriod, "grace-period", "g", 5*time.Second, "how long in-flight urls are awaited after the crawl is interrupted or times out")
+	mirrorCmd.Flags().BoolVar(&mirrorNoPrune, "no-prune", false, "keep the files of urls no longer found instead of removing them")
+	mirrorCmd.Flags().IntVarP(&mirrorRetries, "retries", "r", 1, "how many times the client should attempt to retry a failed request per individual download")
+	mirrorCmd.Flags().DurationVarP(&mirrorTimeout, "timeout", "t", time.Minute, "for how long the webcrawler will explore the domain")
+	mirrorCmd.Flags().BoolVarP(&mirrorVerbose, "verbose", "v", false, "use it to print logs")
+	mirrorCmd.Flags().IntVarP(&mirrorWorkers, "workers", "w", 3, "number of concurrent workers")
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thiagolcmelo/webcrawler/src"
+	"github.com/thiagolcmelo/webcrawler/src/memory"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+	"github.com/thiagolcmelo/webcrawler/src/warc"
+)
+
+var (
+	replayFormat    string
+	replayOutput    string
+	replayVerbose   bool
+	replayWithStats bool
+	replayWorkers   int
+)
+
+// replayCmd represents the replay command
+var replayCmd = &cobra.Command{
+	Use:   "replay [flags] file.warc.gz",
+	Short: "It re-runs a crawl from a WARC archive without network access",
+	Long: `It re-runs a crawl from a WARC archive without network access
+The archive must be written by get with --warc, the crawl starts again from the seeds it
+lists, or from the first url archived when it lists none, and urls missing from the archive
+fail as if they could not be downloaded.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		reportWriter, err := report.NewWriter(replayFormat)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if !replayVerbose {
+			log.SetOutput(io.Discard)
+		}
+
+		orchestrator, err := replayArchive(cmd.Context(), args[0], replayWorkers)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		var outputWriter io.Writer = os.Stdout
+		if replayOutput != "" {
+			out, err := os.OpenFile(replayOutput, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			if err != nil {
+				fmt.Printf("could not open output: %v\n", err)
+				return
+			}
+			defer out.Close()
+			outputWriter = out
+		}
+
+		if err := orchestrator.PrintReport(outputWriter, reportWriter, replayWithStats); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
+
+// replayArchive crawls a WARC archive again from its seeds, without network access
+func replayArchive(ctx context.Context, filename string, workers int) (*src.Orchestrator, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not open archive: %w", err)
+	}
+	archive, err := warc.Load(f)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("could not read archive: %w", err)
+	}
+	if len(archive.URLs()) == 0 {
+		return nil, errors.New("the archive has no responses to replay")
+	}
+
+	orchestrator := src.NewOrchestrator(
+		ctx,
+		workers,
+		memory.NewFrontier(),
+		memory.NewStorage(),
+		memory.NewEvents(),
+		1,
+		time.Duration(0),
+		1,
+	)
+	orchestrator.UseDownloader(archive)
+	orchestrator.Start(archive.Seeds()...)
+	return orchestrator, nil
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+	replayCmd.Flags().StringVarP(&replayFormat, "format", "f", "json", "output format can be json, json-formatted, raw, csv, csv-edges, ndjson, dot, graphml or gexf")
+	replayCmd.Flags().StringVarP(&replayOutput, "output", "o", "", "filename to write output to, if empty, it will print to stdout")
+	replayCmd.Flags().BoolVar(&replayWithStats, "stats", false, "include a summary of the replay statistics in the output")
+	replayCmd.Flags().BoolVarP(&replayVerbose, "verbose", "v", false, "use it to print logs")
+	replayCmd.Flags().IntVarP(&replayWorkers, "workers", "w", 3, "number of concurrent workers")
+}
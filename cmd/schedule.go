@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thiagolcmelo/webcrawler/src/jobs"
+	"github.com/thiagolcmelo/webcrawler/src/schedule"
+)
+
+var (
+	scheduleDataDir string
+	scheduleMaxJobs int
+	scheduleNow     bool
+	scheduleVerbose bool
+	scheduleFormat  string
+)
+
+// scheduleCmd groups the commands about scheduled crawls
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "It crawls jobs on cron schedules and keeps a history of their runs",
+}
+
+// scheduleRunCmd represents the schedule run command
+var scheduleRunCmd = &cobra.Command{
+	Use:   "run [flags] schedule-file",
+	Short: "It runs the jobs of a schedule file when their schedules match",
+	Long: `It runs the jobs of a schedule file when their schedules match
+The schedule file is JSON with a retention and the jobs, each with a name, a cron schedule
+and the seeds, profile and options a job of serve takes. Every run is recorded in the data
+directory with its stats and its diff to the previous finished run of the same job, and runs
+older than the retention are pruned along with their results.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !scheduleVerbose {
+			log.SetOutput(io.Discard)
+		}
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			fmt.Printf("could not open schedule file: %v\n", err)
+			return
+		}
+		cfg, err := schedule.LoadConfig(f)
+		f.Close()
+		if err != nil {
+			fmt.Printf("could not read schedule file: %v\n", err)
+			return
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		store, err := jobs.OpenStore(filepath.Join(scheduleDataDir, "jobs"))
+		if err != nil {
+			fmt.Printf("could not open data directory: %v\n", err)
+			return
+		}
+		history, err := schedule.OpenHistory(filepath.Join(scheduleDataDir, "history"))
+		if err != nil {
+			fmt.Printf("could not open data directory: %v\n", err)
+			return
+		}
+		manager, err := jobs.NewManager(context.Background(), store, scheduleMaxJobs)
+		if err != nil {
+			fmt.Printf("could not load jobs: %v\n", err)
+			return
+		}
+		defer manager.Close()
+
+		scheduler, err := schedule.NewScheduler(manager, history, cfg)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		notify := func(run schedule.Run, err error) {
+			if err != nil {
+				fmt.Printf("%s: %v\n", run.Name, err)
+				return
+			}
+			fmt.Println(runSummary(run))
+		}
+
+		if scheduleNow {
+			for _, e := range cfg.Jobs {
+				notify(scheduler.Trigger(ctx, e.Name))
+			}
+		}
+
+		next := scheduler.Next(time.Now())
+		for _, e := range cfg.Jobs {
+			fmt.Printf("%s: next run at %s\n", e.Name, next[e.Name].Format(time.RFC3339))
+		}
+		scheduler.Run(ctx, notify)
+	},
+}
+
+// scheduleHistoryCmd represents the schedule history command
+var scheduleHistoryCmd = &cobra.Command{
+	Use:   "history [flags] [name]",
+	Short: "It lists the runs of the scheduled jobs, or of one of them",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		history, err := schedule.OpenHistory(filepath.Join(scheduleDataDir, "history"))
+		if err != nil {
+			fmt.Printf("could not open data directory: %v\n", err)
+			return
+		}
+
+		names := args
+		if len(names) == 0 {
+			if names, err = history.Names(); err != nil {
+				fmt.Printf("could not read history: %v\n", err)
+				return
+			}
+		}
+		runs := []schedule.Run{}
+		for _, name := range names {
+			named, err := history.Runs(name)
+			if err != nil {
+				fmt.Printf("could not read history: %v\n", err)
+				return
+			}
+			runs = append(runs, named...)
+		}
+		sort.SliceStable(runs, func(i, j int) bool { return runs[i].Job.Created.Before(runs[j].Job.Created) })
+
+		if err := writeRuns(os.Stdout, runs, scheduleFormat); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
+
+// writeRuns writes the runs as text, json or json-formatted
+func writeRuns(w io.Writer, runs []schedule.Run, format string) error {
+	switch format {
+	case "text":
+		for _, run := range runs {
+			if _, err := fmt.Fprintln(w, runSummary(run)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		return json.NewEncoder(w).Encode(runs)
+	case "json-formatted":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(runs)
+	default:
+		return fmt.Errorf("unknown format [%s], it can be text, json or json-formatted", format)
+	}
+}
+
+// runSummary describes a run in a line, with its diff counts when it was compared
+func runSummary(run schedule.Run) string {
+	job := run.Job
+	parts := []string{fmt.Sprintf("%s: run %s %s", run.Name, job.ID, job.State)}
+	if job.Started != nil {
+		parts[0] += " at " + job.Started.Local().Format(time.RFC3339)
+	}
+	if job.Started != nil && job.Finished != nil {
+		parts = append(parts, fmt.Sprintf("took %s", job.Finished.Sub(*job.Started).Round(time.Millisecond)))
+	}
+	if job.Error != "" {
+		parts = append(parts, job.Error)
+	}
+	parts = append(parts, fmt.Sprintf("%d stored, %d failed", job.Progress.Stored, job.Progress.Failed))
+	if run.Diff != nil {
+		c := run.Diff.Counts
+		parts = append(parts, fmt.Sprintf("since %s: %d added, %d removed, %d status changes, %d content changes",
+			run.Previous, c.Added, c.Removed, c.StatusChanges, c.ContentChanges))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+	scheduleCmd.AddCommand(scheduleRunCmd)
+	scheduleCmd.AddCommand(scheduleHistoryCmd)
+	scheduleCmd.PersistentFlags().StringVarP(&scheduleDataDir, "data-dir", "d", ".webcrawler-schedule", "directory where runs and their results are kept")
+	scheduleRunCmd.Flags().IntVarP(&scheduleMaxJobs, "max-jobs", "j", 2, "how many jobs can run at a time, the others are queued")
+	scheduleRunCmd.Flags().BoolVar(&scheduleNow, "now", false, "run every job once right away, before waiting for their schedules")
+	scheduleRunCmd.Flags().BoolVarP(&scheduleVerbose, "verbose", "v", false, "use it to print logs")
+	scheduleHistoryCmd.Flags().StringVarP(&scheduleFormat, "format", "f", "text", "output format can be text, json or json-formatted")
+}
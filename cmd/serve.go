@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thiagolcmelo/webcrawler/src/jobs"
+)
+
+var (
+	serveAddr    string
+	serveDataDir string
+	serveMaxJobs int
+	serveVerbose bool
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve [flags]",
+	Short: "It runs the crawler as a service with a REST API for crawl jobs",
+	Long: `It runs the crawler as a service with a REST API for crawl jobs
+Jobs are submitted with POST /jobs, listed with GET /jobs, followed with GET /jobs/{id},
+canceled with POST /jobs/{id}/cancel and their reports are fetched in any format with
+GET /jobs/{id}/results?format=. Up to max-jobs jobs run at a time, the others are queued.
+Jobs and reports are kept in the data directory, so they survive restarts.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !serveVerbose {
+			log.SetOutput(io.Discard)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		store, err := jobs.OpenStore(serveDataDir)
+		if err != nil {
+			fmt.Printf("could not open data directory: %v\n", err)
+			return
+		}
+		manager, err := jobs.NewManager(context.Background(), store, serveMaxJobs)
+		if err != nil {
+			fmt.Printf("could not load jobs: %v\n", err)
+			return
+		}
+		defer manager.Close()
+
+		server := &http.Server{Addr: serveAddr, Handler: jobs.NewAPI(manager)}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Printf("serving jobs on %s\n", serveAddr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("server failed: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVarP(&serveAddr, "addr", "a", ":8080", "address to listen on")
+	serveCmd.Flags().StringVarP(&serveDataDir, "data-dir", "d", ".webcrawler-jobs", "directory where jobs and their reports are kept")
+	serveCmd.Flags().IntVarP(&serveMaxJobs, "max-jobs", "j", 2, "how many jobs can run at a time, the others are queued")
+	serveCmd.Flags().BoolVarP(&serveVerbose, "verbose", "v", false, "use it to print logs")
+}
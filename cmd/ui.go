@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+	"github.com/thiagolcmelo/webcrawler/src/ui"
+)
+
+var (
+	uiAddr    string
+	uiVerbose bool
+)
+
+// uiCmd represents the ui command
+var uiCmd = &cobra.Command{
+	Use:   "ui [flags] result-file",
+	Short: "It serves a web interface for browsing the results of a crawl",
+	Long: `It serves a web interface for browsing the results of a crawl
+The result file can be written by get, of any version, in the json, json-formatted or ndjson
+formats, be a job report kept by serve, or be a WARC archive written by get with --warc, which
+is replayed into the storage first. The interface lists the pages in a searchable table, shows the inlinks and
+outlinks of every page, draws the link graph and summarises errors and duplicates.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !uiVerbose {
+			log.SetOutput(io.Discard)
+		}
+
+		r, err := readResults(cmd.Context(), args[0])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		server := &http.Server{Addr: uiAddr, Handler: ui.NewServer(r)}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Printf("browsing %d pages on http://%s/\n", len(r.Pages), uiAddr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("server failed: %v\n", err)
+		}
+	},
+}
+
+// readResults reads a crawl output, or replays a WARC archive and reads the stored pages
+func readResults(ctx context.Context, filename string) (report.Report, error) {
+	if strings.HasSuffix(filename, ".warc") || strings.HasSuffix(filename, ".warc.gz") {
+		orchestrator, err := replayArchive(ctx, filename, 3)
+		if err != nil {
+			return report.Report{}, err
+		}
+		return orchestrator.Report(true), nil
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return report.Report{}, fmt.Errorf("could not open crawl output: %w", err)
+	}
+	defer f.Close()
+
+	r, err := report.Read(f)
+	if err != nil {
+		return report.Report{}, fmt.Errorf("could not read crawl output: %w", err)
+	}
+	return r, nil
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+	uiCmd.Flags().StringVarP(&uiAddr, "addr", "a", "localhost:8081", "address to listen on")
+	uiCmd.Flags().BoolVarP(&uiVerbose, "verbose", "v", false, "use it to print logs")
+}
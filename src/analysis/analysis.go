@@ -0,0 +1,225 @@
+package analysis
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+const (
+	// damping is the probability of following a link instead of jumping to a random page
+	damping = 0.85
+	// maxIterations bounds the PageRank power iteration
+	maxIterations = 100
+	// tolerance is the total rank change under which PageRank is considered converged
+	tolerance = 1e-9
+)
+
+// PageMetrics bundles the link metrics of a stored page
+type PageMetrics struct {
+	URL      string  `json:"url"`
+	PageRank float64 `json:"pageRank"`
+	Inlinks  int     `json:"inlinks"`
+	Outlinks int     `json:"outlinks"`
+	Depth    int     `json:"depth"`
+}
+
+// Analysis bundles the link graph metrics of a crawl
+type Analysis struct {
+	Pages    []PageMetrics `json:"pages"`
+	DeadEnds []string      `json:"deadEnds"`
+	Orphans  []string      `json:"orphans"`
+}
+
+// Analyze computes the metrics of the link graph formed by the stored pages, mapped to the
+// urls they link to. Pages are sorted by PageRank, dead ends are pages without outlinks and
+// orphans are sitemap urls no page links to
+func Analyze(links map[string][]string, seeds []string, sitemapURLs []string) Analysis {
+	a := Analysis{
+		Pages:    []PageMetrics{},
+		DeadEnds: []string{},
+		Orphans:  []string{},
+	}
+
+	inlinks := map[string]int{}
+	for source, targets := range links {
+		for _, target := range unique(targets) {
+			if target != source {
+				inlinks[target]++
+			}
+		}
+	}
+
+	ranks := PageRank(links)
+	depths := ClickDepths(links, seeds)
+	for url, targets := range links {
+		depth, ok := depths[url]
+		if !ok {
+			depth = -1
+		}
+		a.Pages = append(a.Pages, PageMetrics{
+			URL:      url,
+			PageRank: ranks[url],
+			Inlinks:  inlinks[url],
+			Outlinks: len(unique(targets)),
+			Depth:    depth,
+		})
+		if len(targets) == 0 {
+			a.DeadEnds = append(a.DeadEnds, url)
+		}
+	}
+	sort.Slice(a.Pages, func(i, j int) bool {
+		if a.Pages[i].PageRank != a.Pages[j].PageRank {
+			return a.Pages[i].PageRank > a.Pages[j].PageRank
+		}
+		return a.Pages[i].URL < a.Pages[j].URL
+	})
+	sort.Strings(a.DeadEnds)
+
+	for _, url := range unique(sitemapURLs) {
+		if inlinks[url] == 0 {
+			a.Orphans = append(a.Orphans, url)
+		}
+	}
+	sort.Strings(a.Orphans)
+
+	return a
+}
+
+// PageRank computes the internal PageRank of the pages in links, only links between
+// those pages count and pages linking nowhere spread their rank evenly
+func PageRank(links map[string][]string) map[string]float64 {
+	n := float64(len(links))
+	ranks := map[string]float64{}
+	if n == 0 {
+		return ranks
+	}
+
+	internal := map[string][]string{}
+	for source, targets := range links {
+		internal[source] = []string{}
+		for _, target := range unique(targets) {
+			if _, ok := links[target]; ok && target != source {
+				internal[source] = append(internal[source], target)
+			}
+		}
+		ranks[source] = 1 / n
+	}
+
+	for i := 0; i < maxIterations; i++ {
+		dangling := 0.0
+		for source, targets := range internal {
+			if len(targets) == 0 {
+				dangling += ranks[source]
+			}
+		}
+
+		next := map[string]float64{}
+		for url := range internal {
+			next[url] = (1-damping)/n + damping*dangling/n
+		}
+		for source, targets := range internal {
+			for _, target := range targets {
+				next[target] += damping * ranks[source] / float64(len(targets))
+			}
+		}
+
+		change := 0.0
+		for url := range next {
+			change += math.Abs(next[url] - ranks[url])
+		}
+		ranks = next
+		if change < tolerance {
+			break
+		}
+	}
+	return ranks
+}
+
+// ClickDepths computes the shortest number of clicks from any seed to every url linked,
+// urls that cannot be reached are left out
+func ClickDepths(links map[string][]string, seeds []string) map[string]int {
+	depths := map[string]int{}
+	queue := []string{}
+	for _, seed := range seeds {
+		if _, ok := depths[seed]; !ok {
+			depths[seed] = 0
+			queue = append(queue, seed)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range links[current] {
+			if _, ok := depths[child]; !ok {
+				depths[child] = depths[current] + 1
+				queue = append(queue, child)
+			}
+		}
+	}
+	return depths
+}
+
+// NearestSeeds maps every url linked to the seed it is reached from in the fewest clicks,
+// ties go to the seed listed first and urls that cannot be reached are left out
+func NearestSeeds(links map[string][]string, seeds []string) map[string]string {
+	nearest := map[string]string{}
+	queue := []string{}
+	for _, seed := range seeds {
+		if _, ok := nearest[seed]; !ok {
+			nearest[seed] = seed
+			queue = append(queue, seed)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range links[current] {
+			if _, ok := nearest[child]; !ok {
+				nearest[child] = nearest[current]
+				queue = append(queue, child)
+			}
+		}
+	}
+	return nearest
+}
+
+// WriteText writes the analysis in a human readable form
+func (a Analysis) WriteText(w io.Writer) error {
+	lines := []string{
+		"analysis:",
+		fmt.Sprintf("  %-10s %8s %8s %6s  %s", "pagerank", "inlinks", "outlinks", "depth", "url"),
+	}
+	for _, p := range a.Pages {
+		lines = append(lines, fmt.Sprintf("  %-10.6f %8d %8d %6d  %s", p.PageRank, p.Inlinks, p.Outlinks, p.Depth, p.URL))
+	}
+	lines = append(lines, fmt.Sprintf("  dead ends: %d", len(a.DeadEnds)))
+	for _, url := range a.DeadEnds {
+		lines = append(lines, fmt.Sprintf("    %s", url))
+	}
+	lines = append(lines, fmt.Sprintf("  orphans: %d", len(a.Orphans)))
+	for _, url := range a.Orphans {
+		lines = append(lines, fmt.Sprintf("    %s", url))
+	}
+
+	for _, line := range lines {
+		if _, err := w.Write([]byte(line + "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unique drops repeated urls keeping their order
+func unique(urls []string) []string {
+	seen := map[string]struct{}{}
+	result := []string{}
+	for _, url := range urls {
+		if _, ok := seen[url]; !ok {
+			seen[url] = struct{}{}
+			result = append(result, url)
+		}
+	}
+	return result
+}
@@ -0,0 +1,145 @@
+package analysis_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/analysis"
+)
+
+func sampleLinks() map[string][]string {
+	return map[string][]string{
+		"http://domain.com/":      {"http://domain.com/page1", "http://domain.com/page2"},
+		"http://domain.com/page1": {"http://domain.com/page2", "http://domain.com/missing"},
+		"http://domain.com/page2": {"http://domain.com/", "http://domain.com/page2"},
+		"http://domain.com/leaf":  {},
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	a := analysis.Analyze(sampleLinks(), []string{"http://domain.com/"}, []string{
+		"http://domain.com/page1",
+		"http://domain.com/hidden",
+		"http://domain.com/hidden",
+	})
+
+	actual := map[string]analysis.PageMetrics{}
+	for _, p := range a.Pages {
+		p.PageRank = 0
+		actual[p.URL] = p
+	}
+	expected := map[string]analysis.PageMetrics{
+		"http://domain.com/":      {URL: "http://domain.com/", Inlinks: 1, Outlinks: 2, Depth: 0},
+		"http://domain.com/page1": {URL: "http://domain.com/page1", Inlinks: 1, Outlinks: 2, Depth: 1},
+		"http://domain.com/page2": {URL: "http://domain.com/page2", Inlinks: 2, Outlinks: 2, Depth: 1},
+		"http://domain.com/leaf":  {URL: "http://domain.com/leaf", Inlinks: 0, Outlinks: 0, Depth: -1},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected metrics (-expected +actual):\n%s", diff)
+	}
+
+	if a.Pages[0].URL != "http://domain.com/page2" {
+		t.Errorf("expected the most linked page first, got %s", a.Pages[0].URL)
+	}
+	if diff := cmp.Diff([]string{"http://domain.com/leaf"}, a.DeadEnds); diff != "" {
+		t.Errorf("unexpected dead ends (-expected +actual):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"http://domain.com/hidden"}, a.Orphans); diff != "" {
+		t.Errorf("unexpected orphans (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestPageRank(t *testing.T) {
+	type testCase struct {
+		testName string
+		links    map[string][]string
+		expected map[string]float64
+	}
+
+	testCases := []testCase{
+		{
+			testName: "empty_graph",
+			links:    map[string][]string{},
+			expected: map[string]float64{},
+		},
+		{
+			testName: "cycle_shares_rank_evenly",
+			links: map[string][]string{
+				"a": {"b"},
+				"b": {"c"},
+				"c": {"a"},
+			},
+			expected: map[string]float64{"a": 1.0 / 3, "b": 1.0 / 3, "c": 1.0 / 3},
+		},
+		{
+			testName: "dangling_pages_spread_their_rank",
+			links: map[string][]string{
+				"a": {"b"},
+				"b": {},
+			},
+			expected: map[string]float64{"a": 0.350877, "b": 0.649123},
+		},
+		{
+			testName: "links_outside_the_graph_are_ignored",
+			links: map[string][]string{
+				"a": {"b", "external"},
+				"b": {"a"},
+			},
+			expected: map[string]float64{"a": 0.5, "b": 0.5},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			actual := analysis.PageRank(tc.links)
+			approx := cmp.Comparer(func(x, y float64) bool { return math.Abs(x-y) < 1e-6 })
+			if diff := cmp.Diff(tc.expected, actual, approx); diff != "" {
+				t.Errorf("unexpected ranks (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAnalysis_WriteText(t *testing.T) {
+	a := analysis.Analyze(sampleLinks(), []string{"http://domain.com/"}, []string{"http://domain.com/hidden"})
+
+	var buf bytes.Buffer
+	if err := a.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"analysis:", "dead ends: 1", "    http://domain.com/leaf", "orphans: 1", "    http://domain.com/hidden"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestNearestSeeds(t *testing.T) {
+	links := map[string][]string{
+		"http://a.com/":      {"http://a.com/page1", "http://a.com/shared"},
+		"http://a.com/page1": {"http://a.com/deep"},
+		"http://b.com/":      {"http://a.com/page1"},
+	}
+
+	expected := map[string]string{
+		"http://a.com/":       "http://a.com/",
+		"http://a.com/shared": "http://a.com/",
+		// page1 is one click away from both seeds, the first seed takes it
+		"http://a.com/page1": "http://a.com/",
+		"http://a.com/deep":  "http://a.com/",
+		"http://b.com/":      "http://b.com/",
+	}
+	if diff := cmp.Diff(expected, analysis.NearestSeeds(links, []string{"http://a.com/", "http://b.com/"})); diff != "" {
+		t.Errorf("unexpected seeds (-expected +actual):\n%s", diff)
+	}
+
+	expected["http://a.com/page1"] = "http://b.com/"
+	expected["http://a.com/deep"] = "http://b.com/"
+	if diff := cmp.Diff(expected, analysis.NearestSeeds(links, []string{"http://b.com/", "http://a.com/"})); diff != "" {
+		t.Errorf("unexpected seeds with b first (-expected +actual):\n%s", diff)
+	}
+}
@@ -0,0 +1,245 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/thiagolcmelo/webcrawler/src/report"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrUnknownRule should be used when a config refers to a rule that does not exist
+	ErrUnknownRule = errors.New("unknown rule")
+	// ErrUnknownSeverity should be used when a severity is not one of off, notice, warning or error
+	ErrUnknownSeverity = errors.New("unknown severity")
+	// ErrUnknownConfigFormat should be used when the extension of a config file is not supported
+	ErrUnknownConfigFormat = errors.New("unknown audit config format")
+	// ErrNothingToAudit should be used when a crawl stored no pages or its seed failed, like when
+	// the site is down
+	ErrNothingToAudit = errors.New("nothing to audit")
+)
+
+// Severity tells how serious a finding is, only errors fail an audit
+type Severity int
+
+const (
+	// Off disables a rule
+	Off Severity = iota
+	// Notice is a finding worth knowing about
+	Notice
+	// Warning is a finding that should be fixed
+	Warning
+	// Error is a finding that must be fixed
+	Error
+)
+
+var severityNames = []string{"off", "notice", "warning", "error"}
+
+func (s Severity) String() string {
+	if s < Off || s > Error {
+		return "unknown"
+	}
+	return severityNames[s]
+}
+
+// MarshalText writes the severity by its name
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText reads a severity from its name
+func (s *Severity) UnmarshalText(text []byte) error {
+	for i, name := range severityNames {
+		if strings.EqualFold(string(text), name) {
+			*s = Severity(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w [%s], it can be %s", ErrUnknownSeverity, text, strings.Join(severityNames, ", "))
+}
+
+// Site bundles what the rules inspect, the stored pages and the status of every url that responded
+type Site struct {
+	Pages    []report.Page
+	Statuses map[string]int
+}
+
+// Crawled checks a crawl is worth auditing, a site that is down stores no pages and would pass
+// any audit, seeds are the addresses the crawl started from
+func Crawled(r report.Report, seeds []string) error {
+	for _, o := range r.Failed {
+		for _, seed := range seeds {
+			if o.URL == seed {
+				return fmt.Errorf("%w, the seed [%s] failed at %s: %s", ErrNothingToAudit, seed, o.Stage, o.Reason)
+			}
+		}
+	}
+	if len(r.Pages) == 0 {
+		return fmt.Errorf("%w, the crawl stored no pages", ErrNothingToAudit)
+	}
+	return nil
+}
+
+// Finding is a problem a rule found in a page
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	URL      string   `json:"url"`
+	Message  string   `json:"message"`
+}
+
+// Check inspects a site and returns the findings of a rule, limit is the rule threshold when it has one
+type Check func(site Site, limit int) []Finding
+
+// Rule is a check along with how serious its findings are
+type Rule struct {
+	Name        string
+	Description string
+	Severity    Severity
+	Limit       int
+	Check       Check
+}
+
+// RuleConfig overrides the severity and the limit of a rule, fields left out keep their defaults
+type RuleConfig struct {
+	Severity *Severity `json:"severity" yaml:"severity"`
+	Limit    *int      `json:"limit" yaml:"limit"`
+}
+
+// Config maps rule names to their overrides
+type Config struct {
+	Rules map[string]RuleConfig `json:"rules" yaml:"rules"`
+}
+
+// LoadConfig reads a YAML or JSON config, picked by the extension of the file name, unknown
+// fields are rejected so typos do not go unnoticed
+func LoadConfig(r io.Reader, name string) (Config, error) {
+	var config Config
+	var err error
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(r)
+		decoder.KnownFields(true)
+		// an empty file configures nothing
+		if err = decoder.Decode(&config); errors.Is(err, io.EOF) {
+			err = nil
+		}
+	case ".json":
+		decoder := json.NewDecoder(r)
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&config)
+	default:
+		return Config{}, fmt.Errorf("%w [%s], it can be .yaml, .yml or .json", ErrUnknownConfigFormat, filepath.Ext(name))
+	}
+	if err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// Configure applies a config to a list of rules
+func Configure(rules []Rule, config Config) ([]Rule, error) {
+	configured := make([]Rule, len(rules))
+	copy(configured, rules)
+
+	names := make([]string, 0, len(config.Rules))
+	for name := range config.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		ruleConfig := config.Rules[name]
+		i := indexOf(configured, name)
+		if i < 0 {
+			return nil, fmt.Errorf("%w [%s]", ErrUnknownRule, name)
+		}
+		if ruleConfig.Severity != nil {
+			configured[i].Severity = *ruleConfig.Severity
+		}
+		if ruleConfig.Limit != nil {
+			configured[i].Limit = *ruleConfig.Limit
+		}
+	}
+	return configured, nil
+}
+
+func indexOf(rules []Rule, name string) int {
+	for i, rule := range rules {
+		if rule.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Result bundles the findings of an audit, the most serious first
+type Result struct {
+	Findings []Finding `json:"findings"`
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+	Notices  int       `json:"notices"`
+}
+
+// Run checks a site against the rules that are not off
+func Run(site Site, rules []Rule) Result {
+	result := Result{Findings: []Finding{}}
+	for _, rule := range rules {
+		if rule.Severity == Off {
+			continue
+		}
+		for _, f := range rule.Check(site, rule.Limit) {
+			f.Rule = rule.Name
+			f.Severity = rule.Severity
+			result.Findings = append(result.Findings, f)
+
+			switch rule.Severity {
+			case Error:
+				result.Errors++
+			case Warning:
+				result.Warnings++
+			case Notice:
+				result.Notices++
+			}
+		}
+	}
+
+	sort.SliceStable(result.Findings, func(i, j int) bool {
+		a, b := result.Findings[i], result.Findings[j]
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.URL < b.URL
+	})
+	return result
+}
+
+// HasErrors informs if any finding is an error
+func (r Result) HasErrors() bool {
+	return r.Errors > 0
+}
+
+// WriteText writes the findings in a human readable form followed by their counts
+func (r Result) WriteText(w io.Writer) error {
+	lines := []string{}
+	for _, f := range r.Findings {
+		lines = append(lines, fmt.Sprintf("%-7s %-21s %s: %s", f.Severity, f.Rule, f.URL, f.Message))
+	}
+	lines = append(lines, fmt.Sprintf("%d errors, %d warnings, %d notices", r.Errors, r.Warnings, r.Notices))
+
+	for _, line := range lines {
+		if _, err := w.Write([]byte(line + "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
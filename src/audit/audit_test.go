@@ -0,0 +1,187 @@
+package audit_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/audit"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+)
+
+func TestSeverity_UnmarshalText(t *testing.T) {
+	type testCase struct {
+		testName         string
+		text             string
+		expectedSeverity audit.Severity
+		expectedErr      error
+	}
+
+	testCases := []testCase{
+		{testName: "off", text: "off", expectedSeverity: audit.Off},
+		{testName: "case_insensitive", text: "Warning", expectedSeverity: audit.Warning},
+		{testName: "error", text: "error", expectedSeverity: audit.Error},
+		{testName: "unknown", text: "fatal", expectedErr: audit.ErrUnknownSeverity},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			var actual audit.Severity
+			err := actual.UnmarshalText([]byte(tc.text))
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected %v, got %v", tc.expectedErr, err)
+			}
+			if actual != tc.expectedSeverity {
+				t.Errorf("expected %v, got %v", tc.expectedSeverity, actual)
+			}
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	type testCase struct {
+		testName    string
+		name        string
+		config      string
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{testName: "empty_config", name: "audit.json", config: `{}`},
+		{testName: "overrides", name: "audit.json", config: `{"rules": {"slow-response": {"severity": "error", "limit": 200}, "missing-alt": {"severity": "off"}}}`},
+		{testName: "unknown_rule", name: "audit.json", config: `{"rules": {"missing-titel": {"severity": "off"}}}`, expectedErr: audit.ErrUnknownRule},
+		{testName: "unknown_severity", name: "audit.json", config: `{"rules": {"missing-title": {"severity": "fatal"}}}`, expectedErr: audit.ErrUnknownSeverity},
+		{testName: "empty_yaml", name: "audit.yaml", config: ``},
+		{testName: "yaml_overrides", name: "audit.yml", config: "rules:\n  slow-response:\n    severity: error\n    limit: 200\n  missing-alt:\n    severity: off\n"},
+		{testName: "yaml_unknown_severity", name: "audit.YAML", config: "rules:\n  missing-title:\n    severity: fatal\n", expectedErr: audit.ErrUnknownSeverity},
+		{testName: "unknown_format", name: "audit.toml", config: `[rules]`, expectedErr: audit.ErrUnknownConfigFormat},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			config, err := audit.LoadConfig(strings.NewReader(tc.config), tc.name)
+			if err == nil {
+				_, err = audit.Configure(audit.Builtins(), config)
+			}
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+
+	config, err := audit.LoadConfig(strings.NewReader("rules:\n  slow-response:\n    severity: error\n    limit: 200\n"), "audit.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := audit.Configure(audit.Builtins(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range rules {
+		if rule.Name == "slow-response" && (rule.Severity != audit.Error || rule.Limit != 200) {
+			t.Errorf("expected slow-response to be configured, got %v and %d", rule.Severity, rule.Limit)
+		}
+	}
+	for _, rule := range audit.Builtins() {
+		if rule.Name == "slow-response" && rule.Severity != audit.Warning {
+			t.Errorf("expected the builtins to be left untouched")
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	m := content.NewMetadata()
+	m.Title = "Title"
+	site := audit.Site{
+		Pages: []report.Page{
+			{URL: "http://domain.com/b", Size: 10, Metadata: m},
+			{URL: "http://domain.com/a", Size: 20, Metadata: content.NewMetadata()},
+		},
+	}
+
+	rules := []audit.Rule{}
+	for _, rule := range audit.Builtins() {
+		switch rule.Name {
+		case "missing-title", "oversized-page":
+			rule.Limit = 5
+			rules = append(rules, rule)
+		case "missing-description":
+			rule.Severity = audit.Off
+			rules = append(rules, rule)
+		}
+	}
+
+	result := audit.Run(site, rules)
+
+	actual := []string{}
+	for _, f := range result.Findings {
+		actual = append(actual, f.Severity.String()+" "+f.Rule+" "+f.URL)
+	}
+	expected := []string{
+		"error missing-title http://domain.com/a",
+		"warning oversized-page http://domain.com/a",
+		"warning oversized-page http://domain.com/b",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected findings (-expected +actual):\n%s", diff)
+	}
+
+	if result.Errors != 1 || result.Warnings != 2 || result.Notices != 0 || !result.HasErrors() {
+		t.Errorf("unexpected counts %d errors, %d warnings, %d notices", result.Errors, result.Warnings, result.Notices)
+	}
+
+	var buf bytes.Buffer
+	if err := result.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(buf.String(), "1 errors, 2 warnings, 0 notices\n") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestCrawled(t *testing.T) {
+	type testCase struct {
+		testName    string
+		report      report.Report
+		expectedErr error
+	}
+
+	seeds := []string{"https://domain.com/", "http://domain.com/"}
+	testCases := []testCase{
+		{
+			testName: "pages_stored",
+			report:   report.Report{Pages: []report.Page{{URL: "http://domain.com/"}}},
+		},
+		{
+			testName:    "no_pages_stored",
+			report:      report.Report{Pages: []report.Page{}},
+			expectedErr: audit.ErrNothingToAudit,
+		},
+		{
+			testName: "seed_failed",
+			report: report.Report{
+				Pages:  []report.Page{{URL: "http://domain.com/other"}},
+				Failed: []report.Outcome{{URL: "http://domain.com/", Stage: "download", Reason: "response status not 200", StatusCode: 503}},
+			},
+			expectedErr: audit.ErrNothingToAudit,
+		},
+		{
+			testName: "other_page_failed",
+			report: report.Report{
+				Pages:  []report.Page{{URL: "http://domain.com/"}},
+				Failed: []report.Outcome{{URL: "http://domain.com/missing", Stage: "download", Reason: "response status not 200", StatusCode: 404}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if err := audit.Crawled(tc.report, seeds); !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package audit
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/thiagolcmelo/webcrawler/src/report"
+)
+
+// Builtins lists the rules known by the audit with their default severities and limits
+func Builtins() []Rule {
+	return []Rule{
+		{Name: "missing-title", Description: "HTML pages without a title", Severity: Error, Check: missingTitle},
+		{Name: "duplicate-title", Description: "HTML pages sharing their title", Severity: Warning, Check: duplicateTitle},
+		{Name: "missing-description", Description: "HTML pages without a meta description", Severity: Warning, Check: missingDescription},
+		{Name: "duplicate-description", Description: "HTML pages sharing their meta description", Severity: Warning, Check: duplicateDescription},
+		{Name: "multiple-h1", Description: "HTML pages with more than one h1", Severity: Warning, Check: multipleH1},
+		{Name: "oversized-page", Description: "pages with a body larger than limit bytes", Severity: Warning, Limit: 1024 * 1024, Check: oversizedPage},
+		{Name: "slow-response", Description: "pages downloaded in more than limit milliseconds", Severity: Warning, Limit: 1000, Check: slowResponse},
+		{Name: "redirect-chain", Description: "pages reached through more than limit redirects", Severity: Warning, Limit: 1, Check: redirectChain},
+		{Name: "broken-link", Description: "pages linking to urls that responded 4xx", Severity: Error, Check: brokenLink},
+		{Name: "mixed-content", Description: "https pages loading http resources", Severity: Error, Check: mixedContent},
+		{Name: "missing-alt", Description: "images without alt text", Severity: Warning, Check: missingAlt},
+	}
+}
+
+// htmlPages lists the pages whose metadata was extracted
+func htmlPages(site Site) []report.Page {
+	pages := []report.Page{}
+	for _, p := range site.Pages {
+		if p.Metadata != nil {
+			pages = append(pages, p)
+		}
+	}
+	return pages
+}
+
+func missingTitle(site Site, _ int) []Finding {
+	findings := []Finding{}
+	for _, p := range htmlPages(site) {
+		if p.Metadata.Title == "" {
+			findings = append(findings, Finding{URL: p.URL, Message: "page has no title"})
+		}
+	}
+	return findings
+}
+
+func missingDescription(site Site, _ int) []Finding {
+	findings := []Finding{}
+	for _, p := range htmlPages(site) {
+		if p.Metadata.Description == "" {
+			findings = append(findings, Finding{URL: p.URL, Message: "page has no meta description"})
+		}
+	}
+	return findings
+}
+
+func duplicateTitle(site Site, _ int) []Finding {
+	return duplicates(site, "title", func(p report.Page) string { return p.Metadata.Title })
+}
+
+func duplicateDescription(site Site, _ int) []Finding {
+	return duplicates(site, "meta description", func(p report.Page) string { return p.Metadata.Description })
+}
+
+// duplicates reports every page sharing a non empty field with other pages
+func duplicates(site Site, field string, value func(report.Page) string) []Finding {
+	groups := map[string][]string{}
+	for _, p := range htmlPages(site) {
+		if v := value(p); v != "" {
+			groups[v] = append(groups[v], p.URL)
+		}
+	}
+
+	findings := []Finding{}
+	for v, urls := range groups {
+		if len(urls) < 2 {
+			continue
+		}
+		sort.Strings(urls)
+		for _, u := range urls {
+			findings = append(findings, Finding{
+				URL:     u,
+				Message: fmt.Sprintf("%s %q is shared with %d other pages", field, v, len(urls)-1),
+			})
+		}
+	}
+	return findings
+}
+
+func multipleH1(site Site, _ int) []Finding {
+	findings := []Finding{}
+	for _, p := range htmlPages(site) {
+		if len(p.Metadata.H1) > 1 {
+			findings = append(findings, Finding{URL: p.URL, Message: fmt.Sprintf("page has %d h1 headings", len(p.Metadata.H1))})
+		}
+	}
+	return findings
+}
+
+func oversizedPage(site Site, limit int) []Finding {
+	findings := []Finding{}
+	for _, p := range site.Pages {
+		if p.Size > limit {
+			findings = append(findings, Finding{URL: p.URL, Message: fmt.Sprintf("body has %d bytes, over %d", p.Size, limit)})
+		}
+	}
+	return findings
+}
+
+func slowResponse(site Site, limit int) []Finding {
+	findings := []Finding{}
+	for _, p := range site.Pages {
+		if p.ElapsedMs > float64(limit) {
+			findings = append(findings, Finding{URL: p.URL, Message: fmt.Sprintf("downloaded in %.0fms, over %dms", p.ElapsedMs, limit)})
+		}
+	}
+	return findings
+}
+
+func redirectChain(site Site, limit int) []Finding {
+	findings := []Finding{}
+	for _, p := range site.Pages {
+		if len(p.Redirects) > limit {
+			findings = append(findings, Finding{
+				URL:     p.URL,
+				Message: fmt.Sprintf("reached through %d redirects: %s", len(p.Redirects), strings.Join(p.Redirects, " -> ")),
+			})
+		}
+	}
+	return findings
+}
+
+func brokenLink(site Site, _ int) []Finding {
+	findings := []Finding{}
+	for _, p := range site.Pages {
+		for _, child := range p.Children {
+			if status := site.Statuses[child]; status >= 400 && status < 500 {
+				findings = append(findings, Finding{URL: p.URL, Message: fmt.Sprintf("links to %s which responded %d", child, status)})
+			}
+		}
+	}
+	return findings
+}
+
+func mixedContent(site Site, _ int) []Finding {
+	findings := []Finding{}
+	for _, p := range htmlPages(site) {
+		if !strings.HasPrefix(p.URL, "https://") {
+			continue
+		}
+		for _, resource := range p.Metadata.Resources {
+			if u, err := url.Parse(resource); err == nil && u.Scheme == "http" {
+				findings = append(findings, Finding{URL: p.URL, Message: fmt.Sprintf("loads %s over http", resource)})
+			}
+		}
+	}
+	return findings
+}
+
+func missingAlt(site Site, _ int) []Finding {
+	findings := []Finding{}
+	for _, p := range htmlPages(site) {
+		for _, image := range p.Metadata.MissingAlt {
+			findings = append(findings, Finding{URL: p.URL, Message: fmt.Sprintf("image %s has no alt text", image)})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,160 @@
+package audit_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/audit"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+)
+
+func htmlPage(url string, update func(*content.Metadata)) report.Page {
+	m := content.NewMetadata()
+	m.Title = url
+	m.Description = url
+	update(m)
+	return report.Page{URL: url, Metadata: m}
+}
+
+func TestBuiltins(t *testing.T) {
+	type testCase struct {
+		testName         string
+		rule             string
+		site             audit.Site
+		expectedFindings []audit.Finding
+	}
+
+	noop := func(*content.Metadata) {}
+
+	testCases := []testCase{
+		{
+			testName: "missing_title",
+			rule:     "missing-title",
+			site: audit.Site{Pages: []report.Page{
+				htmlPage("http://domain.com/a", func(m *content.Metadata) { m.Title = "" }),
+				htmlPage("http://domain.com/b", noop),
+				{URL: "http://domain.com/image.png"},
+			}},
+			expectedFindings: []audit.Finding{{URL: "http://domain.com/a", Message: "page has no title"}},
+		},
+		{
+			testName: "duplicate_title",
+			rule:     "duplicate-title",
+			site: audit.Site{Pages: []report.Page{
+				htmlPage("http://domain.com/a", func(m *content.Metadata) { m.Title = "Same" }),
+				htmlPage("http://domain.com/b", func(m *content.Metadata) { m.Title = "Same" }),
+				htmlPage("http://domain.com/c", noop),
+			}},
+			expectedFindings: []audit.Finding{
+				{URL: "http://domain.com/a", Message: `title "Same" is shared with 1 other pages`},
+				{URL: "http://domain.com/b", Message: `title "Same" is shared with 1 other pages`},
+			},
+		},
+		{
+			testName: "missing_description",
+			rule:     "missing-description",
+			site: audit.Site{Pages: []report.Page{
+				htmlPage("http://domain.com/a", func(m *content.Metadata) { m.Description = "" }),
+			}},
+			expectedFindings: []audit.Finding{{URL: "http://domain.com/a", Message: "page has no meta description"}},
+		},
+		{
+			testName: "duplicate_description",
+			rule:     "duplicate-description",
+			site: audit.Site{Pages: []report.Page{
+				htmlPage("http://domain.com/a", func(m *content.Metadata) { m.Description = "Same" }),
+				htmlPage("http://domain.com/b", func(m *content.Metadata) { m.Description = "Same" }),
+			}},
+			expectedFindings: []audit.Finding{
+				{URL: "http://domain.com/a", Message: `meta description "Same" is shared with 1 other pages`},
+				{URL: "http://domain.com/b", Message: `meta description "Same" is shared with 1 other pages`},
+			},
+		},
+		{
+			testName: "multiple_h1",
+			rule:     "multiple-h1",
+			site: audit.Site{Pages: []report.Page{
+				htmlPage("http://domain.com/a", func(m *content.Metadata) { m.H1 = []string{"one", "two"} }),
+				htmlPage("http://domain.com/b", func(m *content.Metadata) { m.H1 = []string{"one"} }),
+			}},
+			expectedFindings: []audit.Finding{{URL: "http://domain.com/a", Message: "page has 2 h1 headings"}},
+		},
+		{
+			testName: "oversized_page",
+			rule:     "oversized-page",
+			site: audit.Site{Pages: []report.Page{
+				{URL: "http://domain.com/a", Size: 2 * 1024 * 1024},
+				{URL: "http://domain.com/b", Size: 1024},
+			}},
+			expectedFindings: []audit.Finding{{URL: "http://domain.com/a", Message: "body has 2097152 bytes, over 1048576"}},
+		},
+		{
+			testName: "slow_response",
+			rule:     "slow-response",
+			site: audit.Site{Pages: []report.Page{
+				{URL: "http://domain.com/a", ElapsedMs: 1500},
+				{URL: "http://domain.com/b", ElapsedMs: 20},
+			}},
+			expectedFindings: []audit.Finding{{URL: "http://domain.com/a", Message: "downloaded in 1500ms, over 1000ms"}},
+		},
+		{
+			testName: "redirect_chain",
+			rule:     "redirect-chain",
+			site: audit.Site{Pages: []report.Page{
+				{URL: "http://domain.com/a", Redirects: []string{"http://domain.com/a", "https://domain.com/a"}},
+				{URL: "http://domain.com/b", Redirects: []string{"http://domain.com/b"}},
+			}},
+			expectedFindings: []audit.Finding{{URL: "http://domain.com/a", Message: "reached through 2 redirects: http://domain.com/a -> https://domain.com/a"}},
+		},
+		{
+			testName: "broken_link",
+			rule:     "broken-link",
+			site: audit.Site{
+				Pages: []report.Page{
+					{URL: "http://domain.com/a", Children: []string{"http://domain.com/gone", "http://domain.com/down", "http://domain.com/b"}},
+				},
+				Statuses: map[string]int{"http://domain.com/gone": 404, "http://domain.com/down": 503, "http://domain.com/b": 200},
+			},
+			expectedFindings: []audit.Finding{{URL: "http://domain.com/a", Message: "links to http://domain.com/gone which responded 404"}},
+		},
+		{
+			testName: "mixed_content",
+			rule:     "mixed-content",
+			site: audit.Site{Pages: []report.Page{
+				htmlPage("https://domain.com/a", func(m *content.Metadata) {
+					m.Resources = []string{"http://cdn.com/script.js", "https://cdn.com/style.css"}
+				}),
+				htmlPage("http://domain.com/b", func(m *content.Metadata) { m.Resources = []string{"http://cdn.com/script.js"} }),
+			}},
+			expectedFindings: []audit.Finding{{URL: "https://domain.com/a", Message: "loads http://cdn.com/script.js over http"}},
+		},
+		{
+			testName: "missing_alt",
+			rule:     "missing-alt",
+			site: audit.Site{Pages: []report.Page{
+				htmlPage("http://domain.com/a", func(m *content.Metadata) { m.MissingAlt = []string{"http://domain.com/image.png"} }),
+			}},
+			expectedFindings: []audit.Finding{{URL: "http://domain.com/a", Message: "image http://domain.com/image.png has no alt text"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			var rule audit.Rule
+			for _, r := range audit.Builtins() {
+				if r.Name == tc.rule {
+					rule = r
+				}
+			}
+			if rule.Check == nil {
+				t.Fatalf("unknown rule %s", tc.rule)
+			}
+
+			actual := rule.Check(tc.site, rule.Limit)
+			if diff := cmp.Diff(tc.expectedFindings, actual); diff != "" {
+				t.Errorf("unexpected findings (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package basic
+
+import (
+	"regexp"
+
+	"github.com/thiagolcmelo/webcrawler/src/content"
+)
+
+var (
+	cssURLPattern    = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)\s]*))\s*\)`)
+	cssImportPattern = regexp.MustCompile(`@import\s+(?:"([^"]*)"|'([^']*)')`)
+)
+
+// CSSParser is an implementation of the Parser interface for stylesheets
+type CSSParser struct{}
+
+// NewCSSParser is a factory for basic.CSSParser
+func NewCSSParser() *CSSParser {
+	return &CSSParser{}
+}
+
+// Parse updates a content with the urls of url() functions and @import rules, data urls are skipped
+func (cp *CSSParser) Parse(c *content.Content) error {
+	links := []string{}
+	for _, pattern := range []*regexp.Regexp{cssURLPattern, cssImportPattern} {
+		for _, match := range pattern.FindAllSubmatch(c.Body, -1) {
+			for _, group := range match[1:] {
+				if len(group) > 0 {
+					links = append(links, string(group))
+				}
+			}
+		}
+	}
+	addLinks(c, links, "css")
+	return nil
+}
@@ -19,7 +19,8 @@ func NewDispatcher(events events.Events, frontier frontier.Frontier) *Dispatcher
 	}
 }
 
-// DispatchNewUrls dispatches new URLs to the download frontier
+// DispatchNewUrls dispatches new URLs to the download frontier, it stops at the first URL the
+// frontier does not take and returns how many were dispatched before it
 func (bd *Dispatcher) DispatchNewUrls(urls []string) (int, error) {
 	newUrls := []string{}
 
@@ -29,8 +30,10 @@ func (bd *Dispatcher) DispatchNewUrls(urls []string) (int, error) {
 		}
 	}
 
-	for _, url := range newUrls {
-		bd.frontier.Publish(url)
+	for i, url := range newUrls {
+		if err := bd.frontier.Publish(url); err != nil {
+			return i, err
+		}
 	}
 
 	return len(newUrls), nil
@@ -11,11 +11,17 @@ import (
 	"golang.org/x/exp/maps"
 )
 
+var errFrontierClosed = errors.New("frontier closed")
+
 type fakeFrontier struct {
-	Items []string
+	Items  []string
+	Closed bool
 }
 
 func (ff *fakeFrontier) Publish(url string) error {
+	if ff.Closed {
+		return errFrontierClosed
+	}
 	ff.Items = append(ff.Items, url)
 	return nil
 }
@@ -33,6 +39,10 @@ func (fe *fakeEvents) LogDownloadEvent(string, bool)      {}
 func (fe *fakeEvents) LogParseEvent(string, bool, int)    {}
 func (fe *fakeEvents) LogStoreEvent(string, bool)         {}
 func (fe *fakeEvents) LogDispatchEvent(string, bool, int) {}
+func (fe *fakeEvents) LogDuplicateEvent(string)           {}
+func (fe *fakeEvents) LogErrorEvent(string, string)       {}
+func (fe *fakeEvents) LogResponseEvent(string, int, int)  {}
+func (fe *fakeEvents) LogSchemeRetryEvent(string)         {}
 func (fe *fakeEvents) GetReport() map[string][]events.EventInstance {
 	return map[string][]events.EventInstance{}
 }
@@ -45,6 +55,7 @@ func TestBasicDispatcher_DispatchNewUrls(t *testing.T) {
 	type testCase struct {
 		testName       string
 		shouldDownload map[string]bool
+		closed         bool
 		expectedErr    error
 		expectedUrls   []string
 	}
@@ -74,12 +85,20 @@ func TestBasicDispatcher_DispatchNewUrls(t *testing.T) {
 			expectedErr:    nil,
 			expectedUrls:   []string{"url1", "url2", "url3"},
 		},
+		{
+			testName:       "dispatch_fails_if_frontier_is_closed",
+			shouldDownload: map[string]bool{"url1": true},
+			closed:         true,
+			expectedErr:    errFrontierClosed,
+			expectedUrls:   []string{},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.testName, func(t *testing.T) {
 			ff := &fakeFrontier{
-				Items: []string{},
+				Items:  []string{},
+				Closed: tc.closed,
 			}
 			fe := &fakeEvents{
 				UrlsToDownload: map[string]struct{}{},
@@ -17,6 +17,8 @@ var (
 	ErrExecutingRequest = errors.New("could not execute request")
 	// ErrResponseStatusNotOK should be used when the status code is not ok
 	ErrResponseStatusNotOK = errors.New("response status not 200")
+	// ErrNotModified should be used when a conditional request gets a 304, there is no body
+	ErrNotModified = errors.New("response status not modified")
 )
 
 // Downloader is a basic implementation of the Downloader interface
@@ -35,42 +37,69 @@ func NewDownloader(retries int, backoff time.Duration, backoffMultiplier int) *D
 	}
 }
 
-// Download attempts to fetch a URL content and store in the provided content object
+// Download attempts to fetch a URL content and store in the provided content object, at least
+// once, a canceled context fails the download and stops the retries
 func (bd *Downloader) Download(ctx context.Context, c *content.Content) error {
-	for i := 0; i < bd.retries; i++ {
-		err := bd.download(ctx, c)
+	return bd.DownloadWithHeader(ctx, c, nil)
+}
+
+// DownloadWithHeader is Download sending the header along with the request, like the validators
+// of a conditional request, a 304 response is ErrNotModified and is not retried
+func (bd *Downloader) DownloadWithHeader(ctx context.Context, c *content.Content, header http.Header) error {
+	attempts := bd.retries
+	if attempts < 1 {
+		attempts = 1
+	}
+	for i := 0; ; i++ {
+		err := bd.download(ctx, c, header)
 		// if there was an error and it is not the last attempt
-		if err != nil && i < bd.retries-1 {
-			log.Printf("attempt %d for url [%s] failed due to %v", i+1, c.Address, err)
+		if err == nil || errors.Is(err, ErrNotModified) || i >= attempts-1 || ctx.Err() != nil {
+			return err
+		}
+		log.Printf("attempt %d for url [%s] failed due to %v", i+1, c.Address, err)
 
-			multiplier := math.Pow(float64(bd.backoffMultiplier), float64(i))
+		multiplier := math.Pow(float64(bd.backoffMultiplier), float64(i))
 
-			time.Sleep(bd.backoff * time.Duration(multiplier))
-			continue
+		select {
+		case <-time.After(bd.backoff * time.Duration(multiplier)):
+		case <-ctx.Done():
+			return ctx.Err()
 		}
-		return err
 	}
-	return nil
 }
 
-func (bd *Downloader) download(ctx context.Context, c *content.Content) error {
+func (bd *Downloader) download(ctx context.Context, c *content.Content, header http.Header) error {
 	// create a request that can be canceled from the context
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.Address, nil)
 	if err != nil {
 		return err
 	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
 
 	// send the request
+	start := time.Now()
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		if !errors.Is(err, context.DeadlineExceeded) {
-			return ErrExecutingRequest
+		// an interrupted request is not a host that cannot be reached
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
 		}
-		return nil
+		return ErrExecutingRequest
 	}
 	defer resp.Body.Close()
+	c.StatusCode = resp.StatusCode
+	c.Header = resp.Header
+	c.Redirects, c.Hops = redirects(resp)
+	if len(c.Redirects) > 0 {
+		c.Location = resp.Request.URL.String()
+	}
 
 	// check if the response is 200
+	if resp.StatusCode == http.StatusNotModified {
+		return ErrNotModified
+	}
 	if resp.StatusCode != http.StatusOK {
 		return ErrResponseStatusNotOK
 	}
@@ -80,6 +109,7 @@ func (bd *Downloader) download(ctx context.Context, c *content.Content) error {
 	if err != nil {
 		return err
 	}
+	c.Elapsed = time.Since(start)
 	c.CreateChecksum()
 
 	// store the content type in the content as well
@@ -87,3 +117,15 @@ func (bd *Downloader) download(ctx context.Context, c *content.Content) error {
 
 	return nil
 }
+
+// redirects lists the urls requested before the one that gave the final response, in order,
+// along with the redirect responses they got
+func redirects(resp *http.Response) ([]string, []content.Hop) {
+	chain := []string{}
+	hops := []content.Hop{}
+	for r := resp.Request.Response; r != nil; r = r.Request.Response {
+		chain = append([]string{r.Request.URL.String()}, chain...)
+		hops = append([]content.Hop{{URL: r.Request.URL.String(), StatusCode: r.StatusCode, Header: r.Header}}, hops...)
+	}
+	return chain, hops
+}
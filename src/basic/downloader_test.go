@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/thiagolcmelo/webcrawler/src/basic"
 	"github.com/thiagolcmelo/webcrawler/src/content"
 )
@@ -53,6 +54,7 @@ func TestDownloader_Download(t *testing.T) {
 		expectedBody       []byte
 		expectedBodyHash   [32]byte
 		expectedCntentType string
+		expectedStatusCode int
 	}
 
 	testCases := []testCase{
@@ -63,6 +65,7 @@ func TestDownloader_Download(t *testing.T) {
 			expectedBody:       homeBody,
 			expectedBodyHash:   homeBodyHash,
 			expectedCntentType: homeContentType,
+			expectedStatusCode: http.StatusOK,
 		},
 		{
 			testName:           "request_to_valid_url_updates_content",
@@ -71,6 +74,7 @@ func TestDownloader_Download(t *testing.T) {
 			expectedBody:       contactBody,
 			expectedBodyHash:   contactBodyHash,
 			expectedCntentType: contactContentType,
+			expectedStatusCode: http.StatusOK,
 		},
 		{
 			testName:           "request_to_bad_url_fails",
@@ -87,6 +91,7 @@ func TestDownloader_Download(t *testing.T) {
 			expectedBody:       []byte{},
 			expectedBodyHash:   [32]byte{},
 			expectedCntentType: "",
+			expectedStatusCode: http.StatusNotFound,
 		},
 	}
 
@@ -115,6 +120,10 @@ func TestDownloader_Download(t *testing.T) {
 			if c.ContentType != tc.expectedCntentType {
 				t.Errorf("content type is not correct")
 			}
+
+			if c.StatusCode != tc.expectedStatusCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedStatusCode, c.StatusCode)
+			}
 		})
 	}
 }
@@ -249,3 +258,148 @@ func TestDownloader_Backoff(t *testing.T) {
 		})
 	}
 }
+
+func TestDownloader_Redirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+		case "/moved":
+			http.Redirect(w, r, "/new", http.StatusFound)
+		default:
+			w.Write([]byte("hello from new"))
+		}
+	}))
+	defer server.Close()
+
+	c, err := content.NewContent(fmt.Sprintf("%s/old", server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = basic.NewDownloader(1, time.Second, 2).Download(context.Background(), &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{fmt.Sprintf("%s/old", server.URL), fmt.Sprintf("%s/moved", server.URL)}
+	if diff := cmp.Diff(expected, c.Redirects); diff != "" {
+		t.Errorf("unexpected redirects (-expected +actual):\n%s", diff)
+	}
+
+	if expected := fmt.Sprintf("%s/new", server.URL); c.Location != expected {
+		t.Errorf("expected the final location %s, got %s", expected, c.Location)
+	}
+
+	if c.Elapsed <= 0 {
+		t.Errorf("expected the download to be timed")
+	}
+}
+
+func TestDownloader_Canceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.RequestURI == "/slow" {
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	type testCase struct {
+		testName string
+		url      string
+	}
+
+	testCases := []testCase{
+		{testName: "request_in_flight", url: fmt.Sprintf("%s/slow", server.URL)},
+		{testName: "waiting_for_retry", url: fmt.Sprintf("%s/unavailable", server.URL)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			c, err := content.NewContent(tc.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			err = basic.NewDownloader(3, 10*time.Second, 2).Download(ctx, &c)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("expected the download to stop with the context, it took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestDownloader_DownloadWithHeader(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("hello from home"))
+	}))
+	defer server.Close()
+
+	type testCase struct {
+		testName         string
+		header           http.Header
+		expectedErr      error
+		expectedStatus   int
+		expectedBody     string
+		expectedAttempts int
+	}
+
+	testCases := []testCase{
+		{
+			testName:         "modified",
+			header:           http.Header{"If-None-Match": {`"v0"`}},
+			expectedStatus:   http.StatusOK,
+			expectedBody:     "hello from home",
+			expectedAttempts: 1,
+		},
+		{
+			testName:         "not_modified_is_not_retried",
+			header:           http.Header{"If-None-Match": {`"v1"`}},
+			expectedErr:      basic.ErrNotModified,
+			expectedStatus:   http.StatusNotModified,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			attempts = 0
+			c, err := content.NewContent(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = basic.NewDownloader(3, time.Millisecond, 1).DownloadWithHeader(context.Background(), &c, tc.header)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected %v, got %v", tc.expectedErr, err)
+			}
+			if c.StatusCode != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, c.StatusCode)
+			}
+			if string(c.Body) != tc.expectedBody {
+				t.Errorf("expected body %q, got %q", tc.expectedBody, c.Body)
+			}
+			if c.Header.Get("ETag") != `"v1"` {
+				t.Errorf("expected the validators of the response, got %v", c.Header)
+			}
+			if attempts != tc.expectedAttempts {
+				t.Errorf("expected %d attempts, got %d", tc.expectedAttempts, attempts)
+			}
+		})
+	}
+}
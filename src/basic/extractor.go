@@ -0,0 +1,179 @@
+package basic
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"golang.org/x/net/html"
+)
+
+// textless lists the elements whose text is not visible on the page
+var textless = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"noscript": true,
+	"template": true,
+	"title":    true,
+}
+
+// loadedLinks lists the link relations whose target is loaded along with the page
+var loadedLinks = map[string]bool{
+	"stylesheet":    true,
+	"icon":          true,
+	"shortcut icon": true,
+	"preload":       true,
+}
+
+// Extractor is a basic implementation of the Extractor interface
+type Extractor struct{}
+
+// NewExtractor is a factory for basic.Extractor
+func NewExtractor() *Extractor {
+	return &Extractor{}
+}
+
+// Extract updates an HTML content with its metadata, other contents are left untouched
+func (e *Extractor) Extract(c *content.Content) error {
+	if !isHTML(c) {
+		return nil
+	}
+
+	m := content.NewMetadata()
+	hidden := ""
+	heading := ""
+	var text strings.Builder
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(c.Body))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			c.Metadata = m
+			return nil
+		case html.TextToken:
+			data := string(tokenizer.Text())
+			switch {
+			case hidden == "title":
+				if m.Title == "" {
+					m.Title = collapseSpaces(data)
+				}
+			case hidden != "":
+			default:
+				m.WordCount += len(strings.Fields(data))
+				if heading != "" {
+					text.WriteString(data)
+				}
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "html":
+				m.Lang, _ = getAttr(token, "lang")
+			case "h1", "h2":
+				heading = token.Data
+				text.Reset()
+			case "meta":
+				extractMeta(token, m)
+			case "link":
+				if alternate, ok := extractAlternate(c, token); ok {
+					m.Alternates = append(m.Alternates, alternate)
+				}
+				if rel, _ := getAttr(token, "rel"); loadedLinks[strings.ToLower(rel)] {
+					if href, ok := resolveAttr(c, token, "href"); ok {
+						m.Resources = append(m.Resources, href)
+					}
+				}
+			case "img":
+				if src, ok := resolveAttr(c, token, "src"); ok {
+					m.Resources = append(m.Resources, src)
+					if _, hasAlt := getAttr(token, "alt"); !hasAlt {
+						m.MissingAlt = append(m.MissingAlt, src)
+					}
+				}
+			case "script", "iframe", "audio", "video", "source", "embed":
+				if src, ok := resolveAttr(c, token, "src"); ok {
+					m.Resources = append(m.Resources, src)
+				}
+				if textless[token.Data] && token.Type == html.StartTagToken {
+					hidden = token.Data
+				}
+			default:
+				if textless[token.Data] && token.Type == html.StartTagToken {
+					hidden = token.Data
+				}
+			}
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			switch {
+			case token.Data == hidden:
+				hidden = ""
+			case token.Data == heading:
+				if heading == "h1" {
+					m.H1 = append(m.H1, collapseSpaces(text.String()))
+				} else {
+					m.H2 = append(m.H2, collapseSpaces(text.String()))
+				}
+				heading = ""
+			}
+		}
+	}
+}
+
+// isHTML informs if a content is an HTML page, contents without a type are assumed to be
+func isHTML(c *content.Content) bool {
+	return c.ContentType == "" || strings.Contains(strings.ToLower(c.ContentType), "html")
+}
+
+// extractMeta records the description and the Open Graph and Twitter card properties
+func extractMeta(token html.Token, m *content.Metadata) {
+	name, _ := getAttr(token, "name")
+	if name == "" {
+		name, _ = getAttr(token, "property")
+	}
+	name = strings.ToLower(name)
+	value, _ := getAttr(token, "content")
+
+	switch {
+	case name == "description":
+		m.Description = collapseSpaces(value)
+	case strings.HasPrefix(name, "og:"):
+		m.OpenGraph[strings.TrimPrefix(name, "og:")] = value
+	case strings.HasPrefix(name, "twitter:"):
+		m.Twitter[strings.TrimPrefix(name, "twitter:")] = value
+	}
+}
+
+// extractAlternate returns the language version a link points to with hreflang, resolved against the page
+func extractAlternate(c *content.Content, token html.Token) (content.Alternate, bool) {
+	rel, _ := getAttr(token, "rel")
+	lang, hasLang := getAttr(token, "hreflang")
+	href, hasHref := getAttr(token, "href")
+	if !strings.EqualFold(rel, "alternate") || !hasLang || !hasHref {
+		return content.Alternate{}, false
+	}
+
+	ref, err := url.Parse(href)
+	if err != nil {
+		return content.Alternate{}, false
+	}
+	return content.Alternate{Lang: lang, URL: c.Base().ResolveReference(ref).String()}, true
+}
+
+// resolveAttr returns an attribute holding a url resolved against the page
+func resolveAttr(c *content.Content, token html.Token, key string) (string, bool) {
+	value, ok := getAttr(token, key)
+	if !ok || value == "" {
+		return "", false
+	}
+	ref, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return "", false
+	}
+	return c.Base().ResolveReference(ref).String(), true
+}
+
+// collapseSpaces trims a text and joins its words with a single space
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,118 @@
+package basic_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/basic"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+)
+
+func TestExtractor_Extract(t *testing.T) {
+	type testCase struct {
+		testName         string
+		contentType      string
+		body             string
+		expectedMetadata *content.Metadata
+	}
+
+	withMetadata := func(update func(*content.Metadata)) *content.Metadata {
+		m := content.NewMetadata()
+		update(m)
+		return m
+	}
+
+	testCases := []testCase{
+		{
+			testName:         "empty_content_has_empty_metadata",
+			contentType:      "text/html",
+			body:             "",
+			expectedMetadata: content.NewMetadata(),
+		},
+		{
+			testName:         "non_html_content_is_skipped",
+			contentType:      "image/png",
+			body:             "<title>not a page</title>",
+			expectedMetadata: nil,
+		},
+		{
+			testName:    "title_description_and_lang",
+			contentType: "text/html; charset=utf-8",
+			body: `<html lang="en-GB"><head><title>
+				Some   Title</title><meta name="Description" content="A  short description"></head></html>`,
+			expectedMetadata: withMetadata(func(m *content.Metadata) {
+				m.Title = "Some Title"
+				m.Description = "A short description"
+				m.Lang = "en-GB"
+			}),
+		},
+		{
+			testName:    "headings_with_nested_elements",
+			contentType: "text/html",
+			body:        `<h1>Main <em>title</em></h1><h2>First</h2><p>text</p><h2>Second</h2>`,
+			expectedMetadata: withMetadata(func(m *content.Metadata) {
+				m.H1 = []string{"Main title"}
+				m.H2 = []string{"First", "Second"}
+				m.WordCount = 5
+			}),
+		},
+		{
+			testName:    "hreflang_alternates_are_resolved",
+			contentType: "text/html",
+			body:        `<link rel="alternate" hreflang="de" href="/de/path"><link rel="alternate" href="/feed.xml"><link rel="alternate" hreflang="x-default" href="http://domain.com/path">`,
+			expectedMetadata: withMetadata(func(m *content.Metadata) {
+				m.Alternates = []content.Alternate{
+					{Lang: "de", URL: "http://domain.com/de/path"},
+					{Lang: "x-default", URL: "http://domain.com/path"},
+				}
+			}),
+		},
+		{
+			testName:    "open_graph_and_twitter_cards",
+			contentType: "text/html",
+			body:        `<meta property="og:title" content="OG title"><meta property="og:image" content="/image.png"><meta name="twitter:card" content="summary">`,
+			expectedMetadata: withMetadata(func(m *content.Metadata) {
+				m.OpenGraph = map[string]string{"title": "OG title", "image": "/image.png"}
+				m.Twitter = map[string]string{"card": "summary"}
+			}),
+		},
+		{
+			testName:    "resources_and_images_without_alt",
+			contentType: "text/html",
+			body:        `<img src="/a.png" alt=""><img src="http://cdn.com/b.png"><script src="/app.js"></script><link rel="stylesheet" href="/style.css"><link rel="next" href="/page2">`,
+			expectedMetadata: withMetadata(func(m *content.Metadata) {
+				m.Resources = []string{"http://domain.com/a.png", "http://cdn.com/b.png", "http://domain.com/app.js", "http://domain.com/style.css"}
+				m.MissingAlt = []string{"http://cdn.com/b.png"}
+			}),
+		},
+		{
+			testName:    "word_count_skips_hidden_text",
+			contentType: "text/html",
+			body:        `<title>not counted</title><script>var notCounted = 1;</script><style>p { color: red }</style><p>only these four words</p>`,
+			expectedMetadata: withMetadata(func(m *content.Metadata) {
+				m.Title = "not counted"
+				m.WordCount = 4
+			}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			c, err := content.NewContent("http://domain.com/path")
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.ContentType = tc.contentType
+			c.Body = []byte(tc.body)
+
+			err = basic.NewExtractor().Extract(&c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.expectedMetadata, c.Metadata); diff != "" {
+				t.Errorf("unexpected metadata (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package basic
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/thiagolcmelo/webcrawler/src/content"
+)
+
+// FeedParser is an implementation of the Parser interface for RSS and Atom feeds, and for
+// other XML documents listing urls like sitemaps
+type FeedParser struct{}
+
+// NewFeedParser is a factory for basic.FeedParser
+func NewFeedParser() *FeedParser {
+	return &FeedParser{}
+}
+
+// Parse updates a content with the links of RSS items, Atom entries and enclosures, and sitemap locations
+func (fp *FeedParser) Parse(c *content.Content) error {
+	links := map[string][]string{}
+	decoder := xml.NewDecoder(bytes.NewReader(c.Body))
+	// feeds declare all sorts of encodings, urls are ASCII anyway
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	decoder.Strict = false
+
+	text := ""
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			text = ""
+			switch t.Name.Local {
+			case "link":
+				// Atom links point with href, RSS links hold the url as text
+				rel := xmlAttr(t, "rel")
+				if href := xmlAttr(t, "href"); href != "" && (rel == "" || rel == "alternate") {
+					links["link"] = append(links["link"], href)
+				}
+			case "enclosure":
+				if u := xmlAttr(t, "url"); u != "" {
+					links["enclosure"] = append(links["enclosure"], u)
+				}
+			}
+		case xml.CharData:
+			text += string(t)
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "link", "loc":
+				if u := strings.TrimSpace(text); u != "" {
+					links[t.Name.Local] = append(links[t.Name.Local], u)
+				}
+			}
+			text = ""
+		}
+	}
+
+	for _, element := range []string{"link", "loc", "enclosure"} {
+		addLinks(c, links[element], element)
+	}
+	return nil
+}
+
+func xmlAttr(element xml.StartElement, key string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == key {
+			return attr.Value
+		}
+	}
+	return ""
+}
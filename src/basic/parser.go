@@ -2,6 +2,8 @@ package basic
 
 import (
 	"bytes"
+	"net/url"
+	"strings"
 
 	"github.com/thiagolcmelo/webcrawler/src/content"
 	"golang.org/x/net/html"
@@ -15,53 +17,74 @@ func NewParser() *Parser {
 	return &Parser{}
 }
 
-func (ep Parser) extractLinksFromData(data []byte) ([]string, error) {
+func (ep Parser) extractLinksFromData(data []byte) ([]string, string, error) {
 	links := []string{}
+	canonical := ""
 	reader := bytes.NewReader(data)
 	tokenizer := html.NewTokenizer(reader)
 	for {
 		switch tokenizer.Next() {
 		case html.ErrorToken:
-			return links, nil
-		case html.StartTagToken, html.EndTagToken:
+			return links, canonical, nil
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
 			token := tokenizer.Token()
-			if token.Data == "a" {
-				for _, attr := range token.Attr {
-					if attr.Key == "href" {
-						links = append(links, attr.Val)
-					}
+			switch token.Data {
+			case "a":
+				if href, ok := getAttr(token, "href"); ok {
+					links = append(links, href)
+				}
+			case "link":
+				if rel, _ := getAttr(token, "rel"); strings.EqualFold(rel, "canonical") {
+					canonical, _ = getAttr(token, "href")
 				}
 			}
 		}
 	}
 }
 
+func getAttr(token html.Token, key string) (string, bool) {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
 // Parse updates a content object with the links existing in its body
 func (ep *Parser) Parse(c *content.Content) error {
-	links, err := ep.extractLinksFromData(c.Body)
+	links, canonical, err := ep.extractLinksFromData(c.Body)
 	if err != nil {
 		return err
 	}
 
-	// add scheme and hostname if needed
-	for i, l := 0, len(links); i < l; i++ {
+	if canonical != "" {
+		if ref, err := url.Parse(canonical); err == nil {
+			if canonicalContent, err := content.NewContent(c.Base().ResolveReference(ref).String()); err == nil {
+				c.Canonical = canonicalContent.Address
+			}
+		}
+	}
+
+	addLinks(c, links, "a")
 
-		linkAsContent, err := content.NewContent(links[i])
+	return nil
+}
+
+// addLinks resolves links against the content and adds the ones in the same host as children
+func addLinks(c *content.Content, links []string, element string) {
+	for _, link := range links {
+		ref, err := url.Parse(strings.TrimSpace(link))
 		if err != nil {
 			continue
 		}
-		if linkAsContent.Hostname() == "" {
-			linkAsContent.Host = c.Host
-		} else if linkAsContent.Hostname() != c.Hostname() {
+		linkAsContent, err := content.NewContent(c.Base().ResolveReference(ref).String())
+		if err != nil || linkAsContent.Hostname() != c.Hostname() {
 			continue
 		}
-
-		if linkAsContent.Scheme == "" {
-			linkAsContent.Scheme = c.Scheme
+		if linkAsContent.Scheme != "http" && linkAsContent.Scheme != "https" {
+			continue
 		}
-
-		c.Children[linkAsContent.String()] = struct{}{}
+		c.AddChild(linkAsContent.Address, element)
 	}
-
-	return nil
 }
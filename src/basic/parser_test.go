@@ -114,3 +114,58 @@ func TestParser_Parse(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_ParseCanonical(t *testing.T) {
+	type testCase struct {
+		testName          string
+		url               string
+		body              string
+		expectedCanonical string
+	}
+
+	testCases := []testCase{
+		{
+			testName:          "no_canonical_link",
+			url:               "http://domain.com/path",
+			body:              "<a href=\"/path1\">link</a>",
+			expectedCanonical: "",
+		},
+		{
+			testName:          "absolute_canonical_link",
+			url:               "http://domain.com/path?page=1",
+			body:              "<link rel=\"canonical\" href=\"http://domain.com/path\">",
+			expectedCanonical: "http://domain.com/path",
+		},
+		{
+			testName:          "relative_canonical_link",
+			url:               "http://domain.com/path?page=1",
+			body:              "<link rel=\"Canonical\" href=\"/path\" />",
+			expectedCanonical: "http://domain.com/path",
+		},
+		{
+			testName:          "other_link_relations_are_ignored",
+			url:               "http://domain.com/path",
+			body:              "<link rel=\"stylesheet\" href=\"/style.css\">",
+			expectedCanonical: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			c, err := content.NewContent(tc.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.Body = []byte(tc.body)
+
+			err = basic.NewParser().Parse(&c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.Canonical != tc.expectedCanonical {
+				t.Errorf("expected %q, got %q", tc.expectedCanonical, c.Canonical)
+			}
+		})
+	}
+}
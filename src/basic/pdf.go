@@ -0,0 +1,54 @@
+package basic
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/thiagolcmelo/webcrawler/src/content"
+)
+
+var (
+	pdfURIPattern    = regexp.MustCompile(`/URI\s*\(((?:\\.|[^\\)])*)\)`)
+	pdfStreamPattern = regexp.MustCompile(`(?s)stream\r?\n(.*?)\r?\nendstream`)
+	pdfUnescaper     = strings.NewReplacer(`\(`, "(", `\)`, ")", `\\`, `\`)
+)
+
+// maxPDFStream bounds how much a compressed PDF stream may inflate to
+const maxPDFStream = 10 << 20
+
+// PDFParser is an implementation of the Parser interface for PDF documents
+type PDFParser struct{}
+
+// NewPDFParser is a factory for basic.PDFParser
+func NewPDFParser() *PDFParser {
+	return &PDFParser{}
+}
+
+// Parse updates a content with the URI actions of its link annotations, found in the plain
+// objects and in the deflated object streams
+func (pp *PDFParser) Parse(c *content.Content) error {
+	links := pdfURIs(c.Body)
+	for _, match := range pdfStreamPattern.FindAllSubmatch(c.Body, -1) {
+		reader, err := zlib.NewReader(bytes.NewReader(match[1]))
+		if err != nil {
+			// not every stream is deflated
+			continue
+		}
+		inflated, _ := io.ReadAll(io.LimitReader(reader, maxPDFStream))
+		reader.Close()
+		links = append(links, pdfURIs(inflated)...)
+	}
+	addLinks(c, links, "pdf")
+	return nil
+}
+
+func pdfURIs(data []byte) []string {
+	links := []string{}
+	for _, match := range pdfURIPattern.FindAllSubmatch(data, -1) {
+		links = append(links, pdfUnescaper.Replace(string(match[1])))
+	}
+	return links
+}
@@ -0,0 +1,68 @@
+package basic
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/parser"
+)
+
+// Registry is an implementation of the Parser interface delegating to a parser per media type
+type Registry struct {
+	parsers map[string]parser.Parser
+}
+
+// NewRegistry is a factory for basic.Registry, it knows HTML, RSS and Atom feeds and other XML,
+// CSS, plain text and PDF
+func NewRegistry() *Registry {
+	r := &Registry{parsers: map[string]parser.Parser{}}
+
+	htmlParser := NewParser()
+	r.Register("text/html", htmlParser)
+	r.Register("application/xhtml+xml", htmlParser)
+
+	feedParser := NewFeedParser()
+	r.Register("application/rss+xml", feedParser)
+	r.Register("application/x-rss+xml", feedParser)
+	r.Register("application/atom+xml", feedParser)
+	r.Register("application/xml", feedParser)
+	r.Register("text/xml", feedParser)
+
+	r.Register("text/css", NewCSSParser())
+	r.Register("text/plain", NewTextParser())
+	r.Register("application/pdf", NewPDFParser())
+	return r
+}
+
+// Register sets the parser for a media type, replacing any previous one
+func (r *Registry) Register(mediaType string, p parser.Parser) {
+	r.parsers[strings.ToLower(mediaType)] = p
+}
+
+// Parse updates a content with the links found by the parser of its media type, contents
+// without a type are sniffed and unknown types fail with parser.ErrUnsupportedContentType
+func (r *Registry) Parse(c *content.Content) error {
+	mediaType := MediaType(c)
+	p, ok := r.parsers[mediaType]
+	if !ok {
+		return fmt.Errorf("%w [%s]", parser.ErrUnsupportedContentType, mediaType)
+	}
+	return p.Parse(c)
+}
+
+// MediaType returns the media type of a content without its parameters, sniffed from the
+// body when the content has no type
+func MediaType(c *content.Content) string {
+	contentType := c.ContentType
+	if contentType == "" {
+		contentType = http.DetectContentType(c.Body)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+	return mediaType
+}
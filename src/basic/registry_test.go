@@ -0,0 +1,148 @@
+package basic_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/thiagolcmelo/webcrawler/src/basic"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/parser"
+)
+
+func deflatedPDF(t *testing.T, object string) string {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write([]byte(object)); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return "%PDF-1.4\n1 0 obj\n<< /Filter /FlateDecode >>\nstream\n" + buf.String() + "\nendstream\nendobj\n%%EOF"
+}
+
+func TestRegistry_Parse(t *testing.T) {
+	type testCase struct {
+		testName         string
+		contentType      string
+		body             string
+		expectedLinks    []string
+		expectedElements map[string]string
+		expectedErr      error
+	}
+
+	testCases := []testCase{
+		{
+			testName:      "html",
+			contentType:   "text/html; charset=utf-8",
+			body:          `<a href="/path">link</a>`,
+			expectedLinks: []string{"http://domain.com/path"},
+		},
+		{
+			testName:      "html_is_sniffed_without_content_type",
+			contentType:   "",
+			body:          `<html><a href="/path">link</a></html>`,
+			expectedLinks: []string{"http://domain.com/path"},
+		},
+		{
+			testName:    "rss_feed",
+			contentType: "application/rss+xml",
+			body: `<?xml version="1.0" encoding="ISO-8859-1"?><rss><channel><link>http://domain.com/</link>
+				<atom:link href="http://domain.com/feed" rel="self"/>
+				<item><link> http://domain.com/post </link><enclosure url="/episode.mp3"/></item>
+				<item><link>http://other.com/post</link></item></channel></rss>`,
+			expectedLinks: []string{"http://domain.com/", "http://domain.com/post", "http://domain.com/episode.mp3"},
+			expectedElements: map[string]string{
+				"http://domain.com/":            "link",
+				"http://domain.com/post":        "link",
+				"http://domain.com/episode.mp3": "enclosure",
+			},
+		},
+		{
+			testName:    "atom_feed",
+			contentType: "application/atom+xml",
+			body: `<feed xmlns="http://www.w3.org/2005/Atom"><link href="/" /><link rel="self" href="/feed"/>
+				<entry><link rel="alternate" href="http://domain.com/entry"/></entry></feed>`,
+			expectedLinks: []string{"http://domain.com/", "http://domain.com/entry"},
+		},
+		{
+			testName:         "xml_sitemap",
+			contentType:      "application/xml",
+			body:             `<urlset><url><loc>http://domain.com/page</loc></url></urlset>`,
+			expectedLinks:    []string{"http://domain.com/page"},
+			expectedElements: map[string]string{"http://domain.com/page": "loc"},
+		},
+		{
+			testName:      "css",
+			contentType:   "text/css",
+			body:          `@import "base.css"; @import url('/print.css'); a { background: url(img/bg.png) } b { background: url("data:image/png;base64,AA") }`,
+			expectedLinks: []string{"http://domain.com/dir/base.css", "http://domain.com/print.css", "http://domain.com/dir/img/bg.png"},
+		},
+		{
+			testName:      "plain_text",
+			contentType:   "text/plain",
+			body:          "See http://domain.com/a, and (http://domain.com/b). Also http://domain.com/c_(d) and http://other.com/x.",
+			expectedLinks: []string{"http://domain.com/a", "http://domain.com/b", "http://domain.com/c_(d)"},
+		},
+		{
+			testName:      "pdf_annotations",
+			contentType:   "application/pdf",
+			body:          "%PDF-1.4\n1 0 obj << /A << /S /URI /URI (http://domain.com/a\\(1\\)) >> >> endobj\n" + deflatedPDF(t, "<< /A << /URI (/b) >> >>"),
+			expectedLinks: []string{"http://domain.com/a(1)", "http://domain.com/b"},
+		},
+		{
+			testName:      "unknown_type",
+			contentType:   "image/png",
+			body:          "\x89PNG",
+			expectedLinks: []string{},
+			expectedErr:   parser.ErrUnsupportedContentType,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			c, err := content.NewContent("http://domain.com/dir/file")
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.ContentType = tc.contentType
+			c.Body = []byte(tc.body)
+
+			err = basic.NewRegistry().Parse(&c)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected %v, got %v", tc.expectedErr, err)
+			}
+
+			less := func(a, b string) bool { return a < b }
+			if diff := cmp.Diff(tc.expectedLinks, c.GetChildrenList(), cmpopts.SortSlices(less)); diff != "" {
+				t.Errorf("unexpected links (-expected +actual):\n%s", diff)
+			}
+			for link, element := range tc.expectedElements {
+				if c.Elements[link] != element {
+					t.Errorf("expected element %s for %s, got %s", element, link, c.Elements[link])
+				}
+			}
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	c, err := content.NewContent("http://domain.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.ContentType = "text/markdown"
+	c.Body = []byte("[link](http://domain.com/path)")
+
+	registry := basic.NewRegistry()
+	registry.Register("Text/Markdown", basic.NewTextParser())
+	if err := registry.Parse(&c); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"http://domain.com/path"}, c.GetChildrenList()); diff != "" {
+		t.Errorf("unexpected links (-expected +actual):\n%s", diff)
+	}
+}
@@ -0,0 +1,172 @@
+package basic
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/scraper"
+	"golang.org/x/net/html"
+)
+
+var (
+	// ErrInvalidField should be used when a field has no selector or more than one
+	ErrInvalidField = errors.New("field must have either a css selector or an xpath expression")
+	// ErrUnknownTransform should be used when a field refers to a transform that does not exist
+	ErrUnknownTransform = errors.New("unknown transform")
+)
+
+var numberPattern = regexp.MustCompile(`-?\d[\d,]*(\.\d+)?`)
+
+// transforms maps each transform name to how it changes a value, the content is where it was found
+var transforms = map[string]func(c *content.Content, value string) string{
+	"trim":  func(_ *content.Content, value string) string { return strings.TrimSpace(value) },
+	"lower": func(_ *content.Content, value string) string { return strings.ToLower(value) },
+	"upper": func(_ *content.Content, value string) string { return strings.ToUpper(value) },
+	"number": func(_ *content.Content, value string) string {
+		return strings.ReplaceAll(numberPattern.FindString(value), ",", "")
+	},
+	"absolute": func(c *content.Content, value string) string { return resolve(c, value) },
+}
+
+// compiledField is a field with its selector, regular expression and transforms ready to run
+type compiledField struct {
+	scraper.Field
+	selector   cascadia.Sel
+	expr       *xpath.Expr
+	regex      *regexp.Regexp
+	transforms []func(*content.Content, string) string
+}
+
+// compiledRule is a rule with its url pattern ready to run
+type compiledRule struct {
+	pattern *regexp.Regexp
+	fields  []compiledField
+}
+
+// Scraper is a basic implementation of the Scraper interface
+type Scraper struct {
+	rules []compiledRule
+}
+
+// NewScraper is a factory for basic.Scraper, it fails when a pattern, selector, regular
+// expression or transform of the rules is invalid
+func NewScraper(rules scraper.Rules) (*Scraper, error) {
+	s := &Scraper{}
+	for _, rule := range rules.Rules {
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern [%s]: %w", rule.Pattern, err)
+		}
+		compiled := compiledRule{pattern: pattern}
+		for _, field := range rule.Fields {
+			cf, err := compileField(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid field [%s]: %w", field.Name, err)
+			}
+			compiled.fields = append(compiled.fields, cf)
+		}
+		s.rules = append(s.rules, compiled)
+	}
+	return s, nil
+}
+
+func compileField(field scraper.Field) (compiledField, error) {
+	cf := compiledField{Field: field}
+	var err error
+	switch {
+	case field.CSS != "" && field.XPath == "":
+		cf.selector, err = cascadia.Parse(field.CSS)
+	case field.XPath != "" && field.CSS == "":
+		cf.expr, err = xpath.Compile(field.XPath)
+	default:
+		err = ErrInvalidField
+	}
+	if err != nil {
+		return cf, err
+	}
+
+	if field.Regex != "" {
+		if cf.regex, err = regexp.Compile(field.Regex); err != nil {
+			return cf, err
+		}
+	}
+
+	for _, name := range field.Transforms {
+		transform, ok := transforms[name]
+		if !ok {
+			return cf, fmt.Errorf("%w [%s]", ErrUnknownTransform, name)
+		}
+		cf.transforms = append(cf.transforms, transform)
+	}
+	return cf, nil
+}
+
+// Scrape updates an HTML content with the fields of every rule matching its url,
+// fields matching nothing are left out
+func (s *Scraper) Scrape(c *content.Content) error {
+	if !isHTML(c) {
+		return nil
+	}
+
+	var doc *html.Node
+	for _, rule := range s.rules {
+		if !rule.pattern.MatchString(c.Address) {
+			continue
+		}
+		if doc == nil {
+			var err error
+			if doc, err = html.Parse(bytes.NewReader(c.Body)); err != nil {
+				return err
+			}
+		}
+		for _, field := range rule.fields {
+			if values := field.scrape(c, doc); len(values) > 0 {
+				c.AddScraped(field.Name, values...)
+			}
+		}
+	}
+	return nil
+}
+
+func (cf compiledField) scrape(c *content.Content, doc *html.Node) []string {
+	var nodes []*html.Node
+	if cf.expr != nil {
+		nodes = htmlquery.QuerySelectorAll(doc, cf.expr)
+	} else {
+		nodes = cascadia.QueryAll(doc, cf.selector)
+	}
+
+	values := []string{}
+	for _, n := range nodes {
+		value := collapseSpaces(htmlquery.InnerText(n))
+		if cf.Attr != "" {
+			value = htmlquery.SelectAttr(n, cf.Attr)
+		}
+		if cf.regex != nil {
+			match := cf.regex.FindStringSubmatch(value)
+			if match == nil {
+				continue
+			}
+			// the first group is the value when there is one
+			value = match[0]
+			if len(match) > 1 {
+				value = match[1]
+			}
+		}
+		for _, transform := range cf.transforms {
+			value = transform(c, value)
+		}
+		values = append(values, value)
+		if !cf.All {
+			break
+		}
+	}
+	return values
+}
@@ -0,0 +1,169 @@
+package basic_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/basic"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/scraper"
+)
+
+func TestScraper_Scrape(t *testing.T) {
+	body := `<html><head><title>Widget | Shop</title></head><body>
+		<h1 class="product-name"> Blue
+			Widget </h1>
+		<span class="price">Price: $1,299.50</span>
+		<img class="gallery" src="/a.png"><img class="gallery" src="/b.png">
+		<table><tr><td>SKU</td><td>W-42</td></tr></table>
+	</body></html>`
+
+	type testCase struct {
+		testName        string
+		url             string
+		contentType     string
+		fields          []scraper.Field
+		expectedScraped map[string][]string
+	}
+
+	testCases := []testCase{
+		{
+			testName:        "css_text",
+			url:             "http://domain.com/product/1",
+			contentType:     "text/html",
+			fields:          []scraper.Field{{Name: "name", CSS: "h1.product-name"}},
+			expectedScraped: map[string][]string{"name": {"Blue Widget"}},
+		},
+		{
+			testName:        "xpath_text",
+			url:             "http://domain.com/product/1",
+			contentType:     "text/html",
+			fields:          []scraper.Field{{Name: "sku", XPath: "//td[text()='SKU']/following-sibling::td"}},
+			expectedScraped: map[string][]string{"sku": {"W-42"}},
+		},
+		{
+			testName:        "first_match_unless_all",
+			url:             "http://domain.com/product/1",
+			contentType:     "text/html",
+			fields:          []scraper.Field{{Name: "image", CSS: "img.gallery", Attr: "src"}},
+			expectedScraped: map[string][]string{"image": {"/a.png"}},
+		},
+		{
+			testName:    "all_matches_with_transform",
+			url:         "http://domain.com/product/1",
+			contentType: "text/html",
+			fields:      []scraper.Field{{Name: "images", CSS: "img.gallery", Attr: "src", All: true, Transforms: []string{"absolute"}}},
+			expectedScraped: map[string][]string{
+				"images": {"http://domain.com/a.png", "http://domain.com/b.png"},
+			},
+		},
+		{
+			testName:        "xpath_attribute",
+			url:             "http://domain.com/product/1",
+			contentType:     "text/html",
+			fields:          []scraper.Field{{Name: "image", XPath: "//img/@src"}},
+			expectedScraped: map[string][]string{"image": {"/a.png"}},
+		},
+		{
+			testName:    "regex_and_transforms",
+			url:         "http://domain.com/product/1",
+			contentType: "text/html",
+			fields: []scraper.Field{
+				{Name: "price", CSS: ".price", Transforms: []string{"number"}},
+				{Name: "shop", CSS: "title", Regex: `\| (\w+)`, Transforms: []string{"upper"}},
+			},
+			expectedScraped: map[string][]string{"price": {"1299.50"}, "shop": {"SHOP"}},
+		},
+		{
+			testName:        "fields_matching_nothing_are_left_out",
+			url:             "http://domain.com/product/1",
+			contentType:     "text/html",
+			fields:          []scraper.Field{{Name: "missing", CSS: ".missing"}},
+			expectedScraped: map[string][]string{},
+		},
+		{
+			testName:        "urls_not_matching_the_pattern_are_skipped",
+			url:             "http://domain.com/about",
+			contentType:     "text/html",
+			fields:          []scraper.Field{{Name: "name", CSS: "h1"}},
+			expectedScraped: map[string][]string{},
+		},
+		{
+			testName:        "non_html_content_is_skipped",
+			url:             "http://domain.com/product/1",
+			contentType:     "application/json",
+			fields:          []scraper.Field{{Name: "name", CSS: "h1"}},
+			expectedScraped: map[string][]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			s, err := basic.NewScraper(scraper.Rules{Rules: []scraper.Rule{{Pattern: "^http://domain.com/product/", Fields: tc.fields}}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := content.NewContent(tc.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.ContentType = tc.contentType
+			c.Body = []byte(body)
+
+			if err := s.Scrape(&c); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.expectedScraped, c.Scraped); diff != "" {
+				t.Errorf("unexpected fields (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewScraper_InvalidRules(t *testing.T) {
+	type testCase struct {
+		testName    string
+		rule        scraper.Rule
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{
+			testName:    "field_without_selector",
+			rule:        scraper.Rule{Pattern: ".", Fields: []scraper.Field{{Name: "name"}}},
+			expectedErr: basic.ErrInvalidField,
+		},
+		{
+			testName:    "field_with_both_selectors",
+			rule:        scraper.Rule{Pattern: ".", Fields: []scraper.Field{{Name: "name", CSS: "h1", XPath: "//h1"}}},
+			expectedErr: basic.ErrInvalidField,
+		},
+		{
+			testName:    "unknown_transform",
+			rule:        scraper.Rule{Pattern: ".", Fields: []scraper.Field{{Name: "name", CSS: "h1", Transforms: []string{"reverse"}}}},
+			expectedErr: basic.ErrUnknownTransform,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			_, err := basic.NewScraper(scraper.Rules{Rules: []scraper.Rule{tc.rule}})
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+
+	for _, rule := range []scraper.Rule{
+		{Pattern: "(", Fields: []scraper.Field{}},
+		{Pattern: ".", Fields: []scraper.Field{{Name: "name", CSS: "h1["}}},
+		{Pattern: ".", Fields: []scraper.Field{{Name: "name", XPath: "//h1["}}},
+	} {
+		if _, err := basic.NewScraper(scraper.Rules{Rules: []scraper.Rule{rule}}); err == nil {
+			t.Errorf("expected an error for %#v", rule)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package basic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"golang.org/x/net/html"
+)
+
+// StructuredDataExtractor is an implementation of the Extractor interface for schema.org
+// items written as JSON-LD, microdata or RDFa
+type StructuredDataExtractor struct{}
+
+// NewStructuredDataExtractor is a factory for basic.StructuredDataExtractor
+func NewStructuredDataExtractor() *StructuredDataExtractor {
+	return &StructuredDataExtractor{}
+}
+
+// Extract updates an HTML content with its structured data, invalid JSON-LD blocks are
+// recorded as warnings and skipped
+func (se *StructuredDataExtractor) Extract(c *content.Content) error {
+	if !isHTML(c) {
+		return nil
+	}
+
+	doc, err := html.Parse(bytes.NewReader(c.Body))
+	if err != nil {
+		return err
+	}
+
+	items := []*content.StructuredItem{}
+	walk(doc, func(n *html.Node) bool {
+		switch {
+		case n.Type != html.ElementNode:
+		case n.Data == "script" && strings.EqualFold(strings.TrimSpace(attr(n, "type")), "application/ld+json"):
+			jsonLD, err := parseJSONLD(text(n))
+			if err != nil {
+				c.AddWarning(fmt.Sprintf("invalid json-ld: %v", err))
+			}
+			items = append(items, jsonLD...)
+			return false
+		case hasAttr(n, "itemscope") && !hasAttr(n, "itemprop"):
+			items = append(items, microdataItem(c, n))
+		case hasAttr(n, "typeof") && !hasAttr(n, "property"):
+			items = append(items, rdfaItem(c, n, vocab(n)))
+		}
+		return true
+	})
+	c.Structured = items
+	return nil
+}
+
+// parseJSONLD normalizes the nodes of a JSON-LD block, a single node, a list or a @graph
+func parseJSONLD(data string) ([]*content.StructuredItem, error) {
+	var value interface{}
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	items := []*content.StructuredItem{}
+	for _, node := range flatten(value) {
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return items, fmt.Errorf("expected an object, got %v", node)
+		}
+		context, _ := object["@context"].(string)
+		if graph, ok := object["@graph"]; ok {
+			for _, graphNode := range flatten(graph) {
+				if graphObject, ok := graphNode.(map[string]interface{}); ok {
+					item := jsonLDItem(graphObject)
+					item.Context = context
+					items = append(items, item)
+				}
+			}
+			continue
+		}
+		item := jsonLDItem(object)
+		item.Context = context
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func jsonLDItem(object map[string]interface{}) *content.StructuredItem {
+	item := content.NewStructuredItem("json-ld")
+	for key, value := range object {
+		switch key {
+		case "@context", "@graph":
+		case "@type":
+			for _, t := range flatten(value) {
+				if s, ok := t.(string); ok {
+					item.Type = append(item.Type, s)
+				}
+			}
+		case "@id":
+			item.ID, _ = value.(string)
+		default:
+			for _, v := range flatten(value) {
+				if nested, ok := v.(map[string]interface{}); ok {
+					item.AddProperty(key, jsonLDItem(nested))
+				} else {
+					item.AddProperty(key, v)
+				}
+			}
+		}
+	}
+	return item
+}
+
+// flatten turns a JSON value into a list of values, arrays are unpacked
+func flatten(value interface{}) []interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{value}
+}
+
+// microdataItem reads an itemscope element and the itemprop elements under it
+func microdataItem(c *content.Content, n *html.Node) *content.StructuredItem {
+	item := content.NewStructuredItem("microdata")
+	item.Type = append(item.Type, strings.Fields(attr(n, "itemtype"))...)
+	item.ID = attr(n, "itemid")
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, func(d *html.Node) bool {
+			if d.Type != html.ElementNode {
+				return true
+			}
+			scoped := hasAttr(d, "itemscope")
+			for _, name := range strings.Fields(attr(d, "itemprop")) {
+				if scoped {
+					item.AddProperty(name, microdataItem(c, d))
+				} else {
+					item.AddProperty(name, propertyValue(c, d, "itemprop"))
+				}
+			}
+			// properties of nested items belong to them
+			return !scoped
+		})
+	}
+	return item
+}
+
+// rdfaItem reads a typeof element and the property elements under it, vocabulary is the one in scope
+func rdfaItem(c *content.Content, n *html.Node, vocabulary string) *content.StructuredItem {
+	item := content.NewStructuredItem("rdfa")
+	item.Context = vocabulary
+	item.Type = append(item.Type, strings.Fields(attr(n, "typeof"))...)
+	item.ID = attr(n, "resource")
+	if item.ID == "" {
+		item.ID = attr(n, "about")
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, func(d *html.Node) bool {
+			if d.Type != html.ElementNode {
+				return true
+			}
+			typed := hasAttr(d, "typeof")
+			for _, name := range strings.Fields(attr(d, "property")) {
+				if typed {
+					item.AddProperty(name, rdfaItem(c, d, vocab(d)))
+				} else {
+					item.AddProperty(name, propertyValue(c, d, "property"))
+				}
+			}
+			return !typed
+		})
+	}
+	return item
+}
+
+// vocab returns the RDFa vocabulary in scope of a node
+func vocab(n *html.Node) string {
+	for ; n != nil; n = n.Parent {
+		if v := attr(n, "vocab"); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// propertyValue reads the value of a microdata or RDFa property element, urls are resolved against the page
+func propertyValue(c *content.Content, n *html.Node, syntax string) string {
+	if syntax == "property" && hasAttr(n, "content") {
+		return attr(n, "content")
+	}
+	switch n.Data {
+	case "meta":
+		return attr(n, "content")
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		return resolve(c, attr(n, "src"))
+	case "a", "area", "link":
+		return resolve(c, attr(n, "href"))
+	case "object":
+		return resolve(c, attr(n, "data"))
+	case "data", "meter":
+		return attr(n, "value")
+	case "time":
+		if hasAttr(n, "datetime") {
+			return attr(n, "datetime")
+		}
+	}
+	return collapseSpaces(text(n))
+}
+
+func resolve(c *content.Content, address string) string {
+	ref, err := url.Parse(strings.TrimSpace(address))
+	if err != nil {
+		return address
+	}
+	return c.Base().ResolveReference(ref).String()
+}
+
+// walk visits a node and its descendants in document order, descending while visit returns true
+func walk(n *html.Node, visit func(*html.Node) bool) {
+	if !visit(n) {
+		return
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, visit)
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// text concatenates the text under a node
+func text(n *html.Node) string {
+	var b strings.Builder
+	walk(n, func(d *html.Node) bool {
+		if d.Type == html.TextNode {
+			b.WriteString(d.Data)
+		}
+		return true
+	})
+	return b.String()
+}
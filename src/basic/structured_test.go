@@ -0,0 +1,142 @@
+package basic_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/basic"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+)
+
+func TestStructuredDataExtractor_Extract(t *testing.T) {
+	type testCase struct {
+		testName         string
+		contentType      string
+		body             string
+		expectedItems    []*content.StructuredItem
+		expectedWarnings []string
+	}
+
+	item := func(format string, context string, types []string, id string, properties map[string][]interface{}) *content.StructuredItem {
+		return &content.StructuredItem{Format: format, Context: context, Type: types, ID: id, Properties: properties}
+	}
+
+	testCases := []testCase{
+		{
+			testName:         "no_structured_data",
+			contentType:      "text/html",
+			body:             "<p>nothing here</p>",
+			expectedItems:    []*content.StructuredItem{},
+			expectedWarnings: []string{},
+		},
+		{
+			testName:         "non_html_content_is_skipped",
+			contentType:      "application/json",
+			body:             `<script type="application/ld+json">{"@type": "Thing"}</script>`,
+			expectedItems:    []*content.StructuredItem{},
+			expectedWarnings: []string{},
+		},
+		{
+			testName:    "json_ld_with_nested_nodes",
+			contentType: "text/html",
+			body: `<script type="application/ld+json">
+				{"@context": "https://schema.org", "@type": "Product", "@id": "#widget", "name": "Widget",
+				 "offers": {"@type": "Offer", "price": 9.99}, "color": ["red", "blue"]}
+			</script>`,
+			expectedItems: []*content.StructuredItem{
+				item("json-ld", "https://schema.org", []string{"Product"}, "#widget", map[string][]interface{}{
+					"name":   {"Widget"},
+					"color":  {"red", "blue"},
+					"offers": {item("json-ld", "", []string{"Offer"}, "", map[string][]interface{}{"price": {json.Number("9.99")}})},
+				}),
+			},
+			expectedWarnings: []string{},
+		},
+		{
+			testName:    "json_ld_graph",
+			contentType: "text/html",
+			body:        `<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [{"@type": "WebSite"}, {"@type": ["Organization", "Brand"]}]}</script>`,
+			expectedItems: []*content.StructuredItem{
+				item("json-ld", "https://schema.org", []string{"WebSite"}, "", map[string][]interface{}{}),
+				item("json-ld", "https://schema.org", []string{"Organization", "Brand"}, "", map[string][]interface{}{}),
+			},
+			expectedWarnings: []string{},
+		},
+		{
+			testName:    "invalid_json_ld_is_a_warning",
+			contentType: "text/html",
+			body:        `<script type="application/ld+json">{"@type": </script><script type="application/ld+json">{"@type": "Thing"}</script>`,
+			expectedItems: []*content.StructuredItem{
+				item("json-ld", "", []string{"Thing"}, "", map[string][]interface{}{}),
+			},
+			expectedWarnings: []string{"invalid json-ld: unexpected EOF"},
+		},
+		{
+			testName:    "microdata_with_nested_item",
+			contentType: "text/html",
+			body: `<div itemscope itemtype="https://schema.org/Person" itemid="urn:ada">
+				<span itemprop="name">Ada  Lovelace</span>
+				<a itemprop="url sameAs" href="/ada">home</a>
+				<meta itemprop="birthDate" content="1815-12-10">
+				<div itemprop="address" itemscope itemtype="https://schema.org/PostalAddress">
+					<span itemprop="addressLocality">London</span>
+				</div>
+			</div>`,
+			expectedItems: []*content.StructuredItem{
+				item("microdata", "", []string{"https://schema.org/Person"}, "urn:ada", map[string][]interface{}{
+					"name":      {"Ada Lovelace"},
+					"url":       {"http://domain.com/ada"},
+					"sameAs":    {"http://domain.com/ada"},
+					"birthDate": {"1815-12-10"},
+					"address": {item("microdata", "", []string{"https://schema.org/PostalAddress"}, "", map[string][]interface{}{
+						"addressLocality": {"London"},
+					})},
+				}),
+			},
+			expectedWarnings: []string{},
+		},
+		{
+			testName:    "rdfa_with_vocab",
+			contentType: "text/html",
+			body: `<div vocab="https://schema.org/" typeof="Event" resource="#launch">
+				<span property="name">Launch</span>
+				<time property="startDate" datetime="2024-01-01">Jan 1</time>
+				<div property="location" typeof="Place"><span property="name" content="Hall">The hall</span></div>
+			</div>`,
+			expectedItems: []*content.StructuredItem{
+				item("rdfa", "https://schema.org/", []string{"Event"}, "#launch", map[string][]interface{}{
+					"name":      {"Launch"},
+					"startDate": {"2024-01-01"},
+					"location": {item("rdfa", "https://schema.org/", []string{"Place"}, "", map[string][]interface{}{
+						"name": {"Hall"},
+					})},
+				}),
+			},
+			expectedWarnings: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			c, err := content.NewContent("http://domain.com/path")
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.ContentType = tc.contentType
+			c.Body = []byte(tc.body)
+
+			err = basic.NewStructuredDataExtractor().Extract(&c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.expectedItems, c.Structured); diff != "" {
+				t.Errorf("unexpected items (-expected +actual):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedWarnings, c.Warnings); diff != "" {
+				t.Errorf("unexpected warnings (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package basic
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/thiagolcmelo/webcrawler/src/content"
+)
+
+var textURLPattern = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+
+// TextParser is an implementation of the Parser interface for plain text documents
+type TextParser struct{}
+
+// NewTextParser is a factory for basic.TextParser
+func NewTextParser() *TextParser {
+	return &TextParser{}
+}
+
+// Parse updates a content with the absolute http(s) urls written in its text
+func (tp *TextParser) Parse(c *content.Content) error {
+	links := []string{}
+	for _, match := range textURLPattern.FindAll(c.Body, -1) {
+		links = append(links, trimTrailingPunctuation(string(match)))
+	}
+	addLinks(c, links, "text")
+	return nil
+}
+
+// trimTrailingPunctuation drops what ends a sentence rather than a url, closing
+// parentheses are kept when they are balanced
+func trimTrailingPunctuation(link string) string {
+	for {
+		trimmed := strings.TrimRight(link, ".,;:!?")
+		if strings.HasSuffix(trimmed, ")") && strings.Count(trimmed, "(") < strings.Count(trimmed, ")") {
+			trimmed = strings.TrimSuffix(trimmed, ")")
+		}
+		if trimmed == link {
+			return link
+		}
+		link = trimmed
+	}
+}
@@ -0,0 +1,209 @@
+// Package config holds the options of a crawl and reads them from files, profiles and the environment
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnknownKey should be used when a key is not an option
+	ErrUnknownKey = errors.New("unknown key")
+	// ErrInvalidValue should be used when a value does not fit its option
+	ErrInvalidValue = errors.New("invalid value")
+	// ErrConflictingKeys should be used when options cannot be used together
+	ErrConflictingKeys = errors.New("conflicting keys")
+)
+
+// Config holds every option of a crawl, keys are the names of the get flags
+type Config struct {
+	Analysis          bool          `config:"analysis"`
+	AnalysisSitemap   string        `config:"analysis-sitemap"`
+	Backoff           time.Duration `config:"backoff"`
+	BackoffMultiplier int           `config:"backoff-multiplier"`
+	EventsOutput      string        `config:"events-output"`
+	Format            string        `config:"format"`
+	GracePeriod       time.Duration `config:"grace-period"`
+	IgnoreScheme      bool          `config:"ignore-scheme"`
+	MetricsAddr       string        `config:"metrics-addr"`
+	Output            string        `config:"output"`
+	Progress          bool          `config:"progress"`
+	Retries           int           `config:"retries"`
+	ScrapeRules       string        `config:"scrape-rules"`
+	SeedsFile         string        `config:"seeds-file"`
+	SitemapBaseURL    string        `config:"sitemap-base-url"`
+	Sorted            bool          `config:"sorted"`
+	Stats             bool          `config:"stats"`
+	Timeout           time.Duration `config:"timeout"`
+	Verbose           bool          `config:"verbose"`
+	WARC              string        `config:"warc"`
+	Webhook           string        `config:"webhook"`
+	WebhookBatchSize  int           `config:"webhook-batch-size"`
+	WebhookFlush      time.Duration `config:"webhook-flush-interval"`
+	WebhookMinStatus  int           `config:"webhook-min-status"`
+	WebhookRetries    int           `config:"webhook-retries"`
+	WebhookStages     string        `config:"webhook-stages"`
+	Workers           int           `config:"workers"`
+}
+
+// Default creates a Config with the defaults of the get flags
+func Default() Config {
+	return Config{
+		Backoff:           500 * time.Millisecond,
+		BackoffMultiplier: 2,
+		Format:            "json",
+		GracePeriod:       5 * time.Second,
+		Retries:           1,
+		Timeout:           10 * time.Second,
+		WebhookBatchSize:  50,
+		WebhookFlush:      time.Second,
+		WebhookRetries:    3,
+		Workers:           3,
+	}
+}
+
+// Keys lists every option, sorted
+func Keys() []string {
+	keys := []string{}
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		keys = append(keys, t.Field(i).Tag.Get("config"))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Set sets an option, values may have their own type or be strings, as in environment variables
+func (c *Config) Set(key string, value interface{}) error {
+	field, ok := c.field(key)
+	if !ok {
+		return fmt.Errorf("%w [%s]", ErrUnknownKey, key)
+	}
+
+	invalid := func(expected string) error {
+		return fmt.Errorf("%w for [%s]: expected %s, got %v", ErrInvalidValue, key, expected, value)
+	}
+
+	switch field.Interface().(type) {
+	case time.Duration:
+		s, ok := value.(string)
+		if !ok {
+			return invalid("a duration like 500ms or 10s")
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return invalid("a duration like 500ms or 10s")
+		}
+		field.SetInt(int64(d))
+	case bool:
+		switch v := value.(type) {
+		case bool:
+			field.SetBool(v)
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return invalid("true or false")
+			}
+			field.SetBool(b)
+		default:
+			return invalid("true or false")
+		}
+	case int:
+		switch v := value.(type) {
+		case int:
+			field.SetInt(int64(v))
+		case int64:
+			field.SetInt(v)
+		case float64:
+			if v != math.Trunc(v) {
+				return invalid("an integer")
+			}
+			field.SetInt(int64(v))
+		case string:
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return invalid("an integer")
+			}
+			field.SetInt(int64(n))
+		default:
+			return invalid("an integer")
+		}
+	case string:
+		s, ok := value.(string)
+		if !ok {
+			return invalid("a string")
+		}
+		field.SetString(s)
+	}
+	return nil
+}
+
+// Apply sets the options in key order, keys for which skip is true are left as they are
+func (c *Config) Apply(values map[string]interface{}, skip func(key string) bool) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if skip != nil && skip(key) {
+			continue
+		}
+		if err := c.Set(key, values[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamFormats are the formats sorted applies to
+var streamFormats = map[string]bool{"csv": true, "csv-edges": true, "ndjson": true}
+
+// Validate checks that no options contradict each other
+func (c Config) Validate() error {
+	conflicts := []string{}
+	if c.Sorted && !streamFormats[c.Format] {
+		conflicts = append(conflicts, fmt.Sprintf("sorted only applies to the csv, csv-edges and ndjson formats, not %s", c.Format))
+	}
+	if c.AnalysisSitemap != "" && !c.Analysis {
+		conflicts = append(conflicts, "analysis-sitemap requires analysis")
+	}
+	if c.Analysis && c.Format == "sitemap" {
+		conflicts = append(conflicts, "analysis cannot be written in the sitemap format")
+	}
+	if c.SitemapBaseURL != "" && c.Format != "sitemap" {
+		conflicts = append(conflicts, fmt.Sprintf("sitemap-base-url only applies to the sitemap format, not %s", c.Format))
+	}
+	if c.EventsOutput == "-" && c.Output == "" {
+		conflicts = append(conflicts, "events-output and output cannot both be stdout")
+	}
+	if c.EventsOutput != "" && c.EventsOutput == c.Output {
+		conflicts = append(conflicts, "events-output and output cannot be the same file")
+	}
+	if c.Webhook == "" && (c.WebhookStages != "" || c.WebhookMinStatus != 0) {
+		conflicts = append(conflicts, "webhook-stages and webhook-min-status require webhook")
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s", ErrConflictingKeys, strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
+// field finds the field of an option
+func (c *Config) field(key string) (reflect.Value, bool) {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("config") == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,128 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/config"
+)
+
+func TestConfig_Set(t *testing.T) {
+	type testCase struct {
+		testName    string
+		key         string
+		value       interface{}
+		expected    func(*config.Config)
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{testName: "int", key: "workers", value: 7, expected: func(c *config.Config) { c.Workers = 7 }},
+		{testName: "int_from_toml", key: "workers", value: int64(7), expected: func(c *config.Config) { c.Workers = 7 }},
+		{testName: "int_from_json", key: "workers", value: float64(7), expected: func(c *config.Config) { c.Workers = 7 }},
+		{testName: "int_from_env", key: "workers", value: "7", expected: func(c *config.Config) { c.Workers = 7 }},
+		{testName: "fractional_int", key: "workers", value: 7.5, expectedErr: config.ErrInvalidValue},
+		{testName: "bool", key: "stats", value: true, expected: func(c *config.Config) { c.Stats = true }},
+		{testName: "bool_from_env", key: "progress", value: "true", expected: func(c *config.Config) { c.Progress = true }},
+		{testName: "invalid_bool", key: "stats", value: "sometimes", expectedErr: config.ErrInvalidValue},
+		{testName: "duration", key: "grace-period", value: "1m30s", expected: func(c *config.Config) { c.GracePeriod = 90 * time.Second }},
+		{testName: "duration_without_unit", key: "timeout", value: 10, expectedErr: config.ErrInvalidValue},
+		{testName: "string", key: "format", value: "csv", expected: func(c *config.Config) { c.Format = "csv" }},
+		{testName: "string_from_number", key: "output", value: 1, expectedErr: config.ErrInvalidValue},
+		{testName: "unknown_key", key: "colour", value: "red", expectedErr: config.ErrUnknownKey},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			actual := config.Default()
+			err := actual.Set(tc.key, tc.value)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected %v, got %v", tc.expectedErr, err)
+			}
+
+			expected := config.Default()
+			if tc.expected != nil {
+				tc.expected(&expected)
+			}
+			if diff := cmp.Diff(expected, actual); diff != "" {
+				t.Errorf("unexpected config (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfig_Apply(t *testing.T) {
+	actual := config.Default()
+	values := map[string]interface{}{"workers": 5, "retries": 4}
+	err := actual.Apply(values, func(key string) bool { return key == "retries" })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := config.Default()
+	expected.Workers = 5
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected config (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	type testCase struct {
+		testName    string
+		change      func(*config.Config)
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{testName: "defaults", change: func(c *config.Config) {}},
+		{testName: "sorted_csv", change: func(c *config.Config) { c.Sorted, c.Format = true, "csv" }},
+		{testName: "sorted_json", change: func(c *config.Config) { c.Sorted = true }, expectedErr: config.ErrConflictingKeys},
+		{testName: "analysis_sitemap_without_analysis", change: func(c *config.Config) { c.AnalysisSitemap = "sitemap.xml" }, expectedErr: config.ErrConflictingKeys},
+		{testName: "analysis_in_sitemap", change: func(c *config.Config) { c.Analysis, c.Format = true, "sitemap" }, expectedErr: config.ErrConflictingKeys},
+		{testName: "sitemap_base_url_in_json", change: func(c *config.Config) { c.SitemapBaseURL = "https://domain.com" }, expectedErr: config.ErrConflictingKeys},
+		{testName: "events_and_output_on_stdout", change: func(c *config.Config) { c.EventsOutput = "-" }, expectedErr: config.ErrConflictingKeys},
+		{testName: "events_on_stdout_output_to_file", change: func(c *config.Config) { c.EventsOutput, c.Output = "-", "out.json" }},
+		{testName: "events_and_output_in_same_file", change: func(c *config.Config) { c.EventsOutput, c.Output = "out.json", "out.json" }, expectedErr: config.ErrConflictingKeys},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			c := config.Default()
+			tc.change(&c)
+			if err := c.Validate(); !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeKey(t *testing.T) {
+	for key, expected := range map[string]string{
+		"grace-period":   "grace-period",
+		"grace_period":   "grace-period",
+		"gracePeriod":    "grace-period",
+		"GRACE_PERIOD":   "grace-period",
+		"sitemapBaseURL": "sitemap-base-url",
+		"WARC":           "warc",
+	} {
+		if actual := config.NormalizeKey(key); actual != expected {
+			t.Errorf("expected %s for %s, got %s", expected, key, actual)
+		}
+	}
+}
+
+func TestEnv(t *testing.T) {
+	environ := []string{
+		"HOME=/root",
+		"WEBCRAWLER_WORKERS=4",
+		"WEBCRAWLER_GRACE_PERIOD=1s",
+		"WEBCRAWLER_CONFIG=crawl.yaml",
+		"WEBCRAWLER_PROFILE=polite",
+	}
+	expected := map[string]interface{}{"workers": "4", "grace-period": "1s"}
+	if diff := cmp.Diff(expected, config.Env(environ)); diff != "" {
+		t.Errorf("unexpected values (-expected +actual):\n%s", diff)
+	}
+}
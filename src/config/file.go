@@ -0,0 +1,244 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix starts the environment variables overriding options, like WEBCRAWLER_GRACE_PERIOD
+const EnvPrefix = "WEBCRAWLER_"
+
+var (
+	// ErrUnknownFormat should be used when a config file is not yaml, toml or json
+	ErrUnknownFormat = errors.New("unknown config format")
+	// ErrUnknownProfile should be used when a profile is neither built in nor in the config file
+	ErrUnknownProfile = errors.New("unknown profile")
+)
+
+// Profiles are the built in profiles, a config file can override their options
+var Profiles = map[string]map[string]interface{}{
+	"polite": {
+		"workers":            1,
+		"retries":            3,
+		"backoff":            "2s",
+		"backoff-multiplier": 2,
+		"timeout":            "10m",
+	},
+	"audit": {
+		"analysis": true,
+		"stats":    true,
+		"format":   "json-formatted",
+		"timeout":  "5m",
+	},
+}
+
+// File is a config file, its options and the options of its profiles
+type File struct {
+	Name     string
+	Values   map[string]interface{}
+	Profiles map[string]map[string]interface{}
+	// duplicates lists the keys written more than once with different spellings, like grace-period and grace_period
+	duplicates []string
+}
+
+// ReadFile reads a config file in yaml, toml or json, by its extension
+func ReadFile(name string) (*File, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("%w [%s], it can be .yaml, .yml, .toml or .json", ErrUnknownFormat, filepath.Ext(name))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read config [%s]: %w", name, err)
+	}
+
+	f := &File{Name: name, Profiles: map[string]map[string]interface{}{}}
+	f.Values = f.normalize("", raw)
+	if profiles, ok := f.Values["profiles"]; ok {
+		delete(f.Values, "profiles")
+		sections, ok := profiles.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w for [profiles]: expected a map of profiles", ErrInvalidValue)
+		}
+		for name, section := range sections {
+			values, ok := section.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%w for profile [%s]: expected a map of options", ErrInvalidValue, name)
+			}
+			f.Profiles[name] = f.normalize(fmt.Sprintf("profiles.%s.", name), values)
+		}
+	}
+	return f, nil
+}
+
+// ValuesFor merges the options of the file with the ones of a profile, an empty profile takes only the file
+func (f *File) ValuesFor(profile string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for key, value := range f.Values {
+		values[key] = value
+	}
+	if profile == "" {
+		return values, nil
+	}
+
+	builtin, isBuiltin := Profiles[profile]
+	custom, isCustom := f.Profiles[profile]
+	if !isBuiltin && !isCustom {
+		return nil, fmt.Errorf("%w [%s], it can be %s", ErrUnknownProfile, profile, strings.Join(f.ProfileNames(), ", "))
+	}
+	for key, value := range builtin {
+		values[key] = value
+	}
+	for key, value := range custom {
+		values[key] = value
+	}
+	return values, nil
+}
+
+// ProfileNames lists the built in profiles and the ones of the file, sorted
+func (f *File) ProfileNames() []string {
+	names := []string{}
+	for name := range Profiles {
+		names = append(names, name)
+	}
+	for name := range f.Profiles {
+		if _, ok := Profiles[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check lists every problem of the file: unknown keys, keys written twice, invalid values and
+// options contradicting each other, for the file alone and along with each of its profiles
+func (f *File) Check() []error {
+	problems := []error{}
+	for _, key := range f.duplicates {
+		problems = append(problems, fmt.Errorf("%w: [%s] is given more than once", ErrConflictingKeys, key))
+	}
+
+	sections := map[string]map[string]interface{}{"": f.Values}
+	for name, values := range f.Profiles {
+		sections[name] = values
+	}
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	baseConflict := ""
+	for _, name := range names {
+		prefix := ""
+		if name != "" {
+			prefix = fmt.Sprintf("profile [%s]: ", name)
+		}
+
+		// profiles are checked the way they are used, on top of the file options, whose
+		// problems are only listed once
+		c := Default()
+		set := func(values map[string]interface{}, report bool) {
+			for _, key := range sortedKeys(values) {
+				if err := c.Set(key, values[key]); err != nil && report {
+					problems = append(problems, fmt.Errorf("%s%w", prefix, err))
+				}
+			}
+		}
+		if name != "" {
+			set(f.Values, false)
+			set(Profiles[name], false)
+		}
+		set(sections[name], true)
+
+		err := c.Validate()
+		if name == "" && err != nil {
+			baseConflict = err.Error()
+		}
+		// conflicts inherited from the file options were already listed
+		if err != nil && (name == "" || err.Error() != baseConflict) {
+			problems = append(problems, fmt.Errorf("%s%w", prefix, err))
+		}
+	}
+	return problems
+}
+
+// Env takes the options set in environment variables, given as KEY=value like os.Environ,
+// the variables for the config file and the profile are left out
+func Env(environ []string) map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, variable := range environ {
+		name, value, ok := strings.Cut(variable, "=")
+		if !ok || !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+		key := NormalizeKey(strings.TrimPrefix(name, EnvPrefix))
+		if key == "config" || key == "profile" {
+			continue
+		}
+		values[key] = value
+	}
+	return values
+}
+
+// NormalizeKey turns keys written as snake_case, camelCase or in upper case into the flag names
+func NormalizeKey(key string) string {
+	var b strings.Builder
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == ' ':
+			b.WriteRune('-')
+		case unicode.IsUpper(r):
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+				b.WriteRune('-')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// normalize normalizes the keys of a section, recording the ones written more than once
+func (f *File) normalize(prefix string, raw map[string]interface{}) map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, key := range sortedKeys(raw) {
+		normalized := NormalizeKey(key)
+		if _, ok := values[normalized]; ok {
+			f.duplicates = append(f.duplicates, prefix+normalized)
+		}
+		values[normalized] = raw[key]
+	}
+	return values
+}
+
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,185 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/config"
+)
+
+func writeFile(t *testing.T, name string, data string) string {
+	name = filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(name, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestReadFile(t *testing.T) {
+	type testCase struct {
+		testName    string
+		name        string
+		data        string
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{
+			testName: "yaml",
+			name:     "crawl.yaml",
+			data:     "workers: 5\ngrace_period: 1s\nprofiles:\n  fast:\n    workers: 20\n",
+		},
+		{
+			testName: "toml",
+			name:     "crawl.toml",
+			data:     "workers = 5\ngracePeriod = \"1s\"\n[profiles.fast]\nworkers = 20\n",
+		},
+		{
+			testName: "json",
+			name:     "crawl.json",
+			data:     `{"workers": 5, "grace-period": "1s", "profiles": {"fast": {"workers": 20}}}`,
+		},
+		{
+			testName:    "unknown_format",
+			name:        "crawl.ini",
+			data:        "workers=5",
+			expectedErr: config.ErrUnknownFormat,
+		},
+		{
+			testName:    "profiles_not_a_map",
+			name:        "crawl.json",
+			data:        `{"profiles": ["fast"]}`,
+			expectedErr: config.ErrInvalidValue,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			f, err := config.ReadFile(writeFile(t, tc.name, tc.data))
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected %v, got %v", tc.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			values, err := f.ValuesFor("fast")
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := config.Default()
+			if err := c.Apply(values, nil); err != nil {
+				t.Fatal(err)
+			}
+			if c.Workers != 20 || c.GracePeriod.String() != "1s" {
+				t.Errorf("expected 20 workers and 1s of grace period, got %d and %v", c.Workers, c.GracePeriod)
+			}
+		})
+	}
+}
+
+func TestFile_ValuesFor(t *testing.T) {
+	f, err := config.ReadFile(writeFile(t, "crawl.yaml", "workers: 5\nformat: csv\nprofiles:\n  polite:\n    retries: 10\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		testName    string
+		profile     string
+		expected    map[string]interface{}
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{
+			testName: "no_profile",
+			profile:  "",
+			expected: map[string]interface{}{"workers": 5, "format": "csv"},
+		},
+		{
+			testName: "builtin_profile_overridden_by_file",
+			profile:  "polite",
+			expected: map[string]interface{}{"workers": 1, "format": "csv", "retries": 10, "backoff": "2s", "backoff-multiplier": 2, "timeout": "10m"},
+		},
+		{
+			testName: "builtin_profile",
+			profile:  "audit",
+			expected: map[string]interface{}{"workers": 5, "format": "json-formatted", "analysis": true, "stats": true, "timeout": "5m"},
+		},
+		{
+			testName:    "unknown_profile",
+			profile:     "fast",
+			expectedErr: config.ErrUnknownProfile,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			actual, err := f.ValuesFor(tc.profile)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected %v, got %v", tc.expectedErr, err)
+			}
+			if err == nil {
+				if diff := cmp.Diff(tc.expected, actual); diff != "" {
+					t.Errorf("unexpected values (-expected +actual):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestFile_Check(t *testing.T) {
+	f, err := config.ReadFile(writeFile(t, "crawl.yaml", `
+workers: 2
+grace_period: 1s
+gracePeriod: 2s
+colour: red
+sorted: true
+profiles:
+  fast:
+    format: csv
+  broken:
+    retries: many
+  noisy:
+    events-output: "-"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := []string{}
+	for _, problem := range f.Check() {
+		actual = append(actual, problem.Error())
+	}
+	expected := []string{
+		"conflicting keys: [grace-period] is given more than once",
+		"unknown key [colour]",
+		"conflicting keys: sorted only applies to the csv, csv-edges and ndjson formats, not json",
+		"profile [broken]: invalid value for [retries]: expected an integer, got many",
+		"profile [noisy]: conflicting keys: sorted only applies to the csv, csv-edges and ndjson formats, not json; events-output and output cannot both be stdout",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected problems (-expected +actual):\n%s", diff)
+	}
+
+	valid, err := config.ReadFile(writeFile(t, "crawl.toml", "format = \"ndjson\"\nsorted = true\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problems := valid.Check(); len(problems) != 0 {
+		t.Errorf("expected no problems, got %s", strings.Join(errorStrings(problems), ", "))
+	}
+}
+
+func errorStrings(errs []error) []string {
+	s := []string{}
+	for _, err := range errs {
+		s = append(s, err.Error())
+	}
+	return s
+}
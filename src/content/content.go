@@ -2,7 +2,9 @@ package content
 
 import (
 	"crypto/sha256"
+	"net/http"
 	"net/url"
+	"time"
 
 	"golang.org/x/exp/maps"
 )
@@ -13,10 +15,29 @@ type Content struct {
 	Body        []byte
 	BodyHash    [32]byte
 	Children    map[string]struct{}
+	Elements    map[string]string
 	ContentType string
+	StatusCode  int
+	Header      http.Header
+	Canonical   string
+	Metadata    *Metadata
+	Structured  []*StructuredItem
+	Scraped     map[string][]string
+	Warnings    []string
+	Redirects   []string
+	Hops        []Hop
+	Location    string
+	Elapsed     time.Duration
 	*url.URL
 }
 
+// Hop is a redirect response followed to reach a content, the one of each url in Redirects
+type Hop struct {
+	URL        string
+	StatusCode int
+	Header     http.Header
+}
+
 // NewContent generates a content object for a URL
 func NewContent(address string) (Content, error) {
 	url, err := url.Parse(address)
@@ -34,7 +55,19 @@ func NewContent(address string) (Content, error) {
 		[]byte{},
 		[32]byte{},
 		map[string]struct{}{},
+		map[string]string{},
+		"",
+		0,
+		http.Header{},
 		"",
+		nil,
+		[]*StructuredItem{},
+		map[string][]string{},
+		[]string{},
+		[]string{},
+		nil,
+		"",
+		0,
 		url,
 	}, nil
 }
@@ -55,6 +88,48 @@ func (c Content) GetChildrenList() []string {
 	return maps.Keys(c.Children)
 }
 
+// AddChild adds a link found in the body along with the element it was found in, the first element is kept
+func (c *Content) AddChild(address string, element string) {
+	if c.Children == nil {
+		c.Children = map[string]struct{}{}
+	}
+	if c.Elements == nil {
+		c.Elements = map[string]string{}
+	}
+	c.Children[address] = struct{}{}
+	if _, ok := c.Elements[address]; !ok {
+		c.Elements[address] = element
+	}
+}
+
+// AddScraped appends values to a scraped field
+func (c *Content) AddScraped(name string, values ...string) {
+	if c.Scraped == nil {
+		c.Scraped = map[string][]string{}
+	}
+	c.Scraped[name] = append(c.Scraped[name], values...)
+}
+
+// AddWarning records a problem found in the content that did not stop it from being processed
+func (c *Content) AddWarning(warning string) {
+	c.Warnings = append(c.Warnings, warning)
+}
+
+// IsCanonical informs if the content does not point to another URL as its canonical version
+func (c Content) IsCanonical() bool {
+	return c.Canonical == "" || c.Canonical == c.Address
+}
+
+// Base returns the url relative links in the body are resolved against, the one of the final response when redirected
+func (c Content) Base() *url.URL {
+	if c.Location != "" {
+		if location, err := url.Parse(c.Location); err == nil {
+			return location
+		}
+	}
+	return c.URL
+}
+
 // CreateChecksum creates a checksum for the body content
 func (c *Content) CreateChecksum() {
 	c.BodyHash = sha256.Sum256(c.Body)
@@ -156,3 +156,45 @@ func TestContent_CreateChecksum(t *testing.T) {
 		})
 	}
 }
+
+func TestContent_IsCanonical(t *testing.T) {
+	c, err := content.NewContent("http://valid-url.com/path?page=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.IsCanonical() {
+		t.Errorf("content without canonical link should be canonical")
+	}
+
+	c.Canonical = c.Address
+	if !c.IsCanonical() {
+		t.Errorf("content pointing to itself should be canonical")
+	}
+
+	c.Canonical = "http://valid-url.com/path"
+	if c.IsCanonical() {
+		t.Errorf("content pointing elsewhere should not be canonical")
+	}
+}
+
+func TestContent_AddChild(t *testing.T) {
+	c, err := content.NewContent("http://valid-url.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.AddChild("child1", "a")
+	c.AddChild("child1", "link")
+	c.AddChild("child2", "img")
+
+	expectedElements := map[string]string{"child1": "a", "child2": "img"}
+	if diff := cmp.Diff(expectedElements, c.Elements); diff != "" {
+		t.Errorf("unexpected elements (-expected +actual):\n%s", diff)
+	}
+
+	less := func(a, b string) bool { return a < b }
+	if diff := cmp.Diff([]string{"child1", "child2"}, c.GetChildrenList(), cmpopts.SortSlices(less)); diff != "" {
+		t.Errorf("unexpected children (-expected +actual):\n%s", diff)
+	}
+}
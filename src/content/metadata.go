@@ -0,0 +1,35 @@
+package content
+
+// Alternate is a version of a page in another language, linked with hreflang
+type Alternate struct {
+	Lang string `json:"lang"`
+	URL  string `json:"url"`
+}
+
+// Metadata bundles what an HTML page tells about itself
+type Metadata struct {
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	H1          []string          `json:"h1"`
+	H2          []string          `json:"h2"`
+	Lang        string            `json:"lang"`
+	Alternates  []Alternate       `json:"alternates"`
+	OpenGraph   map[string]string `json:"openGraph"`
+	Twitter     map[string]string `json:"twitter"`
+	WordCount   int               `json:"wordCount"`
+	Resources   []string          `json:"resources"`
+	MissingAlt  []string          `json:"imagesWithoutAlt"`
+}
+
+// NewMetadata creates an empty Metadata
+func NewMetadata() *Metadata {
+	return &Metadata{
+		H1:         []string{},
+		H2:         []string{},
+		Alternates: []Alternate{},
+		OpenGraph:  map[string]string{},
+		Twitter:    map[string]string{},
+		Resources:  []string{},
+		MissingAlt: []string{},
+	}
+}
@@ -0,0 +1,25 @@
+package content
+
+// StructuredItem is a normalized schema.org item found in a page as JSON-LD, microdata or RDFa,
+// property values are strings, numbers, booleans or nested items
+type StructuredItem struct {
+	Format     string                   `json:"format"`
+	Context    string                   `json:"context,omitempty"`
+	Type       []string                 `json:"type"`
+	ID         string                   `json:"id,omitempty"`
+	Properties map[string][]interface{} `json:"properties"`
+}
+
+// NewStructuredItem creates an empty StructuredItem in a format
+func NewStructuredItem(format string) *StructuredItem {
+	return &StructuredItem{
+		Format:     format,
+		Type:       []string{},
+		Properties: map[string][]interface{}{},
+	}
+}
+
+// AddProperty appends a value to a property
+func (si *StructuredItem) AddProperty(name string, value interface{}) {
+	si.Properties[name] = append(si.Properties[name], value)
+}
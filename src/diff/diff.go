@@ -0,0 +1,278 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/thiagolcmelo/webcrawler/src/report"
+)
+
+// StatusChange is a page that responded with another status, both statuses are known since a
+// status of 0 means it was not recorded and is never compared
+type StatusChange struct {
+	URL string `json:"url"`
+	Old int    `json:"old"`
+	New int    `json:"new"`
+}
+
+// Regression informs if a page that responded well now fails with a 4xx or 5xx status
+func (s StatusChange) Regression() bool {
+	return s.Old > 0 && s.Old < 400 && s.New >= 400
+}
+
+// TextChange is a page whose title or description changed
+type TextChange struct {
+	URL string `json:"url"`
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// Edge is a link from a page to a url
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Counts bundles how many changes of each kind were found
+type Counts struct {
+	Added              int `json:"added"`
+	Removed            int `json:"removed"`
+	StatusChanges      int `json:"statusChanges"`
+	Regressions        int `json:"regressions"`
+	ContentChanges     int `json:"contentChanges"`
+	TitleChanges       int `json:"titleChanges"`
+	DescriptionChanges int `json:"descriptionChanges"`
+	EdgesAdded         int `json:"edgesAdded"`
+	EdgesRemoved       int `json:"edgesRemoved"`
+}
+
+// Diff bundles the changes between two crawls, every list is sorted by url
+type Diff struct {
+	Counts             Counts         `json:"counts"`
+	Added              []string       `json:"added"`
+	Removed            []string       `json:"removed"`
+	StatusChanges      []StatusChange `json:"statusChanges"`
+	ContentChanges     []string       `json:"contentChanges"`
+	TitleChanges       []TextChange   `json:"titleChanges"`
+	DescriptionChanges []TextChange   `json:"descriptionChanges"`
+	EdgesAdded         []Edge         `json:"edgesAdded"`
+	EdgesRemoved       []Edge         `json:"edgesRemoved"`
+}
+
+// Compare finds the changes from an old crawl to a newer one. Pages are matched by url, and the
+// status and content of a page are only compared when both crawls recorded them
+func Compare(old, newer report.Report) Diff {
+	d := Diff{
+		Added:              []string{},
+		Removed:            []string{},
+		StatusChanges:      []StatusChange{},
+		ContentChanges:     []string{},
+		TitleChanges:       []TextChange{},
+		DescriptionChanges: []TextChange{},
+	}
+
+	oldPages := byURL(old.Pages)
+	newPages := byURL(newer.Pages)
+
+	for url := range oldPages {
+		if _, ok := newPages[url]; !ok {
+			d.Removed = append(d.Removed, url)
+		}
+	}
+	for url, n := range newPages {
+		o, ok := oldPages[url]
+		if !ok {
+			d.Added = append(d.Added, url)
+			continue
+		}
+
+		if o.StatusCode != 0 && n.StatusCode != 0 && o.StatusCode != n.StatusCode {
+			d.StatusChanges = append(d.StatusChanges, StatusChange{URL: url, Old: o.StatusCode, New: n.StatusCode})
+		}
+		if o.Hash != "" && n.Hash != "" && o.Hash != n.Hash {
+			d.ContentChanges = append(d.ContentChanges, url)
+		}
+		if oldTitle, newTitle := title(o), title(n); oldTitle != newTitle {
+			d.TitleChanges = append(d.TitleChanges, TextChange{URL: url, Old: oldTitle, New: newTitle})
+		}
+		if oldDescription, newDescription := description(o), description(n); oldDescription != newDescription {
+			d.DescriptionChanges = append(d.DescriptionChanges, TextChange{URL: url, Old: oldDescription, New: newDescription})
+		}
+	}
+
+	oldEdges := edges(old.Pages)
+	newEdges := edges(newer.Pages)
+	d.EdgesAdded = missing(newEdges, oldEdges)
+	d.EdgesRemoved = missing(oldEdges, newEdges)
+
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Strings(d.ContentChanges)
+	sort.Slice(d.StatusChanges, func(i, j int) bool { return d.StatusChanges[i].URL < d.StatusChanges[j].URL })
+	sort.Slice(d.TitleChanges, func(i, j int) bool { return d.TitleChanges[i].URL < d.TitleChanges[j].URL })
+	sort.Slice(d.DescriptionChanges, func(i, j int) bool { return d.DescriptionChanges[i].URL < d.DescriptionChanges[j].URL })
+
+	regressions := 0
+	for _, s := range d.StatusChanges {
+		if s.Regression() {
+			regressions++
+		}
+	}
+	d.Counts = Counts{
+		Added:              len(d.Added),
+		Removed:            len(d.Removed),
+		StatusChanges:      len(d.StatusChanges),
+		Regressions:        regressions,
+		ContentChanges:     len(d.ContentChanges),
+		TitleChanges:       len(d.TitleChanges),
+		DescriptionChanges: len(d.DescriptionChanges),
+		EdgesAdded:         len(d.EdgesAdded),
+		EdgesRemoved:       len(d.EdgesRemoved),
+	}
+	return d
+}
+
+func byURL(pages []report.Page) map[string]report.Page {
+	m := make(map[string]report.Page, len(pages))
+	for _, p := range pages {
+		m[p.URL] = p
+	}
+	return m
+}
+
+func title(p report.Page) string {
+	if p.Metadata == nil {
+		return ""
+	}
+	return p.Metadata.Title
+}
+
+func description(p report.Page) string {
+	if p.Metadata == nil {
+		return ""
+	}
+	return p.Metadata.Description
+}
+
+// edges lists the distinct links between the pages and the urls they link to
+func edges(pages []report.Page) map[Edge]struct{} {
+	m := map[Edge]struct{}{}
+	for from, targets := range report.Links(pages) {
+		for _, to := range targets {
+			if to != from {
+				m[Edge{From: from, To: to}] = struct{}{}
+			}
+		}
+	}
+	return m
+}
+
+// missing lists the edges of a that are not in b, sorted
+func missing(a, b map[Edge]struct{}) []Edge {
+	result := []Edge{}
+	for e := range a {
+		if _, ok := b[e]; !ok {
+			result = append(result, e)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].From != result[j].From {
+			return result[i].From < result[j].From
+		}
+		return result[i].To < result[j].To
+	})
+	return result
+}
+
+// Thresholds bounds how many changes of each kind are tolerated, negative values disable a bound
+type Thresholds struct {
+	Removed        int
+	Regressions    int
+	ContentChanges int
+	EdgesRemoved   int
+}
+
+// NoThresholds tolerates any change
+func NoThresholds() Thresholds {
+	return Thresholds{Removed: -1, Regressions: -1, ContentChanges: -1, EdgesRemoved: -1}
+}
+
+// Violations describes every threshold the diff crosses
+func (d Diff) Violations(t Thresholds) []string {
+	violations := []string{}
+	check := func(name string, count, limit int) {
+		if limit >= 0 && count > limit {
+			violations = append(violations, fmt.Sprintf("%d %s, more than the limit of %d", count, name, limit))
+		}
+	}
+	check("pages removed", d.Counts.Removed, t.Removed)
+	check("pages failing that did not before", d.Counts.Regressions, t.Regressions)
+	check("pages with changed content", d.Counts.ContentChanges, t.ContentChanges)
+	check("links removed", d.Counts.EdgesRemoved, t.EdgesRemoved)
+	return violations
+}
+
+// WriteText writes the diff in a human readable form, counts first
+func (d Diff) WriteText(w io.Writer) error {
+	c := d.Counts
+	lines := []string{
+		fmt.Sprintf("pages: %d added, %d removed, %d status changes (%d regressions), %d content changes, %d title changes, %d description changes",
+			c.Added, c.Removed, c.StatusChanges, c.Regressions, c.ContentChanges, c.TitleChanges, c.DescriptionChanges),
+		fmt.Sprintf("links: %d added, %d removed", c.EdgesAdded, c.EdgesRemoved),
+	}
+	section := func(name string, entries []string) {
+		if len(entries) == 0 {
+			return
+		}
+		lines = append(lines, name+":")
+		for _, entry := range entries {
+			lines = append(lines, "  "+entry)
+		}
+	}
+
+	entries := []string{}
+	for _, url := range d.Added {
+		entries = append(entries, "+ "+url)
+	}
+	for _, url := range d.Removed {
+		entries = append(entries, "- "+url)
+	}
+	section("pages", entries)
+
+	entries = []string{}
+	for _, s := range d.StatusChanges {
+		entries = append(entries, fmt.Sprintf("%s: %d -> %d", s.URL, s.Old, s.New))
+	}
+	section("status", entries)
+
+	section("content", d.ContentChanges)
+
+	entries = []string{}
+	for _, t := range d.TitleChanges {
+		entries = append(entries, fmt.Sprintf("%s: %q -> %q", t.URL, t.Old, t.New))
+	}
+	section("title", entries)
+
+	entries = []string{}
+	for _, t := range d.DescriptionChanges {
+		entries = append(entries, fmt.Sprintf("%s: %q -> %q", t.URL, t.Old, t.New))
+	}
+	section("description", entries)
+
+	entries = []string{}
+	for _, e := range d.EdgesAdded {
+		entries = append(entries, fmt.Sprintf("+ %s -> %s", e.From, e.To))
+	}
+	for _, e := range d.EdgesRemoved {
+		entries = append(entries, fmt.Sprintf("- %s -> %s", e.From, e.To))
+	}
+	section("links", entries)
+
+	for _, line := range lines {
+		if _, err := w.Write([]byte(line + "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
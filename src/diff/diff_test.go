@@ -0,0 +1,182 @@
+package diff_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/diff"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+)
+
+func page(url string, status int, hash string, title string, children ...string) report.Page {
+	return report.Page{
+		URL:        url,
+		StatusCode: status,
+		Hash:       hash,
+		Children:   children,
+		Metadata:   &content.Metadata{Title: title},
+	}
+}
+
+func sampleReports() (report.Report, report.Report) {
+	old := report.Report{Pages: []report.Page{
+		page("http://domain.com/", 200, "a", "Home", "http://domain.com/about", "http://domain.com/old"),
+		page("http://domain.com/about", 200, "b", "About"),
+		page("http://domain.com/old", 200, "c", "Old"),
+		page("http://domain.com/moved", 200, "", "Moved"),
+	}}
+	newer := report.Report{Pages: []report.Page{
+		page("http://domain.com/", 200, "a", "Home", "http://domain.com/about", "http://domain.com/new"),
+		page("http://domain.com/about", 404, "d", "About us"),
+		page("http://domain.com/new", 200, "e", "New"),
+		page("http://domain.com/moved", 301, "f", "Moved"),
+	}}
+	old.Pages[1].Metadata.Description = "who we are"
+	return old, newer
+}
+
+func TestCompare(t *testing.T) {
+	d := diff.Compare(sampleReports())
+
+	expected := diff.Diff{
+		Counts: diff.Counts{
+			Added:              1,
+			Removed:            1,
+			StatusChanges:      2,
+			Regressions:        1,
+			ContentChanges:     1,
+			TitleChanges:       1,
+			DescriptionChanges: 1,
+			EdgesAdded:         1,
+			EdgesRemoved:       1,
+		},
+		Added:   []string{"http://domain.com/new"},
+		Removed: []string{"http://domain.com/old"},
+		StatusChanges: []diff.StatusChange{
+			{URL: "http://domain.com/about", Old: 200, New: 404},
+			{URL: "http://domain.com/moved", Old: 200, New: 301},
+		},
+		// the old crawl has no checksum for /moved
+		ContentChanges:     []string{"http://domain.com/about"},
+		TitleChanges:       []diff.TextChange{{URL: "http://domain.com/about", Old: "About", New: "About us"}},
+		DescriptionChanges: []diff.TextChange{{URL: "http://domain.com/about", Old: "who we are", New: ""}},
+		EdgesAdded:         []diff.Edge{{From: "http://domain.com/", To: "http://domain.com/new"}},
+		EdgesRemoved:       []diff.Edge{{From: "http://domain.com/", To: "http://domain.com/old"}},
+	}
+	if diff := cmp.Diff(expected, d); diff != "" {
+		t.Errorf("unexpected diff (-expected +actual):\n%s", diff)
+	}
+
+	_, newer := sampleReports()
+	same := diff.Compare(newer, newer)
+	if same.Counts != (diff.Counts{}) {
+		t.Errorf("expected no changes between equal crawls, got %+v", same.Counts)
+	}
+}
+
+func TestCompare_UnknownStatus(t *testing.T) {
+	// pages read from outputs that did not record the status have a status of 0
+	old := report.Report{Pages: []report.Page{
+		page("http://domain.com/", 0, "", "Home"),
+		page("http://domain.com/about", 200, "", "About"),
+	}}
+	newer := report.Report{Pages: []report.Page{
+		page("http://domain.com/", 404, "", "Home"),
+		page("http://domain.com/about", 0, "", "About"),
+	}}
+
+	d := diff.Compare(old, newer)
+	if d.Counts.StatusChanges != 0 || d.Counts.Regressions != 0 {
+		t.Errorf("expected unknown statuses not to be compared, got %+v", d.StatusChanges)
+	}
+}
+
+func TestStatusChange_Regression(t *testing.T) {
+	type testCase struct {
+		testName string
+		change   diff.StatusChange
+		expected bool
+	}
+
+	testCases := []testCase{
+		{testName: "ok_to_not_found", change: diff.StatusChange{Old: 200, New: 404}, expected: true},
+		{testName: "redirect_to_server_error", change: diff.StatusChange{Old: 301, New: 500}, expected: true},
+		{testName: "ok_to_redirect", change: diff.StatusChange{Old: 200, New: 301}, expected: false},
+		{testName: "not_found_to_server_error", change: diff.StatusChange{Old: 404, New: 500}, expected: false},
+		{testName: "not_found_to_ok", change: diff.StatusChange{Old: 404, New: 200}, expected: false},
+		{testName: "unknown_to_not_found", change: diff.StatusChange{Old: 0, New: 404}, expected: false},
+		{testName: "ok_to_unknown", change: diff.StatusChange{Old: 200, New: 0}, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if actual := tc.change.Regression(); actual != tc.expected {
+				t.Errorf("expected regression %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDiff_Violations(t *testing.T) {
+	type testCase struct {
+		testName   string
+		thresholds diff.Thresholds
+		expected   []string
+	}
+
+	testCases := []testCase{
+		{
+			testName:   "no_thresholds",
+			thresholds: diff.NoThresholds(),
+			expected:   []string{},
+		},
+		{
+			testName:   "within_thresholds",
+			thresholds: diff.Thresholds{Removed: 1, Regressions: 1, ContentChanges: 1, EdgesRemoved: 1},
+			expected:   []string{},
+		},
+		{
+			testName:   "crossed_thresholds",
+			thresholds: diff.Thresholds{Removed: 0, Regressions: 0, ContentChanges: -1, EdgesRemoved: 5},
+			expected: []string{
+				"1 pages removed, more than the limit of 0",
+				"1 pages failing that did not before, more than the limit of 0",
+			},
+		},
+	}
+
+	d := diff.Compare(sampleReports())
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, d.Violations(tc.thresholds)); diff != "" {
+				t.Errorf("unexpected violations (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiff_WriteText(t *testing.T) {
+	d := diff.Compare(sampleReports())
+
+	var buf bytes.Buffer
+	if err := d.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"pages: 1 added, 1 removed, 2 status changes (1 regressions), 1 content changes, 1 title changes, 1 description changes",
+		"links: 1 added, 1 removed",
+		"  + http://domain.com/new",
+		"  - http://domain.com/old",
+		"  http://domain.com/about: 200 -> 404",
+		`  http://domain.com/about: "About" -> "About us"`,
+		"  - http://domain.com/ -> http://domain.com/old",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, buf.String())
+		}
+	}
+}
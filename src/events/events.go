@@ -19,6 +19,14 @@ const (
 	Store
 	// Dispatch is used for a URL that was dispatched
 	Dispatch
+	// Duplicate is used for a URL whose content was already seen
+	Duplicate
+	// Error is used for a URL whose processing stopped, the reason is kept
+	Error
+	// Response is used for a URL that got an HTTP response, the status code is kept
+	Response
+	// SchemeRetry is used for a URL without scheme that is retried with one
+	SchemeRetry
 )
 
 func (et EventType) String() string {
@@ -33,16 +41,36 @@ func (et EventType) String() string {
 		return "store"
 	case Dispatch:
 		return "dispatch"
+	case Duplicate:
+		return "duplicate"
+	case Error:
+		return "error"
+	case Response:
+		return "response"
+	case SchemeRetry:
+		return "scheme-retry"
 	default:
 		return fmt.Sprintf("%d", int(et))
 	}
 }
 
+// ParseEventType is the inverse of EventType.String
+func ParseEventType(s string) (EventType, error) {
+	for et := Discovery; et <= SchemeRetry; et++ {
+		if et.String() == s {
+			return et, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown event type [%s]", s)
+}
+
 // EventInstance bundles information about an event
 type EventInstance struct {
 	EventType EventType
 	Success   bool
 	Value     int
+	Reason    string
+	Size      int
 	Time      time.Time
 }
 
@@ -53,6 +81,10 @@ type Events interface {
 	LogParseEvent(string, bool, int)
 	LogStoreEvent(string, bool)
 	LogDispatchEvent(string, bool, int)
+	LogDuplicateEvent(string)
+	LogErrorEvent(string, string)
+	LogResponseEvent(string, int, int)
+	LogSchemeRetryEvent(string)
 	GetReport() map[string][]EventInstance
 	IsAlreadyDiscovered(string) bool
 }
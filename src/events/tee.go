@@ -0,0 +1,92 @@
+package events
+
+// Tee fans out every event logged to several Events, queries are answered by the first one
+type Tee struct {
+	primary Events
+	others  []Events
+}
+
+// NewTee is a factory for Tee, primary is the one answering GetReport and IsAlreadyDiscovered
+func NewTee(primary Events, others ...Events) *Tee {
+	return &Tee{
+		primary: primary,
+		others:  others,
+	}
+}
+
+func (t *Tee) all() []Events {
+	return append([]Events{t.primary}, t.others...)
+}
+
+// LogDiscoveryEvent logs a Discovery event to all Events
+func (t *Tee) LogDiscoveryEvent(address string, success bool) {
+	for _, e := range t.all() {
+		e.LogDiscoveryEvent(address, success)
+	}
+}
+
+// LogDownloadEvent logs a Download event to all Events
+func (t *Tee) LogDownloadEvent(address string, success bool) {
+	for _, e := range t.all() {
+		e.LogDownloadEvent(address, success)
+	}
+}
+
+// LogParseEvent logs a Parse event to all Events
+func (t *Tee) LogParseEvent(address string, success bool, children int) {
+	for _, e := range t.all() {
+		e.LogParseEvent(address, success, children)
+	}
+}
+
+// LogStoreEvent logs a Store event to all Events
+func (t *Tee) LogStoreEvent(address string, success bool) {
+	for _, e := range t.all() {
+		e.LogStoreEvent(address, success)
+	}
+}
+
+// LogDispatchEvent logs a Dispatch event to all Events
+func (t *Tee) LogDispatchEvent(address string, success bool, children int) {
+	for _, e := range t.all() {
+		e.LogDispatchEvent(address, success, children)
+	}
+}
+
+// LogDuplicateEvent logs a Duplicate event to all Events
+func (t *Tee) LogDuplicateEvent(address string) {
+	for _, e := range t.all() {
+		e.LogDuplicateEvent(address)
+	}
+}
+
+// LogErrorEvent logs an Error event to all Events
+func (t *Tee) LogErrorEvent(address string, reason string) {
+	for _, e := range t.all() {
+		e.LogErrorEvent(address, reason)
+	}
+}
+
+// LogResponseEvent logs a Response event to all Events
+func (t *Tee) LogResponseEvent(address string, statusCode int, size int) {
+	for _, e := range t.all() {
+		e.LogResponseEvent(address, statusCode, size)
+	}
+}
+
+// LogSchemeRetryEvent logs a SchemeRetry event to all Events
+func (t *Tee) LogSchemeRetryEvent(address string) {
+	for _, e := range t.all() {
+		e.LogSchemeRetryEvent(address)
+	}
+}
+
+// GetReport returns the report of the primary Events
+func (t *Tee) GetReport() map[string][]EventInstance {
+	return t.primary.GetReport()
+}
+
+// IsAlreadyDiscovered asks the primary Events
+func (t *Tee) IsAlreadyDiscovered(address string) bool {
+	return t.primary.IsAlreadyDiscovered(address)
+}
@@ -0,0 +1,52 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/thiagolcmelo/webcrawler/src/events"
+	"github.com/thiagolcmelo/webcrawler/src/memory"
+)
+
+func TestTee(t *testing.T) {
+	primary := memory.NewEvents()
+	other := memory.NewEvents()
+	tee := events.NewTee(primary, other)
+
+	tee.LogDiscoveryEvent("url1", true)
+	tee.LogResponseEvent("url1", 200, 10)
+	tee.LogDownloadEvent("url1", true)
+	tee.LogParseEvent("url1", true, 1)
+	tee.LogStoreEvent("url1", true)
+	tee.LogDispatchEvent("url1", true, 1)
+	tee.LogDuplicateEvent("url2")
+	tee.LogErrorEvent("url2", "repeated content")
+	tee.LogSchemeRetryEvent("url2")
+
+	for _, me := range []*memory.Events{primary, other} {
+		report := me.GetReport()
+		if len(report["url1"]) != 6 || len(report["url2"]) != 3 {
+			t.Errorf("events were not fanned out %#v", report)
+		}
+	}
+
+	other.LogDiscoveryEvent("url3", true)
+	if !tee.IsAlreadyDiscovered("url3") {
+		t.Errorf("queries should be answered by the primary events only")
+	}
+	if tee.IsAlreadyDiscovered("url1") {
+		t.Errorf("url1 was discovered through the tee")
+	}
+}
+
+func TestParseEventType(t *testing.T) {
+	for et := events.Discovery; et <= events.Response; et++ {
+		actual, err := events.ParseEventType(et.String())
+		if err != nil || actual != et {
+			t.Errorf("expected %v, got %v (%v)", et, actual, err)
+		}
+	}
+
+	if _, err := events.ParseEventType("unknown"); err == nil {
+		t.Errorf("expected error for unknown event type")
+	}
+}
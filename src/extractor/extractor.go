@@ -0,0 +1,8 @@
+package extractor
+
+import "github.com/thiagolcmelo/webcrawler/src/content"
+
+// Extractor defines an interface for extracting metadata from downloaded content
+type Extractor interface {
+	Extract(*content.Content) error
+}
@@ -0,0 +1,144 @@
+// Package hooks calls Go callbacks and webhooks on the events of the stages of a crawl
+package hooks
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thiagolcmelo/webcrawler/src/content"
+)
+
+// ErrUnknownStage should be used when a stage is not one of the stages hooks are called on
+var ErrUnknownStage = errors.New("unknown stage")
+
+// Stage is a point of the pipeline of the orchestrator hooks are called on
+type Stage string
+
+const (
+	// OnDiscover is called for every url discovered for the first time
+	OnDiscover Stage = "on-discover"
+	// AfterDownload is called for every download attempted, successful or not
+	AfterDownload Stage = "after-download"
+	// AfterParse is called for every url parsed, with the number of children found
+	AfterParse Stage = "after-parse"
+	// OnStore is called for every page stored
+	OnStore Stage = "on-store"
+	// OnError is called for every url whose processing failed, skipped urls like the ones
+	// already seen are not errors
+	OnError Stage = "on-error"
+)
+
+// Stages lists every stage, in the order of the pipeline
+func Stages() []Stage {
+	return []Stage{OnDiscover, AfterDownload, AfterParse, OnStore, OnError}
+}
+
+// ParseStages reads a comma separated list of stages, every stage when empty
+func ParseStages(text string) ([]Stage, error) {
+	if strings.TrimSpace(text) == "" {
+		return Stages(), nil
+	}
+
+	stages := []Stage{}
+	for _, name := range strings.Split(text, ",") {
+		stage := Stage(strings.TrimSpace(name))
+		known := false
+		for _, s := range Stages() {
+			known = known || s == stage
+		}
+		if !known {
+			names := []string{}
+			for _, s := range Stages() {
+				names = append(names, string(s))
+			}
+			return nil, fmt.Errorf("%w [%s], it can be %s", ErrUnknownStage, stage, strings.Join(names, ", "))
+		}
+		stages = append(stages, stage)
+	}
+	return stages, nil
+}
+
+// Event is what a hook gets, fields the stage does not know yet are left empty
+type Event struct {
+	Stage       Stage     `json:"stage"`
+	URL         string    `json:"url"`
+	Time        time.Time `json:"time"`
+	StatusCode  int       `json:"statusCode,omitempty"`
+	ContentType string    `json:"contentType,omitempty"`
+	Size        int       `json:"size,omitempty"`
+	Hash        string    `json:"sha256,omitempty"`
+	Title       string    `json:"title,omitempty"`
+	Children    int       `json:"children,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// NewEvent creates the event of a stage for a content, with what is known about it so far
+func NewEvent(stage Stage, c content.Content) Event {
+	e := Event{
+		Stage:       stage,
+		URL:         c.Address,
+		Time:        time.Now().UTC(),
+		StatusCode:  c.StatusCode,
+		ContentType: c.ContentType,
+		Size:        len(c.Body),
+		Children:    len(c.Children),
+	}
+	if c.BodyHash != [32]byte{} {
+		e.Hash = hex.EncodeToString(c.BodyHash[:])
+	}
+	if c.Metadata != nil {
+		e.Title = c.Metadata.Title
+	}
+	return e
+}
+
+// Hook is called with the events of the stages it is added to, from the goroutines processing
+// the urls, so it must be safe for concurrent use and should return quickly
+type Hook func(Event)
+
+// MinStatus wraps a hook so it is only called for events with a status code of at least min,
+// like 500 for server errors
+func MinStatus(min int, hook Hook) Hook {
+	return func(e Event) {
+		if e.StatusCode >= min {
+			hook(e)
+		}
+	}
+}
+
+// Hooks maps every stage to its hooks, the zero value has no hooks
+type Hooks struct {
+	mu    sync.RWMutex
+	hooks map[Stage][]Hook
+}
+
+// Add calls the hook on every event of the stage, after the hooks added before it
+func (h *Hooks) Add(stage Stage, hook Hook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.hooks == nil {
+		h.hooks = map[Stage][]Hook{}
+	}
+	h.hooks[stage] = append(h.hooks[stage], hook)
+}
+
+// Has informs if the stage has hooks, so events nobody listens to are not built
+func (h *Hooks) Has(stage Stage) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.hooks[stage]) > 0
+}
+
+// Fire calls the hooks of the stage of the event
+func (h *Hooks) Fire(e Event) {
+	h.mu.RLock()
+	hooks := h.hooks[e.Stage]
+	h.mu.RUnlock()
+	for _, hook := range hooks {
+		hook(e)
+	}
+}
@@ -0,0 +1,90 @@
+package hooks_test
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/hooks"
+)
+
+func TestParseStages(t *testing.T) {
+	type testCase struct {
+		testName    string
+		text        string
+		expected    []hooks.Stage
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{testName: "every_stage", text: "", expected: hooks.Stages()},
+		{testName: "some_stages", text: "on-error, on-store", expected: []hooks.Stage{hooks.OnError, hooks.OnStore}},
+		{testName: "unknown_stage", text: "on-error,before-download", expectedErr: hooks.ErrUnknownStage},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			actual, err := hooks.ParseStages(tc.text)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected %v, got %v", tc.expectedErr, err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected stages (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHooks_Fire(t *testing.T) {
+	var h hooks.Hooks
+	if h.Has(hooks.OnStore) {
+		t.Errorf("expected no hooks in the zero value")
+	}
+	h.Fire(hooks.Event{Stage: hooks.OnStore})
+
+	fired := []string{}
+	h.Add(hooks.OnStore, func(e hooks.Event) { fired = append(fired, "first "+e.URL) })
+	h.Add(hooks.OnStore, func(e hooks.Event) { fired = append(fired, "second "+e.URL) })
+	h.Add(hooks.OnError, hooks.MinStatus(500, func(e hooks.Event) { fired = append(fired, "error "+e.URL) }))
+
+	h.Fire(hooks.Event{Stage: hooks.OnStore, URL: "http://domain.com/"})
+	h.Fire(hooks.Event{Stage: hooks.OnError, URL: "http://domain.com/missing", StatusCode: 404})
+	h.Fire(hooks.Event{Stage: hooks.OnError, URL: "http://domain.com/broken", StatusCode: 503})
+	h.Fire(hooks.Event{Stage: hooks.AfterParse, URL: "http://domain.com/"})
+
+	expected := []string{"first http://domain.com/", "second http://domain.com/", "error http://domain.com/broken"}
+	if diff := cmp.Diff(expected, fired); diff != "" {
+		t.Errorf("unexpected hooks fired (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	body := []byte("<title>Home</title>")
+	c, err := content.NewContentWithBody("http://domain.com", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.StatusCode = 200
+	c.ContentType = "text/html"
+	c.Metadata = &content.Metadata{Title: "Home"}
+	c.AddChild("http://domain.com/page", "a")
+
+	e := hooks.NewEvent(hooks.OnStore, c)
+	expected := hooks.Event{
+		Stage:       hooks.OnStore,
+		URL:         "http://domain.com/",
+		Time:        e.Time,
+		StatusCode:  200,
+		ContentType: "text/html",
+		Size:        19,
+		Hash:        fmt.Sprintf("%x", sha256.Sum256(body)),
+		Title:       "Home",
+		Children:    1,
+	}
+	if diff := cmp.Diff(expected, e); diff != "" {
+		t.Errorf("unexpected event (-expected +actual):\n%s", diff)
+	}
+}
@@ -0,0 +1,201 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrDeliveryFailed should be used when a webhook could not deliver some of its events
+var ErrDeliveryFailed = errors.New("webhook delivery failed")
+
+// WebhookOptions tells how a webhook batches and retries its events
+type WebhookOptions struct {
+	// BatchSize is the most events posted at once
+	BatchSize int
+	// FlushInterval is the longest an event waits for its batch to fill up
+	FlushInterval time.Duration
+	// Retries is how many times a batch is posted again after failing
+	Retries int
+	// Backoff is the wait before the first retry, it doubles for every other retry
+	Backoff time.Duration
+	// Client posts the batches, a client with a 10 seconds timeout when nil
+	Client *http.Client
+}
+
+// DefaultWebhookOptions posts up to 50 events at once, at least every second, retrying 3 times
+func DefaultWebhookOptions() WebhookOptions {
+	return WebhookOptions{BatchSize: 50, FlushInterval: time.Second, Retries: 3, Backoff: 500 * time.Millisecond}
+}
+
+// Batch is the body of the requests of a webhook
+type Batch struct {
+	Events []Event `json:"events"`
+}
+
+// Webhook posts the events it gets to a url as JSON batches, from its own goroutine so the
+// crawl only waits for it when it falls behind
+type Webhook struct {
+	url     string
+	options WebhookOptions
+	events  chan Event
+	done    chan struct{}
+	// closed is guarded by sendMu, so events sent after Close are dropped
+	sendMu  sync.RWMutex
+	closed  bool
+	mu      sync.Mutex
+	batches int
+	failed  int
+	lastErr error
+}
+
+// NewWebhook creates a Webhook posting to the url, it must be closed to deliver the last batch
+func NewWebhook(url string, options WebhookOptions) *Webhook {
+	defaults := DefaultWebhookOptions()
+	if options.BatchSize < 1 {
+		options.BatchSize = defaults.BatchSize
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = defaults.FlushInterval
+	}
+	if options.Retries < 0 {
+		options.Retries = 0
+	}
+	if options.Backoff <= 0 {
+		options.Backoff = defaults.Backoff
+	}
+	if options.Client == nil {
+		options.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	w := &Webhook{
+		url:     url,
+		options: options,
+		events:  make(chan Event, 4*options.BatchSize),
+		done:    make(chan struct{}),
+	}
+	go w.loop()
+	return w
+}
+
+// Send queues an event to be posted, it is a Hook, events sent after Close are dropped like
+// the ones of urls still in flight when a crawl is not fully drained
+func (w *Webhook) Send(e Event) {
+	w.sendMu.RLock()
+	defer w.sendMu.RUnlock()
+	if w.closed {
+		log.Printf("dropping %s event of url [%s], the webhook is closed", e.Stage, e.URL)
+		return
+	}
+	w.events <- e
+}
+
+// Subscribe sends the events of the stages through add, like Orchestrator.Hook, only the ones
+// with a status code of at least minStatus when it is positive
+func (w *Webhook) Subscribe(add func(Stage, Hook), stages []Stage, minStatus int) {
+	var hook Hook = w.Send
+	if minStatus > 0 {
+		hook = MinStatus(minStatus, hook)
+	}
+	for _, stage := range stages {
+		add(stage, hook)
+	}
+}
+
+// Close posts the events still queued and returns an error when any batch was not delivered
+func (w *Webhook) Close() error {
+	w.sendMu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.events)
+	}
+	w.sendMu.Unlock()
+	<-w.done
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.failed > 0 {
+		return fmt.Errorf("%w: %d of %d batches to [%s], last error: %v", ErrDeliveryFailed, w.failed, w.batches, w.url, w.lastErr)
+	}
+	return nil
+}
+
+// loop gathers the events in batches, posted when full or when the flush interval ends
+func (w *Webhook) loop() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.options.FlushInterval)
+	defer ticker.Stop()
+
+	batch := []Event{}
+	flush := func() {
+		if len(batch) > 0 {
+			w.deliver(batch)
+			batch = []Event{}
+		}
+	}
+	for {
+		select {
+		case e, ok := <-w.events:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, e)
+			if len(batch) >= w.options.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// deliver posts a batch, retrying on network errors, 429 and 5xx responses
+func (w *Webhook) deliver(events []Event) {
+	body, err := json.Marshal(Batch{Events: events})
+	if err == nil {
+		backoff := w.options.Backoff
+		for attempt := 0; ; attempt++ {
+			var retry bool
+			retry, err = w.post(body)
+			if err == nil || !retry || attempt >= w.options.Retries {
+				break
+			}
+			log.Printf("could not post %d events to webhook [%s], retrying in %s: %v", len(events), w.url, backoff, err)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.batches++
+	if err != nil {
+		log.Printf("could not post %d events to webhook [%s]: %v", len(events), w.url, err)
+		w.failed++
+		w.lastErr = err
+	}
+}
+
+// post sends a body once, it informs if a failure is worth retrying
+func (w *Webhook) post(body []byte) (bool, error) {
+	resp, err := w.options.Client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("response status %d", resp.StatusCode)
+	}
+	return false, nil
+}
@@ -0,0 +1,164 @@
+package hooks_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/hooks"
+)
+
+// receiver records the batches posted to it, answering with the statuses in order and with
+// 200 once they run out
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests int
+	batches  [][]string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests++
+	if len(r.statuses) > 0 {
+		status := r.statuses[0]
+		r.statuses = r.statuses[1:]
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+	}
+
+	var batch hooks.Batch
+	if err := json.NewDecoder(req.Body).Decode(&batch); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	urls := []string{}
+	for _, e := range batch.Events {
+		urls = append(urls, e.URL)
+	}
+	r.batches = append(r.batches, urls)
+}
+
+func TestWebhook(t *testing.T) {
+	type testCase struct {
+		testName         string
+		statuses         []int
+		retries          int
+		events           []string
+		expectedBatches  [][]string
+		expectedRequests int
+		expectedErr      error
+	}
+
+	testCases := []testCase{
+		{
+			testName:         "batches",
+			events:           []string{"/1", "/2", "/3", "/4", "/5"},
+			expectedBatches:  [][]string{{"/1", "/2"}, {"/3", "/4"}, {"/5"}},
+			expectedRequests: 3,
+		},
+		{
+			testName:         "retried_until_delivered",
+			statuses:         []int{http.StatusServiceUnavailable, http.StatusTooManyRequests},
+			retries:          2,
+			events:           []string{"/1"},
+			expectedBatches:  [][]string{{"/1"}},
+			expectedRequests: 3,
+		},
+		{
+			testName:         "retries_exhausted",
+			statuses:         []int{http.StatusInternalServerError, http.StatusInternalServerError},
+			retries:          1,
+			events:           []string{"/1"},
+			expectedRequests: 2,
+			expectedErr:      hooks.ErrDeliveryFailed,
+		},
+		{
+			testName:         "client_errors_not_retried",
+			statuses:         []int{http.StatusBadRequest},
+			retries:          3,
+			events:           []string{"/1"},
+			expectedRequests: 1,
+			expectedErr:      hooks.ErrDeliveryFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			r := &receiver{statuses: tc.statuses}
+			server := httptest.NewServer(r)
+			defer server.Close()
+
+			webhook := hooks.NewWebhook(server.URL, hooks.WebhookOptions{BatchSize: 2, FlushInterval: time.Minute, Retries: tc.retries, Backoff: time.Millisecond})
+			for _, url := range tc.events {
+				webhook.Send(hooks.Event{Stage: hooks.OnStore, URL: url})
+			}
+			if err := webhook.Close(); !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected %v, got %v", tc.expectedErr, err)
+			}
+
+			if diff := cmp.Diff(tc.expectedBatches, r.batches); diff != "" {
+				t.Errorf("unexpected batches (-expected +actual):\n%s", diff)
+			}
+			if r.requests != tc.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tc.expectedRequests, r.requests)
+			}
+		})
+	}
+}
+
+func TestWebhook_FlushInterval(t *testing.T) {
+	r := &receiver{}
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	webhook := hooks.NewWebhook(server.URL, hooks.WebhookOptions{BatchSize: 100, FlushInterval: 10 * time.Millisecond})
+	defer webhook.Close()
+	webhook.Send(hooks.Event{Stage: hooks.OnError, URL: "/1"})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		r.mu.Lock()
+		delivered := len(r.batches)
+		r.mu.Unlock()
+		if delivered == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the event to be posted before the batch is full")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWebhook_Subscribe(t *testing.T) {
+	r := &receiver{}
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	var h hooks.Hooks
+	webhook := hooks.NewWebhook(server.URL, hooks.WebhookOptions{})
+	webhook.Subscribe(h.Add, []hooks.Stage{hooks.AfterDownload}, 500)
+
+	h.Fire(hooks.Event{Stage: hooks.AfterDownload, URL: "/ok", StatusCode: 200})
+	h.Fire(hooks.Event{Stage: hooks.AfterDownload, URL: "/broken", StatusCode: 502})
+	h.Fire(hooks.Event{Stage: hooks.OnError, URL: "/other", StatusCode: 500})
+	if err := webhook.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// events after closing are dropped
+	webhook.Send(hooks.Event{Stage: hooks.AfterDownload, URL: "/late", StatusCode: 500})
+
+	if diff := cmp.Diff([][]string{{"/broken"}}, r.batches); diff != "" {
+		t.Errorf("unexpected batches (-expected +actual):\n%s", diff)
+	}
+}
@@ -0,0 +1,155 @@
+package jobs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/thiagolcmelo/webcrawler/src/config"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+)
+
+// contentTypes maps the report formats to the content type their results are served with
+var contentTypes = map[string]string{
+	"raw":            "text/plain; charset=utf-8",
+	"json":           "application/json",
+	"json-formatted": "application/json",
+	"dot":            "text/vnd.graphviz; charset=utf-8",
+	"graphml":        "application/xml",
+	"gexf":           "application/xml",
+	"csv":            "text/csv; charset=utf-8",
+	"csv-edges":      "text/csv; charset=utf-8",
+	"ndjson":         "application/x-ndjson",
+}
+
+// API serves the jobs of a manager over HTTP:
+//
+//	POST /jobs                 submits a job, the body is a Request
+//	GET  /jobs                 lists the jobs, oldest first
+//	GET  /jobs/{id}            gets a job with its live progress
+//	POST /jobs/{id}/cancel     cancels a queued or running job
+//	GET  /jobs/{id}/results    gets the report of a job, ?format= takes any report format, json by default
+type API struct {
+	manager *Manager
+	mux     *http.ServeMux
+}
+
+// NewAPI is a factory for API
+func NewAPI(manager *Manager) *API {
+	a := &API{manager: manager, mux: http.NewServeMux()}
+	a.mux.HandleFunc("/jobs", a.jobs)
+	a.mux.HandleFunc("/jobs/", a.job)
+	return a
+}
+
+// ServeHTTP routes a request to its handler
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mux.ServeHTTP(w, r)
+}
+
+// jobs handles the collection of jobs
+func (a *API) jobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, a.manager.List())
+	case http.MethodPost:
+		var req Request
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid job request: %w", err))
+			return
+		}
+		job, err := a.manager.Submit(req)
+		if err != nil {
+			writeError(w, statusOf(err), err)
+			return
+		}
+		w.Header().Set("Location", "/jobs/"+job.ID)
+		writeJSON(w, http.StatusCreated, job)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// job handles a single job and its actions
+func (a *API) job(w http.ResponseWriter, r *http.Request) {
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/jobs/"), "/")
+
+	switch action {
+	case "":
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		job, err := a.manager.Get(id)
+		if err != nil {
+			writeError(w, statusOf(err), err)
+			return
+		}
+		writeJSON(w, http.StatusOK, job)
+	case "cancel":
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		job, err := a.manager.Cancel(id)
+		if err != nil {
+			writeError(w, statusOf(err), err)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, job)
+	case "results":
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "json"
+		}
+		// the report is buffered so a failure can still be answered with an error
+		var buf bytes.Buffer
+		if err := a.manager.WriteResults(&buf, id, format); err != nil {
+			writeError(w, statusOf(err), err)
+			return
+		}
+		w.Header().Set("Content-Type", contentTypes[format])
+		w.Write(buf.Bytes())
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown action [%s]", action))
+	}
+}
+
+// statusOf maps the errors of the manager to HTTP status codes
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, ErrUnknownJob):
+		return http.StatusNotFound
+	case errors.Is(err, ErrJobFinished), errors.Is(err, ErrNoResults):
+		return http.StatusConflict
+	case errors.Is(err, ErrNoSeeds), errors.Is(err, ErrUnsupportedOption), errors.Is(err, config.ErrUnknownKey),
+		errors.Is(err, config.ErrInvalidValue), errors.Is(err, config.ErrConflictingKeys),
+		errors.Is(err, config.ErrUnknownProfile), errors.Is(err, report.ErrUnknownFormat):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,157 @@
+package jobs_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thiagolcmelo/webcrawler/src/jobs"
+)
+
+func TestAPI(t *testing.T) {
+	site := fakeSite()
+	defer site.Close()
+
+	m := newManager(t, t.TempDir(), 1)
+	defer m.Close()
+	api := httptest.NewServer(jobs.NewAPI(m))
+	defer api.Close()
+
+	resp, err := http.Post(api.URL+"/jobs", "application/json", strings.NewReader(`{"seeds": ["`+site.URL+`"], "options": {"workers": 2}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var submitted jobs.Job
+	json.NewDecoder(resp.Body).Decode(&submitted)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || submitted.ID == "" {
+		t.Fatalf("expected the job to be created, got %d", resp.StatusCode)
+	}
+	if location := resp.Header.Get("Location"); location != "/jobs/"+submitted.ID {
+		t.Errorf("unexpected location %s", location)
+	}
+	wait(t, m, submitted.ID)
+
+	type testCase struct {
+		testName            string
+		method              string
+		path                string
+		body                string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}
+
+	testCases := []testCase{
+		{
+			testName:            "list_jobs",
+			method:              http.MethodGet,
+			path:                "/jobs",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        `"id":"` + submitted.ID + `"`,
+		},
+		{
+			testName:            "get_job",
+			method:              http.MethodGet,
+			path:                "/jobs/" + submitted.ID,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        `"state":"finished"`,
+		},
+		{
+			testName:            "results_in_json_by_default",
+			method:              http.MethodGet,
+			path:                "/jobs/" + submitted.ID + "/results",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        `"url":"` + site.URL + `/page1"`,
+		},
+		{
+			testName:            "results_in_another_format",
+			method:              http.MethodGet,
+			path:                "/jobs/" + submitted.ID + "/results?format=dot",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/vnd.graphviz; charset=utf-8",
+			expectedBody:        "digraph",
+		},
+		{
+			testName:            "results_in_unknown_format",
+			method:              http.MethodGet,
+			path:                "/jobs/" + submitted.ID + "/results?format=pdf",
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        "unknown format [pdf]",
+		},
+		{
+			testName:            "cancel_finished_job",
+			method:              http.MethodPost,
+			path:                "/jobs/" + submitted.ID + "/cancel",
+			expectedStatus:      http.StatusConflict,
+			expectedContentType: "application/json",
+			expectedBody:        "job already finished",
+		},
+		{
+			testName:            "unknown_job",
+			method:              http.MethodGet,
+			path:                "/jobs/missing",
+			expectedStatus:      http.StatusNotFound,
+			expectedContentType: "application/json",
+			expectedBody:        "unknown job [missing]",
+		},
+		{
+			testName:            "submit_without_seeds",
+			method:              http.MethodPost,
+			path:                "/jobs",
+			body:                `{"seeds": []}`,
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        "job has no seeds",
+		},
+		{
+			testName:            "submit_unknown_field",
+			method:              http.MethodPost,
+			path:                "/jobs",
+			body:                `{"seed": "https://domain.com"}`,
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody:        "invalid job request",
+		},
+		{
+			testName:            "method_not_allowed",
+			method:              http.MethodDelete,
+			path:                "/jobs/" + submitted.ID,
+			expectedStatus:      http.StatusMethodNotAllowed,
+			expectedContentType: "application/json",
+			expectedBody:        "method not allowed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, api.URL+tc.path, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tc.expectedStatus, resp.StatusCode, body)
+			}
+			if contentType := resp.Header.Get("Content-Type"); contentType != tc.expectedContentType {
+				t.Errorf("expected content type %s, got %s", tc.expectedContentType, contentType)
+			}
+			if !strings.Contains(string(body), tc.expectedBody) {
+				t.Errorf("expected %q in:\n%s", tc.expectedBody, body)
+			}
+		})
+	}
+}
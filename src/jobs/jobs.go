@@ -0,0 +1,511 @@
+// Package jobs runs crawls submitted as jobs, each one with its own orchestrator, and keeps
+// their metadata and results in a directory so they outlive the process
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/thiagolcmelo/webcrawler/src"
+	"github.com/thiagolcmelo/webcrawler/src/config"
+	"github.com/thiagolcmelo/webcrawler/src/hooks"
+	"github.com/thiagolcmelo/webcrawler/src/memory"
+	"github.com/thiagolcmelo/webcrawler/src/progress"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+)
+
+var (
+	// ErrUnknownJob should be used when no job has the requested id
+	ErrUnknownJob = errors.New("unknown job")
+	// ErrNoSeeds should be used when a job is submitted without seeds
+	ErrNoSeeds = errors.New("job has no seeds")
+	// ErrUnsupportedOption should be used when an option only makes sense for the get command
+	ErrUnsupportedOption = errors.New("option not supported by jobs")
+	// ErrJobFinished should be used when canceling a job that is not queued or running
+	ErrJobFinished = errors.New("job already finished")
+	// ErrJobNotDone should be used when deleting a job that is still queued or running
+	ErrJobNotDone = errors.New("job not done")
+	// ErrNoResults should be used when the results of a job are requested before it finishes,
+	// or when it finished without any
+	ErrNoResults = errors.New("job has no results")
+	// ErrCanceled is the cause of the context of a canceled job
+	ErrCanceled = errors.New("job canceled")
+	// errStopped is the cause of the context of the jobs when the manager is closed
+	errStopped = errors.New("the server stopped while the job was running")
+)
+
+// State is the stage of its life a job is in
+type State string
+
+const (
+	// Queued jobs wait for one of the running jobs to finish
+	Queued State = "queued"
+	// Running jobs are crawling
+	Running State = "running"
+	// Finished jobs crawled until there was nothing left or their timeout
+	Finished State = "finished"
+	// Canceled jobs were canceled before finishing, running ones keep their partial results
+	Canceled State = "canceled"
+	// Failed jobs could not run or could not keep their results
+	Failed State = "failed"
+)
+
+// jobOptions are the options of the get command that apply to jobs, the others are about
+// outputs that jobs do not have
+var jobOptions = map[string]bool{
+	"analysis":               true,
+	"backoff":                true,
+	"backoff-multiplier":     true,
+	"grace-period":           true,
+	"ignore-scheme":          true,
+	"retries":                true,
+	"stats":                  true,
+	"timeout":                true,
+	"webhook":                true,
+	"webhook-batch-size":     true,
+	"webhook-flush-interval": true,
+	"webhook-min-status":     true,
+	"webhook-retries":        true,
+	"webhook-stages":         true,
+	"workers":                true,
+}
+
+// Request is what is needed to submit a job, options are named as the flags of get and are
+// applied on top of the built in profile, if any
+type Request struct {
+	Seeds   []string               `json:"seeds"`
+	Profile string                 `json:"profile,omitempty"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// Job is the metadata of a crawl job, its progress is live while it runs
+type Job struct {
+	ID string `json:"id"`
+	Request
+	State    State             `json:"state"`
+	Error    string            `json:"error,omitempty"`
+	Partial  bool              `json:"partial"`
+	Created  time.Time         `json:"created"`
+	Started  *time.Time        `json:"started,omitempty"`
+	Finished *time.Time        `json:"finished,omitempty"`
+	Progress progress.Snapshot `json:"progress"`
+}
+
+// IsDone informs if the job will not change anymore
+func (j Job) IsDone() bool {
+	return j.State == Finished || j.State == Canceled || j.State == Failed
+}
+
+// entry is a job along with what is needed to run it
+type entry struct {
+	job     Job
+	cfg     config.Config
+	cancel  context.CancelCauseFunc
+	counter *progress.Counter
+	report  *report.Report
+	done    chan struct{}
+}
+
+// Manager runs the jobs submitted, at most limit of them at a time, and persists them in a store
+type Manager struct {
+	ctx   context.Context
+	stop  context.CancelCauseFunc
+	mu    sync.Mutex
+	wg    sync.WaitGroup
+	slots chan struct{}
+	store *Store
+	jobs  map[string]*entry
+}
+
+// NewManager opens the store and creates a Manager running up to limit jobs at a time, jobs
+// left queued by a previous process are queued again and the running ones are marked failed
+func NewManager(ctx context.Context, store *Store, limit int) (*Manager, error) {
+	if limit < 1 {
+		limit = 1
+	}
+	ctx, stop := context.WithCancelCause(ctx)
+	m := &Manager{
+		ctx:   ctx,
+		stop:  stop,
+		slots: make(chan struct{}, limit),
+		store: store,
+		jobs:  map[string]*entry{},
+	}
+
+	persisted, err := store.Jobs()
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range persisted {
+		e := &entry{job: job, done: make(chan struct{})}
+		m.jobs[job.ID] = e
+
+		switch job.State {
+		case Queued:
+			if e.cfg, err = resolve(job.Request); err != nil {
+				m.finish(e, Failed, err)
+				continue
+			}
+			m.run(e)
+		case Running:
+			m.finish(e, Failed, errStopped)
+		default:
+			close(e.done)
+		}
+	}
+	return m, nil
+}
+
+// Submit validates a request and queues its job, which starts as soon as there is a free slot
+func (m *Manager) Submit(r Request) (Job, error) {
+	cfg, err := resolve(r)
+	if err != nil {
+		return Job{}, err
+	}
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	e := &entry{
+		job:  Job{ID: id, Request: r, State: Queued, Created: time.Now().UTC()},
+		cfg:  cfg,
+		done: make(chan struct{}),
+	}
+	if err := m.store.Save(e.job); err != nil {
+		return Job{}, err
+	}
+	job := e.job
+
+	// the job can be canceled as soon as it is listed
+	m.run(e)
+	m.mu.Lock()
+	m.jobs[id] = e
+	m.mu.Unlock()
+	return job, nil
+}
+
+// Validate checks a request as Submit does, without queueing it
+func Validate(r Request) error {
+	_, err := resolve(r)
+	return err
+}
+
+// resolve builds the options of a job from the defaults, its profile and its options
+func resolve(r Request) (config.Config, error) {
+	cfg := config.Default()
+	if len(r.Seeds) == 0 {
+		return cfg, ErrNoSeeds
+	}
+	if r.Profile != "" {
+		values, ok := config.Profiles[r.Profile]
+		if !ok {
+			return cfg, fmt.Errorf("%w [%s]", config.ErrUnknownProfile, r.Profile)
+		}
+		// options of the profile about outputs do not matter to jobs
+		if err := cfg.Apply(values, func(key string) bool { return !jobOptions[key] }); err != nil {
+			return cfg, err
+		}
+	}
+
+	values := map[string]interface{}{}
+	for key, value := range r.Options {
+		key = config.NormalizeKey(key)
+		if !jobOptions[key] {
+			return cfg, fmt.Errorf("%w [%s]", ErrUnsupportedOption, key)
+		}
+		values[key] = value
+	}
+	if err := cfg.Apply(values, nil); err != nil {
+		return cfg, err
+	}
+	if _, err := hooks.ParseStages(cfg.WebhookStages); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// newID creates a random job id
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// run crawls the job in the background once a slot is free, unless it is canceled first
+func (m *Manager) run(e *entry) {
+	ctx, cancel := context.WithCancelCause(m.ctx)
+	m.mu.Lock()
+	e.cancel = cancel
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer cancel(nil)
+
+		select {
+		case m.slots <- struct{}{}:
+			defer func() { <-m.slots }()
+		case <-ctx.Done():
+			// jobs still queued when the manager is closed are queued again when it is reopened
+			if errors.Is(context.Cause(ctx), ErrCanceled) {
+				m.finish(e, Canceled, nil)
+			}
+			return
+		}
+		m.crawl(ctx, e)
+	}()
+}
+
+// crawl runs the orchestrator of a job and keeps its report
+func (m *Manager) crawl(ctx context.Context, e *entry) {
+	counter := progress.NewCounter(memory.NewEvents(), len(e.job.Seeds))
+	started := time.Now().UTC()
+	m.mu.Lock()
+	e.counter = counter
+	e.job.State = Running
+	e.job.Started = &started
+	err := m.store.Save(e.job)
+	m.mu.Unlock()
+	if err != nil {
+		m.finish(e, Failed, err)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, e.cfg.Timeout)
+	defer cancel()
+
+	o := src.NewOrchestrator(
+		timeoutCtx,
+		e.cfg.Workers,
+		memory.NewFrontier(),
+		memory.NewStorage(),
+		counter,
+		e.cfg.Retries,
+		e.cfg.Backoff,
+		e.cfg.BackoffMultiplier,
+	)
+	if e.cfg.IgnoreScheme {
+		o.IgnoreScheme()
+	}
+	var webhook *hooks.Webhook
+	if e.cfg.Webhook != "" {
+		// the stages were checked when the job was submitted
+		stages, _ := hooks.ParseStages(e.cfg.WebhookStages)
+		webhook = hooks.NewWebhook(e.cfg.Webhook, hooks.WebhookOptions{
+			BatchSize:     e.cfg.WebhookBatchSize,
+			FlushInterval: e.cfg.WebhookFlush,
+			Retries:       e.cfg.WebhookRetries,
+		})
+		webhook.Subscribe(o.Hook, stages, e.cfg.WebhookMinStatus)
+	}
+	o.GracePeriod(e.cfg.GracePeriod)
+	o.Start(e.job.Seeds...)
+	o.Drain()
+	if webhook != nil {
+		// a webhook that is down does not fail the crawl
+		if err := webhook.Close(); err != nil {
+			log.Printf("job [%s]: %v", e.job.ID, err)
+		}
+	}
+
+	r := o.Report(e.cfg.Stats)
+	if e.cfg.Analysis {
+		a := o.Analyze(nil)
+		r.Analysis = &a
+	}
+	if err := m.store.SaveReport(e.job.ID, r); err != nil {
+		m.finish(e, Failed, fmt.Errorf("could not keep the results: %w", err))
+		return
+	}
+
+	m.mu.Lock()
+	e.report = &r
+	e.job.Partial = r.Partial
+	m.mu.Unlock()
+
+	switch cause := context.Cause(ctx); {
+	case cause == nil:
+		m.finish(e, Finished, nil)
+	case errors.Is(cause, ErrCanceled):
+		m.finish(e, Canceled, nil)
+	default:
+		m.finish(e, Canceled, errStopped)
+	}
+}
+
+// finish moves a job to its final state and persists it
+func (m *Manager) finish(e *entry, state State, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	finished := time.Now().UTC()
+	e.job.State = state
+	e.job.Finished = &finished
+	if err != nil {
+		e.job.Error = err.Error()
+	}
+	if e.counter != nil {
+		e.job.Progress = e.counter.Snapshot()
+	}
+	if err := m.store.Save(e.job); err != nil {
+		e.job.Error = fmt.Sprintf("could not save the job: %v", err)
+	}
+	close(e.done)
+}
+
+// List returns every job, oldest first
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, e := range m.jobs {
+		jobs = append(jobs, e.snapshot())
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].Created.Equal(jobs[j].Created) {
+			return jobs[i].Created.Before(jobs[j].Created)
+		}
+		return jobs[i].ID < jobs[j].ID
+	})
+	return jobs
+}
+
+// Get returns a job, with live progress counts while it runs
+func (m *Manager) Get(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.jobs[id]
+	if !ok {
+		return Job{}, fmt.Errorf("%w [%s]", ErrUnknownJob, id)
+	}
+	return e.snapshot(), nil
+}
+
+// snapshot copies the job, taking the current counts while it runs
+func (e *entry) snapshot() Job {
+	job := e.job
+	if job.State == Running && e.counter != nil {
+		job.Progress = e.counter.Snapshot()
+	}
+	return job
+}
+
+// Wait blocks until a job is done or the context is
+func (m *Manager) Wait(ctx context.Context, id string) (Job, error) {
+	m.mu.Lock()
+	e, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return Job{}, fmt.Errorf("%w [%s]", ErrUnknownJob, id)
+	}
+
+	select {
+	case <-e.done:
+		return m.Get(id)
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	}
+}
+
+// Cancel stops a queued or running job, a running job is drained and keeps its partial results
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.jobs[id]
+	if !ok {
+		return Job{}, fmt.Errorf("%w [%s]", ErrUnknownJob, id)
+	}
+	if e.job.IsDone() || e.cancel == nil {
+		return e.snapshot(), fmt.Errorf("%w [%s]", ErrJobFinished, id)
+	}
+	e.cancel(ErrCanceled)
+	return e.snapshot(), nil
+}
+
+// WriteResults writes the report of a job in one of the report formats
+func (m *Manager) WriteResults(w io.Writer, id string, format string) error {
+	rw, err := report.NewWriter(format)
+	if err != nil {
+		return err
+	}
+	r, err := m.Report(id)
+	if err != nil {
+		return err
+	}
+	return rw.Write(w, r)
+}
+
+// Report returns the report of a job that is done
+func (m *Manager) Report(id string) (report.Report, error) {
+	m.mu.Lock()
+	e, ok := m.jobs[id]
+	var r *report.Report
+	var done bool
+	if ok {
+		r, done = e.report, e.job.IsDone()
+	}
+	m.mu.Unlock()
+	if !ok {
+		return report.Report{}, fmt.Errorf("%w [%s]", ErrUnknownJob, id)
+	}
+	if !done {
+		return report.Report{}, fmt.Errorf("%w [%s] yet", ErrNoResults, id)
+	}
+
+	if r == nil {
+		// the job ran in a previous process
+		loaded, err := m.store.Report(id)
+		if errors.Is(err, ErrNoResults) {
+			return report.Report{}, fmt.Errorf("%w [%s]", ErrNoResults, id)
+		}
+		if err != nil {
+			return report.Report{}, err
+		}
+		r = &loaded
+		m.mu.Lock()
+		e.report = r
+		m.mu.Unlock()
+	}
+	return *r, nil
+}
+
+// Delete forgets a job that is done and removes its files from the store
+func (m *Manager) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.jobs[id]
+	if !ok {
+		return fmt.Errorf("%w [%s]", ErrUnknownJob, id)
+	}
+	if !e.job.IsDone() {
+		return fmt.Errorf("%w [%s]", ErrJobNotDone, id)
+	}
+	if err := m.store.Delete(id); err != nil {
+		return err
+	}
+	delete(m.jobs, id)
+	return nil
+}
+
+// Close stops the running jobs, which keep their partial results, and waits for them to be
+// persisted, queued jobs stay queued
+func (m *Manager) Close() {
+	m.stop(errStopped)
+	m.wg.Wait()
+}
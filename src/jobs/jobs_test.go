@@ -0,0 +1,259 @@
+package jobs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thiagolcmelo/webcrawler/src/config"
+	"github.com/thiagolcmelo/webcrawler/src/hooks"
+	"github.com/thiagolcmelo/webcrawler/src/jobs"
+)
+
+// fakeSite serves a root page linking to two pages
+func fakeSite() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<a href="/page1">page 1</a><a href="/page2">page 2</a>`))
+		case "/page1", "/page2":
+			w.Write([]byte(fmt.Sprintf(`<a href="/">home</a> at %s`, r.URL.Path)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// blockingSite serves pages only after release is closed
+func blockingSite(release chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+			w.Write([]byte("released"))
+		case <-r.Context().Done():
+		}
+	}))
+}
+
+func newManager(t *testing.T, dir string, limit int) *jobs.Manager {
+	store, err := jobs.OpenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := jobs.NewManager(context.Background(), store, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func wait(t *testing.T, m *jobs.Manager, id string) jobs.Job {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	job, err := m.Wait(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func TestManager_Submit(t *testing.T) {
+	site := fakeSite()
+	defer site.Close()
+
+	m := newManager(t, t.TempDir(), 2)
+	defer m.Close()
+
+	job, err := m.Submit(jobs.Request{Seeds: []string{site.URL}, Options: map[string]interface{}{"workers": 2, "stats": true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.State != jobs.Queued && job.State != jobs.Running {
+		t.Errorf("expected the job to be queued, got %s", job.State)
+	}
+
+	job = wait(t, m, job.ID)
+	if job.State != jobs.Finished || job.Partial {
+		t.Errorf("expected the job to finish, got %s with partial %v", job.State, job.Partial)
+	}
+	if job.Progress.Stored != 3 {
+		t.Errorf("expected 3 stored pages, got %d", job.Progress.Stored)
+	}
+
+	var buf bytes.Buffer
+	if err := m.WriteResults(&buf, job.ID, "raw"); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{site.URL + "/page1", site.URL + "/page2", "stats:"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, buf.String())
+		}
+	}
+
+	if _, err := m.Cancel(job.ID); !errors.Is(err, jobs.ErrJobFinished) {
+		t.Errorf("expected %v, got %v", jobs.ErrJobFinished, err)
+	}
+	if err := m.WriteResults(&buf, job.ID, "unknown"); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestManager_SubmitInvalid(t *testing.T) {
+	type testCase struct {
+		testName    string
+		request     jobs.Request
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{
+			testName:    "no_seeds",
+			request:     jobs.Request{},
+			expectedErr: jobs.ErrNoSeeds,
+		},
+		{
+			testName:    "option_about_outputs",
+			request:     jobs.Request{Seeds: []string{"https://domain.com"}, Options: map[string]interface{}{"output": "out.json"}},
+			expectedErr: jobs.ErrUnsupportedOption,
+		},
+		{
+			testName:    "invalid_value",
+			request:     jobs.Request{Seeds: []string{"https://domain.com"}, Options: map[string]interface{}{"workers": "many"}},
+			expectedErr: config.ErrInvalidValue,
+		},
+		{
+			testName:    "unknown_webhook_stage",
+			request:     jobs.Request{Seeds: []string{"https://domain.com"}, Options: map[string]interface{}{"webhook": "http://localhost/hook", "webhook-stages": "on-crash"}},
+			expectedErr: hooks.ErrUnknownStage,
+		},
+		{
+			testName:    "unknown_profile",
+			request:     jobs.Request{Seeds: []string{"https://domain.com"}, Profile: "rude"},
+			expectedErr: config.ErrUnknownProfile,
+		},
+	}
+
+	m := newManager(t, t.TempDir(), 1)
+	defer m.Close()
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if _, err := m.Submit(tc.request); !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+
+	if jobs := m.List(); len(jobs) != 0 {
+		t.Errorf("expected invalid jobs not to be listed, got %d", len(jobs))
+	}
+}
+
+func TestManager_Limit(t *testing.T) {
+	release := make(chan struct{})
+	site := blockingSite(release)
+	defer site.Close()
+	defer close(release)
+
+	m := newManager(t, t.TempDir(), 1)
+	defer m.Close()
+
+	options := map[string]interface{}{"grace-period": "1s", "timeout": "1m"}
+	first, err := m.Submit(jobs.Request{Seeds: []string{site.URL}, Options: options})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first job holds the only slot while its seed is being downloaded
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		job, _ := m.Get(first.ID)
+		if job.State == jobs.Running && job.Progress.Discovered == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the first job to run, got %s", job.State)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	second, err := m.Submit(jobs.Request{Seeds: []string{site.URL}, Options: options})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job, _ := m.Get(second.ID); job.State != jobs.Queued {
+		t.Errorf("expected the second job to be queued, got %s", job.State)
+	}
+	if err := m.WriteResults(&bytes.Buffer{}, first.ID, "json"); !errors.Is(err, jobs.ErrNoResults) {
+		t.Errorf("expected %v while running, got %v", jobs.ErrNoResults, err)
+	}
+
+	if err := m.Delete(first.ID); !errors.Is(err, jobs.ErrJobNotDone) {
+		t.Errorf("expected %v while running, got %v", jobs.ErrJobNotDone, err)
+	}
+
+	if _, err := m.Cancel(second.ID); err != nil {
+		t.Fatal(err)
+	}
+	if job := wait(t, m, second.ID); job.State != jobs.Canceled || job.Started != nil {
+		t.Errorf("expected the second job to be canceled before starting, got %s", job.State)
+	}
+
+	if _, err := m.Cancel(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if job := wait(t, m, first.ID); job.State != jobs.Canceled || !job.Partial {
+		t.Errorf("expected the first job to be canceled with partial results, got %s with partial %v", job.State, job.Partial)
+	}
+	if err := m.WriteResults(&bytes.Buffer{}, first.ID, "json"); err != nil {
+		t.Errorf("expected the partial results of the first job, got %v", err)
+	}
+}
+
+func TestManager_Reopen(t *testing.T) {
+	site := fakeSite()
+	defer site.Close()
+	dir := t.TempDir()
+
+	m := newManager(t, dir, 1)
+	job, err := m.Submit(jobs.Request{Seeds: []string{site.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wait(t, m, job.ID)
+	m.Close()
+
+	reopened := newManager(t, dir, 1)
+	defer reopened.Close()
+
+	listed := reopened.List()
+	if len(listed) != 1 || listed[0].ID != job.ID || listed[0].State != jobs.Finished || listed[0].Progress.Stored != 3 {
+		t.Fatalf("expected the finished job to be kept, got %+v", listed)
+	}
+
+	var buf bytes.Buffer
+	if err := reopened.WriteResults(&buf, job.ID, "csv"); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 4 {
+		t.Errorf("expected a header and 3 rows, got:\n%s", buf.String())
+	}
+
+	if err := reopened.Delete(job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.Get(job.ID); !errors.Is(err, jobs.ErrUnknownJob) {
+		t.Errorf("expected %v after deleting, got %v", jobs.ErrUnknownJob, err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("expected the files of the job to be removed, got %d", len(files))
+	}
+}
@@ -0,0 +1,121 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/thiagolcmelo/webcrawler/src/report"
+)
+
+// ErrInvalidJobFile should be used when a persisted job cannot be read
+var ErrInvalidJobFile = errors.New("invalid job file")
+
+// reportSuffix ends the name of the file holding the report of a job, next to its metadata
+const reportSuffix = ".report.json"
+
+// Store persists the metadata of the jobs and their reports as JSON files in a directory
+type Store struct {
+	dir string
+}
+
+// OpenStore creates the directory of the store if it does not exist yet
+func OpenStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Save writes the metadata of a job, replacing the previous one
+func (s *Store) Save(job Job) error {
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.write(job.ID+".json", data)
+}
+
+// SaveReport writes the report of a job
+func (s *Store) SaveReport(id string, r report.Report) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return s.write(id+reportSuffix, data)
+}
+
+// write replaces a file through a temporary one, so a crash never leaves it half written
+func (s *Store) write(name string, data []byte) error {
+	tmp, err := os.CreateTemp(s.dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, name))
+}
+
+// Delete removes the metadata and the report of a job, files already missing are ignored
+func (s *Store) Delete(id string) error {
+	for _, name := range []string{id + ".json", id + reportSuffix} {
+		if err := os.Remove(filepath.Join(s.dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Jobs reads the metadata of every job persisted, sorted by id
+func (s *Store) Jobs() ([]Job, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := []Job{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") || strings.HasSuffix(name, reportSuffix) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, name))
+		if err != nil {
+			return nil, err
+		}
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil || job.ID == "" {
+			return nil, fmt.Errorf("%w [%s]", ErrInvalidJobFile, name)
+		}
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+	return jobs, nil
+}
+
+// Report reads the report of a job
+func (s *Store) Report(id string) (report.Report, error) {
+	var r report.Report
+	data, err := os.ReadFile(filepath.Join(s.dir, id+reportSuffix))
+	if errors.Is(err, os.ErrNotExist) {
+		return r, ErrNoResults
+	}
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("%w [%s]: %v", ErrInvalidJobFile, id+reportSuffix, err)
+	}
+	return r, nil
+}
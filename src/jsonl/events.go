@@ -0,0 +1,163 @@
+package jsonl
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/thiagolcmelo/webcrawler/src/events"
+)
+
+// Line is how an event is represented in a JSON line
+type Line struct {
+	URL       string    `json:"url"`
+	Stage     string    `json:"stage"`
+	Success   bool      `json:"success"`
+	Value     int       `json:"value"`
+	Reason    string    `json:"reason,omitempty"`
+	Size      int       `json:"size,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Events is an implementation of Events that streams every event as a JSON line,
+// only discovered addresses are kept so it can tell what was already discovered
+type Events struct {
+	encoder    *json.Encoder
+	discovered map[string]struct{}
+	err        error
+	sync.Mutex
+}
+
+// NewEvents is a factory for JSON lines Events
+func NewEvents(w io.Writer) *Events {
+	return &Events{
+		encoder:    json.NewEncoder(w),
+		discovered: map[string]struct{}{},
+	}
+}
+
+func (je *Events) write(address string, evt events.EventInstance) {
+	je.Lock()
+	defer je.Unlock()
+
+	if evt.EventType == events.Discovery {
+		je.discovered[address] = struct{}{}
+	}
+
+	err := je.encoder.Encode(Line{
+		URL:       address,
+		Stage:     evt.EventType.String(),
+		Success:   evt.Success,
+		Value:     evt.Value,
+		Reason:    evt.Reason,
+		Size:      evt.Size,
+		Timestamp: evt.Time,
+	})
+	if err != nil && je.err == nil {
+		log.Printf("could not stream event for url [%s]: %v", address, err)
+		je.err = err
+	}
+}
+
+// LogDiscoveryEvent streams a Discovery event
+func (je *Events) LogDiscoveryEvent(address string, success bool) {
+	je.write(address, events.EventInstance{EventType: events.Discovery, Success: success, Time: time.Now()})
+}
+
+// LogDownloadEvent streams a Download event
+func (je *Events) LogDownloadEvent(address string, success bool) {
+	je.write(address, events.EventInstance{EventType: events.Download, Success: success, Time: time.Now()})
+}
+
+// LogParseEvent streams a Parse event
+func (je *Events) LogParseEvent(address string, success bool, children int) {
+	je.write(address, events.EventInstance{EventType: events.Parse, Success: success, Value: children, Time: time.Now()})
+}
+
+// LogStoreEvent streams a Store event
+func (je *Events) LogStoreEvent(address string, success bool) {
+	je.write(address, events.EventInstance{EventType: events.Store, Success: success, Time: time.Now()})
+}
+
+// LogDispatchEvent streams a Dispatch event
+func (je *Events) LogDispatchEvent(address string, success bool, children int) {
+	je.write(address, events.EventInstance{EventType: events.Dispatch, Success: success, Value: children, Time: time.Now()})
+}
+
+// LogDuplicateEvent streams a Duplicate event
+func (je *Events) LogDuplicateEvent(address string) {
+	je.write(address, events.EventInstance{EventType: events.Duplicate, Time: time.Now()})
+}
+
+// LogErrorEvent streams an Error event along with its reason
+func (je *Events) LogErrorEvent(address string, reason string) {
+	je.write(address, events.EventInstance{EventType: events.Error, Reason: reason, Time: time.Now()})
+}
+
+// LogResponseEvent streams a Response event with its status code and size
+func (je *Events) LogResponseEvent(address string, statusCode int, size int) {
+	je.write(address, events.EventInstance{
+		EventType: events.Response,
+		Success:   statusCode >= 200 && statusCode < 300,
+		Value:     statusCode,
+		Size:      size,
+		Time:      time.Now(),
+	})
+}
+
+// LogSchemeRetryEvent streams a SchemeRetry event
+func (je *Events) LogSchemeRetryEvent(address string) {
+	je.write(address, events.EventInstance{EventType: events.SchemeRetry, Time: time.Now()})
+}
+
+// GetReport returns no events since they are streamed, use ReadEvents to load them back
+func (je *Events) GetReport() map[string][]events.EventInstance {
+	return map[string][]events.EventInstance{}
+}
+
+// IsAlreadyDiscovered informs if an address was already discovered
+func (je *Events) IsAlreadyDiscovered(address string) bool {
+	je.Lock()
+	defer je.Unlock()
+	_, ok := je.discovered[address]
+	return !ok
+}
+
+// Err returns the first error found while streaming events
+func (je *Events) Err() error {
+	je.Lock()
+	defer je.Unlock()
+	return je.err
+}
+
+// ReadEvents loads events streamed as JSON lines back into a report like the one from GetReport
+func ReadEvents(r io.Reader) (map[string][]events.EventInstance, error) {
+	report := map[string][]events.EventInstance{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var line Line
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, err
+		}
+		eventType, err := events.ParseEventType(line.Stage)
+		if err != nil {
+			return nil, err
+		}
+		report[line.URL] = append(report[line.URL], events.EventInstance{
+			EventType: eventType,
+			Success:   line.Success,
+			Value:     line.Value,
+			Reason:    line.Reason,
+			Size:      line.Size,
+			Time:      line.Timestamp,
+		})
+	}
+	return report, scanner.Err()
+}
@@ -0,0 +1,90 @@
+package jsonl_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/thiagolcmelo/webcrawler/src/events"
+	"github.com/thiagolcmelo/webcrawler/src/jsonl"
+)
+
+func TestJSONLEvents_Stream(t *testing.T) {
+	var buf bytes.Buffer
+	je := jsonl.NewEvents(&buf)
+	je.LogDiscoveryEvent("url1", true)
+	je.LogResponseEvent("url1", 200, 42)
+	je.LogDownloadEvent("url1", true)
+	je.LogParseEvent("url1", true, 3)
+	je.LogStoreEvent("url1", true)
+	je.LogDispatchEvent("url1", true, 2)
+	je.LogDuplicateEvent("url2")
+	je.LogErrorEvent("url2", "repeated content")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expectedStages := []string{"discovery", "response", "download", "parse", "store", "dispatch", "duplicate", "error"}
+	if len(lines) != len(expectedStages) {
+		t.Fatalf("expected %d lines, got %d", len(expectedStages), len(lines))
+	}
+
+	for i, line := range lines {
+		var actual map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &actual); err != nil {
+			t.Fatal(err)
+		}
+		if actual["stage"] != expectedStages[i] {
+			t.Errorf("expected stage %s, got %v", expectedStages[i], actual["stage"])
+		}
+		for _, key := range []string{"url", "success", "value", "timestamp"} {
+			if _, ok := actual[key]; !ok {
+				t.Errorf("missing %s in %s", key, line)
+			}
+		}
+	}
+
+	if err := je.Err(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestJSONLEvents_IsAlreadyDiscovered(t *testing.T) {
+	je := jsonl.NewEvents(&bytes.Buffer{})
+	je.LogDownloadEvent("url1", true)
+	je.LogDiscoveryEvent("url2", true)
+
+	if !je.IsAlreadyDiscovered("url1") {
+		t.Errorf("url1 should be downloaded since it was not discovered")
+	}
+	if je.IsAlreadyDiscovered("url2") {
+		t.Errorf("url2 should not be downloaded since it was discovered")
+	}
+	if len(je.GetReport()) != 0 {
+		t.Errorf("streamed events should not be kept")
+	}
+}
+
+func TestJSONLEvents_ReadEvents(t *testing.T) {
+	var buf bytes.Buffer
+	je := jsonl.NewEvents(&buf)
+	je.LogDiscoveryEvent("url1", true)
+	je.LogParseEvent("url1", true, 3)
+	je.LogErrorEvent("url2", "repeated url")
+
+	report, err := jsonl.ReadEvents(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report["url1"]) != 2 || report["url1"][1].EventType != events.Parse || report["url1"][1].Value != 3 {
+		t.Errorf("unexpected events for url1 %#v", report["url1"])
+	}
+	if len(report["url2"]) != 1 || report["url2"][0].Reason != "repeated url" {
+		t.Errorf("unexpected events for url2 %#v", report["url2"])
+	}
+
+	_, err = jsonl.ReadEvents(strings.NewReader(`{"url":"url1","stage":"unknown"}`))
+	if err == nil {
+		t.Errorf("expected error for unknown stage")
+	}
+}
@@ -88,6 +88,54 @@ func (ms *Events) LogDispatchEvent(address string, success bool, children int) {
 	})
 }
 
+// LogDuplicateEvent adds a Duplicate event to memory
+func (ms *Events) LogDuplicateEvent(address string) {
+	ms.Lock()
+	defer ms.Unlock()
+	ms.addAddressIfNeeded(address)
+	ms.events[address] = append(ms.events[address], events.EventInstance{
+		EventType: events.Duplicate,
+		Time:      time.Now(),
+	})
+}
+
+// LogErrorEvent adds an Error event to memory along with its reason
+func (ms *Events) LogErrorEvent(address string, reason string) {
+	ms.Lock()
+	defer ms.Unlock()
+	ms.addAddressIfNeeded(address)
+	ms.events[address] = append(ms.events[address], events.EventInstance{
+		EventType: events.Error,
+		Reason:    reason,
+		Time:      time.Now(),
+	})
+}
+
+// LogResponseEvent adds a Response event to memory with its status code and size
+func (ms *Events) LogResponseEvent(address string, statusCode int, size int) {
+	ms.Lock()
+	defer ms.Unlock()
+	ms.addAddressIfNeeded(address)
+	ms.events[address] = append(ms.events[address], events.EventInstance{
+		EventType: events.Response,
+		Success:   statusCode >= 200 && statusCode < 300,
+		Value:     statusCode,
+		Size:      size,
+		Time:      time.Now(),
+	})
+}
+
+// LogSchemeRetryEvent adds a SchemeRetry event to memory
+func (ms *Events) LogSchemeRetryEvent(address string) {
+	ms.Lock()
+	defer ms.Unlock()
+	ms.addAddressIfNeeded(address)
+	ms.events[address] = append(ms.events[address], events.EventInstance{
+		EventType: events.SchemeRetry,
+		Time:      time.Now(),
+	})
+}
+
 // IsAlreadyDiscovered informs if an address was already discovered
 func (ms *Events) IsAlreadyDiscovered(address string) bool {
 	ms.Lock()
@@ -105,9 +153,14 @@ func (ms *Events) IsAlreadyDiscovered(address string) bool {
 	return true
 }
 
-// GetReport simply return all events
+// GetReport returns a copy of all events, so it can be read while urls still in flight keep
+// logging theirs
 func (ms *Events) GetReport() map[string][]events.EventInstance {
-	ms.Lock()
-	defer ms.Unlock()
-	return ms.events
+	ms.RLock()
+	defer ms.RUnlock()
+	report := make(map[string][]events.EventInstance, len(ms.events))
+	for address, instances := range ms.events {
+		report[address] = append([]events.EventInstance{}, instances...)
+	}
+	return report
 }
@@ -3,6 +3,7 @@ package memory_test
 import (
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/thiagolcmelo/webcrawler/src/events"
 	"github.com/thiagolcmelo/webcrawler/src/memory"
 )
@@ -121,6 +122,46 @@ func TestMemoryEvents_LogDispatchEvent(t *testing.T) {
 	assertEventInMemoryEvents(t, me, "url3", events.Dispatch, false, 0, 2)
 }
 
+func TestMemoryEvents_LogDuplicateEvent(t *testing.T) {
+	me := memory.NewEvents()
+	me.LogDuplicateEvent("url1")
+	me.LogDuplicateEvent("url1")
+	me.LogDuplicateEvent("url2")
+
+	assertEventInMemoryEvents(t, me, "url1", events.Duplicate, false, 0, 2)
+	assertEventInMemoryEvents(t, me, "url2", events.Duplicate, false, 0, 1)
+}
+
+func TestMemoryEvents_LogErrorEvent(t *testing.T) {
+	me := memory.NewEvents()
+	me.LogErrorEvent("url1", "reason1")
+	me.LogErrorEvent("url1", "reason2")
+
+	assertEventInMemoryEvents(t, me, "url1", events.Error, false, 0, 2)
+
+	reasons := []string{}
+	for _, eventInstance := range me.GetReport()["url1"] {
+		reasons = append(reasons, eventInstance.Reason)
+	}
+	if diff := cmp.Diff(reasons, []string{"reason1", "reason2"}); diff != "" {
+		t.Errorf("unexpected reasons %v", reasons)
+	}
+}
+
+func TestMemoryEvents_LogResponseEvent(t *testing.T) {
+	me := memory.NewEvents()
+	me.LogResponseEvent("url1", 200, 10)
+	me.LogResponseEvent("url2", 404, 0)
+	me.LogResponseEvent("url2", 404, 0)
+
+	assertEventInMemoryEvents(t, me, "url1", events.Response, true, 200, 1)
+	assertEventInMemoryEvents(t, me, "url2", events.Response, false, 404, 2)
+
+	if size := me.GetReport()["url1"][0].Size; size != 10 {
+		t.Errorf("expected size 10, got %d", size)
+	}
+}
+
 func TestMemoryEvents_GetReport(t *testing.T) {
 	me := memory.NewEvents()
 	me.LogDiscoveryEvent("url1", true)
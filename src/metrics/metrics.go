@@ -0,0 +1,268 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thiagolcmelo/webcrawler/src/events"
+	"github.com/thiagolcmelo/webcrawler/src/progress"
+)
+
+var (
+	// durationBuckets are the upper bounds in seconds for stage durations
+	durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	// linkBuckets are the upper bounds for the number of links found or dispatched per page
+	linkBuckets = []float64{0, 1, 2, 5, 10, 25, 50, 100, 250, 500}
+)
+
+// histogram is a cumulative histogram in the Prometheus sense
+type histogram struct {
+	buckets []float64
+	counts  []int
+	sum     float64
+	count   int
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{
+		buckets: buckets,
+		counts:  make([]int, len(buckets)),
+	}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, upperBound := range h.buckets {
+		if v <= upperBound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// Events decorates a progress.Counter exposing every event logged as Prometheus metrics,
+// the counter provides the frontier depth and the active workers
+type Events struct {
+	*progress.Counter
+	stageEvents    map[string]map[bool]int
+	stageDurations map[string]*histogram
+	parseLinks     *histogram
+	dispatchURLs   *histogram
+	statusClasses  map[string]int
+	bytes          int
+	duplicates     int
+	errors         map[string]int
+	lastEvent      map[string]time.Time
+	sync.Mutex
+}
+
+// NewEvents is a factory for metrics Events
+func NewEvents(counter *progress.Counter) *Events {
+	stageDurations := map[string]*histogram{}
+	for _, stage := range []events.EventType{events.Download, events.Parse, events.Store, events.Dispatch} {
+		stageDurations[stage.String()] = newHistogram(durationBuckets)
+	}
+	return &Events{
+		Counter:        counter,
+		stageEvents:    map[string]map[bool]int{},
+		stageDurations: stageDurations,
+		parseLinks:     newHistogram(linkBuckets),
+		dispatchURLs:   newHistogram(linkBuckets),
+		statusClasses:  map[string]int{},
+		errors:         map[string]int{},
+		lastEvent:      map[string]time.Time{},
+	}
+}
+
+func (me *Events) countStage(stage events.EventType, success bool) {
+	if _, ok := me.stageEvents[stage.String()]; !ok {
+		me.stageEvents[stage.String()] = map[bool]int{}
+	}
+	me.stageEvents[stage.String()][success]++
+}
+
+// observeStage counts a stage event and how long it took since the previous stage of the same url
+func (me *Events) observeStage(address string, stage events.EventType, success bool) {
+	me.Lock()
+	defer me.Unlock()
+	me.countStage(stage, success)
+
+	// a failed discovery means the url is processed elsewhere, its timing is left alone
+	if stage == events.Discovery && !success {
+		return
+	}
+
+	now := time.Now()
+	if previous, ok := me.lastEvent[address]; ok {
+		if h, ok := me.stageDurations[stage.String()]; ok {
+			h.observe(now.Sub(previous).Seconds())
+		}
+	}
+	me.lastEvent[address] = now
+}
+
+// LogDiscoveryEvent counts a discovery, successful ones start timing the url
+func (me *Events) LogDiscoveryEvent(address string, success bool) {
+	me.observeStage(address, events.Discovery, success)
+	me.Counter.LogDiscoveryEvent(address, success)
+}
+
+// LogDownloadEvent counts a download and observes its duration
+func (me *Events) LogDownloadEvent(address string, success bool) {
+	me.observeStage(address, events.Download, success)
+	me.Counter.LogDownloadEvent(address, success)
+}
+
+// LogParseEvent counts a parse, observing its duration and the links found
+func (me *Events) LogParseEvent(address string, success bool, children int) {
+	me.observeStage(address, events.Parse, success)
+	if success {
+		me.Lock()
+		me.parseLinks.observe(float64(children))
+		me.Unlock()
+	}
+	me.Counter.LogParseEvent(address, success, children)
+}
+
+// LogStoreEvent counts a store and observes its duration
+func (me *Events) LogStoreEvent(address string, success bool) {
+	me.observeStage(address, events.Store, success)
+	me.Counter.LogStoreEvent(address, success)
+}
+
+// LogDispatchEvent counts a dispatch, observing its duration and the urls dispatched
+func (me *Events) LogDispatchEvent(address string, success bool, children int) {
+	me.observeStage(address, events.Dispatch, success)
+	me.Lock()
+	if success {
+		me.dispatchURLs.observe(float64(children))
+	}
+	// the url is done, there is no next stage to time
+	delete(me.lastEvent, address)
+	me.Unlock()
+	me.Counter.LogDispatchEvent(address, success, children)
+}
+
+// LogDuplicateEvent counts a duplicate
+func (me *Events) LogDuplicateEvent(address string) {
+	me.Lock()
+	me.duplicates++
+	// the url is skipped, there is no next stage to time
+	delete(me.lastEvent, address)
+	me.Unlock()
+	me.Counter.LogDuplicateEvent(address)
+}
+
+// LogErrorEvent counts an error by its reason
+func (me *Events) LogErrorEvent(address string, reason string) {
+	me.Lock()
+	me.errors[reason]++
+	delete(me.lastEvent, address)
+	me.Unlock()
+	me.Counter.LogErrorEvent(address, reason)
+}
+
+// LogResponseEvent counts a response by its status class and its bytes
+func (me *Events) LogResponseEvent(address string, statusCode int, size int) {
+	me.Lock()
+	me.statusClasses[fmt.Sprintf("%dxx", statusCode/100)]++
+	me.bytes += size
+	me.Unlock()
+	me.Counter.LogResponseEvent(address, statusCode, size)
+}
+
+// ServeHTTP writes all metrics in the Prometheus text format
+func (me *Events) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := me.WriteMetrics(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// WriteMetrics writes all metrics in the Prometheus text format to the provided writer
+func (me *Events) WriteMetrics(w io.Writer) error {
+	snapshot := me.Counter.Snapshot()
+
+	me.Lock()
+	defer me.Unlock()
+
+	var b strings.Builder
+
+	writeHeader(&b, "webcrawler_stage_events_total", "counter", "Pipeline stage events by stage and outcome.")
+	for _, stage := range sortedKeys(me.stageEvents) {
+		for _, success := range []bool{true, false} {
+			outcome := "failure"
+			if success {
+				outcome = "success"
+			}
+			fmt.Fprintf(&b, "webcrawler_stage_events_total{stage=%q,outcome=%q} %d\n", stage, outcome, me.stageEvents[stage][success])
+		}
+	}
+
+	writeHeader(&b, "webcrawler_stage_duration_seconds", "histogram", "Time spent by a url in a pipeline stage.")
+	for _, stage := range sortedKeys(me.stageDurations) {
+		writeHistogram(&b, "webcrawler_stage_duration_seconds", fmt.Sprintf("stage=%q,", stage), me.stageDurations[stage])
+	}
+
+	writeHeader(&b, "webcrawler_parse_links", "histogram", "Links found per parsed page.")
+	writeHistogram(&b, "webcrawler_parse_links", "", me.parseLinks)
+
+	writeHeader(&b, "webcrawler_dispatch_urls", "histogram", "New urls dispatched per page.")
+	writeHistogram(&b, "webcrawler_dispatch_urls", "", me.dispatchURLs)
+
+	writeHeader(&b, "webcrawler_http_responses_total", "counter", "HTTP responses by status class.")
+	for _, class := range sortedKeys(me.statusClasses) {
+		fmt.Fprintf(&b, "webcrawler_http_responses_total{class=%q} %d\n", class, me.statusClasses[class])
+	}
+
+	writeHeader(&b, "webcrawler_downloaded_bytes_total", "counter", "Bytes downloaded.")
+	fmt.Fprintf(&b, "webcrawler_downloaded_bytes_total %d\n", me.bytes)
+
+	writeHeader(&b, "webcrawler_duplicates_total", "counter", "Urls skipped because their content was already seen.")
+	fmt.Fprintf(&b, "webcrawler_duplicates_total %d\n", me.duplicates)
+
+	writeHeader(&b, "webcrawler_errors_total", "counter", "Urls that stopped being processed by reason.")
+	for _, reason := range sortedKeys(me.errors) {
+		fmt.Fprintf(&b, "webcrawler_errors_total{reason=%q} %d\n", reason, me.errors[reason])
+	}
+
+	writeHeader(&b, "webcrawler_frontier_depth", "gauge", "Urls waiting in the frontier.")
+	fmt.Fprintf(&b, "webcrawler_frontier_depth %d\n", snapshot.Queued)
+
+	writeHeader(&b, "webcrawler_active_workers", "gauge", "Urls being processed.")
+	fmt.Fprintf(&b, "webcrawler_active_workers %d\n", snapshot.InFlight)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeHeader(b *strings.Builder, name string, kind string, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeHistogram(b *strings.Builder, name string, labels string, h *histogram) {
+	for i, upperBound := range h.buckets {
+		fmt.Fprintf(b, "%s_bucket{%sle=\"%g\"} %d\n", name, labels, upperBound, h.counts[i])
+	}
+	fmt.Fprintf(b, "%s_bucket{%sle=\"+Inf\"} %d\n", name, labels, h.count)
+	labels = strings.TrimSuffix(labels, ",")
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	fmt.Fprintf(b, "%s_sum%s %g\n", name, labels, h.sum)
+	fmt.Fprintf(b, "%s_count%s %d\n", name, labels, h.count)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
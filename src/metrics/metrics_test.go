@@ -0,0 +1,74 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/thiagolcmelo/webcrawler/src/memory"
+	"github.com/thiagolcmelo/webcrawler/src/metrics"
+	"github.com/thiagolcmelo/webcrawler/src/progress"
+)
+
+func TestMetricsEvents_ServeHTTP(t *testing.T) {
+	me := metrics.NewEvents(progress.NewCounter(memory.NewEvents(), 1))
+	me.LogDiscoveryEvent("url1", true)
+	me.LogResponseEvent("url1", 200, 100)
+	me.LogDownloadEvent("url1", true)
+	me.LogParseEvent("url1", true, 3)
+	me.LogStoreEvent("url1", true)
+	me.LogDispatchEvent("url1", true, 2)
+	me.LogDiscoveryEvent("url2", true)
+	me.LogResponseEvent("url2", 404, 20)
+	me.LogDownloadEvent("url2", false)
+	me.LogErrorEvent("url2", "response status not 200")
+	me.LogDiscoveryEvent("url1", false)
+	me.LogErrorEvent("url1", "repeated url")
+
+	server := httptest.NewServer(me)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedLines := []string{
+		"# TYPE webcrawler_stage_events_total counter",
+		`webcrawler_stage_events_total{stage="discovery",outcome="success"} 2`,
+		`webcrawler_stage_events_total{stage="discovery",outcome="failure"} 1`,
+		`webcrawler_stage_events_total{stage="download",outcome="failure"} 1`,
+		`webcrawler_stage_duration_seconds_count{stage="download"} 2`,
+		`webcrawler_stage_duration_seconds_bucket{stage="store",le="+Inf"} 1`,
+		`webcrawler_parse_links_bucket{le="5"} 1`,
+		`webcrawler_dispatch_urls_sum 2`,
+		`webcrawler_http_responses_total{class="2xx"} 1`,
+		`webcrawler_http_responses_total{class="4xx"} 1`,
+		"webcrawler_downloaded_bytes_total 120",
+		`webcrawler_errors_total{reason="repeated url"} 1`,
+		"webcrawler_frontier_depth 0",
+		"webcrawler_active_workers 0",
+	}
+
+	lines := strings.Split(string(body), "\n")
+	for _, expected := range expectedLines {
+		found := false
+		for _, line := range lines {
+			if line == expected {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("expected line %q in:\n%s", expected, body)
+		}
+	}
+}
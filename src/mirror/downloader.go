@@ -0,0 +1,72 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/thiagolcmelo/webcrawler/src/basic"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+)
+
+// Downloader downloads the urls of a mirror with conditional requests, sending the validators
+// recorded for the files already mirrored, a url not modified since gets back the body it was
+// mirrored from instead of being downloaded again
+type Downloader struct {
+	mirror     *Mirror
+	downloader *basic.Downloader
+}
+
+// NewDownloader is a factory for Downloader, the mirror must not be updated while it is in use
+func NewDownloader(m *Mirror, retries int, backoff time.Duration, backoffMultiplier int) *Downloader {
+	return &Downloader{
+		mirror:     m,
+		downloader: basic.NewDownloader(retries, backoff, backoffMultiplier),
+	}
+}
+
+// Download downloads a url, when it was not modified since it was mirrored the content is
+// filled from the mirror and keeps the 304 status
+func (md *Downloader) Download(ctx context.Context, c *content.Content) error {
+	file, ok := md.mirror.manifest.Files[c.Address]
+	if !ok || (file.ETag == "" && file.LastModified == "") {
+		return md.downloader.Download(ctx, c)
+	}
+
+	header := http.Header{}
+	if file.ETag != "" {
+		header.Set("If-None-Match", file.ETag)
+	}
+	if file.LastModified != "" {
+		header.Set("If-Modified-Since", file.LastModified)
+	}
+	err := md.downloader.DownloadWithHeader(ctx, c, header)
+	if !errors.Is(err, basic.ErrNotModified) {
+		return err
+	}
+
+	source := file.Path
+	if file.Source != "" {
+		source = file.Source
+	}
+	body, err := md.mirror.read(source)
+	if err != nil {
+		// without the body it was mirrored from the url is downloaded again
+		log.Printf("could not read the mirrored body of url [%s]: %v", c.Address, err)
+		return md.downloader.Download(ctx, c)
+	}
+
+	c.Body = body
+	c.ContentType = file.ContentType
+	// a 304 may leave the validators out, the ones recorded still hold
+	if c.Header.Get("ETag") == "" && file.ETag != "" {
+		c.Header.Set("ETag", file.ETag)
+	}
+	if c.Header.Get("Last-Modified") == "" && file.LastModified != "" {
+		c.Header.Set("Last-Modified", file.LastModified)
+	}
+	c.CreateChecksum()
+	return nil
+}
@@ -0,0 +1,135 @@
+package mirror_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/mirror"
+)
+
+func TestDownloader_Download(t *testing.T) {
+	var mu sync.Mutex
+	style := "body { color: red }"
+	modified := time.Date(2023, 5, 14, 12, 0, 0, 0, time.UTC)
+	served := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/":
+			// the page is validated by its etag
+			w.Header().Set("ETag", `"home"`)
+			if r.Header.Get("If-None-Match") == `"home"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<link rel="stylesheet" href="/style.css">`))
+		case "/style.css":
+			// the stylesheet is validated by its modification date
+			w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+			if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !modified.After(since) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Content-Type", "text/css")
+			w.Write([]byte(style))
+		}
+		served[r.URL.Path]++
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	update := func() ([]int, mirror.Summary) {
+		m, err := mirror.Open(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		downloader := mirror.NewDownloader(m, 1, time.Millisecond, 1)
+		contents := []content.Content{}
+		statuses := []int{}
+		for _, path := range []string{"/", "/style.css"} {
+			c, err := content.NewContent(fmt.Sprintf("%s%s", server.URL, path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := downloader.Download(context.Background(), &c); err != nil {
+				t.Fatal(err)
+			}
+			contents = append(contents, c)
+			statuses = append(statuses, c.StatusCode)
+		}
+		summary, err := m.Update(contents, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return statuses, summary
+	}
+
+	type testCase struct {
+		testName         string
+		change           func()
+		expectedStatuses []int
+		expectedSummary  mirror.Summary
+	}
+
+	testCases := []testCase{
+		{
+			testName:         "first_update_downloads_everything",
+			expectedStatuses: []int{http.StatusOK, http.StatusOK},
+			expectedSummary:  mirror.Summary{Added: 2},
+		},
+		{
+			testName:         "nothing_modified",
+			expectedStatuses: []int{http.StatusNotModified, http.StatusNotModified},
+			expectedSummary:  mirror.Summary{Unchanged: 2},
+		},
+		{
+			testName: "stylesheet_modified",
+			change: func() {
+				mu.Lock()
+				defer mu.Unlock()
+				style = "body { color: blue }"
+				modified = modified.Add(time.Hour)
+			},
+			expectedStatuses: []int{http.StatusNotModified, http.StatusOK},
+			expectedSummary:  mirror.Summary{Updated: 1, Unchanged: 1},
+		},
+	}
+
+	// the cases run in order, each one updating the mirror left by the previous one
+	for _, tc := range testCases {
+		if tc.change != nil {
+			tc.change()
+		}
+		statuses, summary := update()
+		if diff := cmp.Diff(tc.expectedStatuses, statuses); diff != "" {
+			t.Errorf("%s: unexpected statuses (-expected +actual):\n%s", tc.testName, diff)
+		}
+		if diff := cmp.Diff(tc.expectedSummary, summary); diff != "" {
+			t.Errorf("%s: unexpected summary (-expected +actual):\n%s", tc.testName, diff)
+		}
+	}
+
+	// bodies are sent only when they changed
+	if diff := cmp.Diff(map[string]int{"/": 1, "/style.css": 2}, served); diff != "" {
+		t.Errorf("unexpected bodies served (-expected +actual):\n%s", diff)
+	}
+	expected := map[string]string{
+		"index.html": `<link rel="stylesheet" href="style.css">`,
+		"style.css":  "body { color: blue }",
+	}
+	for name, body := range expected {
+		if diff := cmp.Diff(body, readFile(t, filepath.Join(dir, name))); diff != "" {
+			t.Errorf("unexpected %s (-expected +actual):\n%s", name, diff)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Package mirror saves crawled pages to a local directory that can be browsed offline
+package mirror
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/thiagolcmelo/webcrawler/src/basic"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+)
+
+// ManifestName is the file, in the mirror directory, that records what was mirrored
+const ManifestName = ".webcrawler-mirror.json"
+
+// SourcesDir is the directory, in the mirror directory, keeping the bodies of the files whose
+// links were rewritten as they were downloaded, so a url not modified can be parsed again
+const SourcesDir = ".webcrawler-sources"
+
+// ErrInvalidManifest should be used when the manifest of an existing mirror cannot be read
+var ErrInvalidManifest = errors.New("invalid mirror manifest")
+
+// File is a mirrored url, its path is relative to the mirror directory and its checksum is
+// the one of the file as written, after links are rewritten, the source is the body it was
+// written from when it differs, and the validators are sent to ask whether the url changed
+type File struct {
+	Path         string `json:"path"`
+	Checksum     string `json:"sha256"`
+	Source       string `json:"source,omitempty"`
+	ContentType  string `json:"contentType,omitempty"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// Manifest maps every mirrored url to its file
+type Manifest struct {
+	Files map[string]File `json:"files"`
+}
+
+// Summary counts what an update did to the mirror
+type Summary struct {
+	Added     int `json:"added"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Removed   int `json:"removed"`
+}
+
+// String describes the summary in a single line
+func (s Summary) String() string {
+	return fmt.Sprintf("%d added, %d updated, %d unchanged, %d removed", s.Added, s.Updated, s.Unchanged, s.Removed)
+}
+
+// Mirror is a local directory holding a copy of the crawled pages
+type Mirror struct {
+	dir      string
+	manifest Manifest
+}
+
+// Open creates the mirror directory or opens an existing mirror so it can be updated
+func Open(dir string) (*Mirror, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	m := &Mirror{dir: dir, manifest: Manifest{Files: map[string]File{}}}
+
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &m.manifest); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	if m.manifest.Files == nil {
+		m.manifest.Files = map[string]File{}
+	}
+	return m, nil
+}
+
+// Update writes the contents with their links rewritten, files whose bytes did not change are
+// left untouched and, when prune is set, files of urls no longer crawled are removed, the
+// validators of every url are recorded for the next update
+func (m *Mirror) Update(contents []content.Content, prune bool) (Summary, error) {
+	summary := Summary{}
+
+	// every path is known before writing so links to any content can be rewritten
+	paths := map[string]string{}
+	sort.Slice(contents, func(i, j int) bool { return contents[i].Address < contents[j].Address })
+	for i := range contents {
+		p, err := Path(contents[i].Address, basic.MediaType(&contents[i]))
+		if err != nil {
+			return summary, err
+		}
+		paths[contents[i].Address] = p
+	}
+
+	for i := range contents {
+		c := &contents[i]
+		body := c.Body
+		rw := rewriter{c: c, file: paths[c.Address], paths: paths}
+		source := ""
+		switch basic.MediaType(c) {
+		case "text/html", "application/xhtml+xml":
+			body = rw.html(c.Body)
+			source = sourcePath(c.Address)
+		case "text/css":
+			body = rw.css(c.Body)
+			source = sourcePath(c.Address)
+		}
+
+		sum := sha256.Sum256(body)
+		file := File{
+			Path:         paths[c.Address],
+			Checksum:     hex.EncodeToString(sum[:]),
+			Source:       source,
+			ContentType:  c.ContentType,
+			ETag:         c.Header.Get("ETag"),
+			LastModified: c.Header.Get("Last-Modified"),
+		}
+		previous, known := m.manifest.Files[c.Address]
+		if source != "" {
+			if err := m.keep(source, c.Body); err != nil {
+				return summary, err
+			}
+		} else if known && previous.Source != "" {
+			m.remove(previous.Source)
+		}
+		if known && previous.Path == file.Path && previous.Checksum == file.Checksum && m.exists(file.Path) {
+			// the validators may have changed even if the bytes did not
+			m.manifest.Files[c.Address] = file
+			summary.Unchanged++
+			continue
+		}
+		if err := m.write(file.Path, body); err != nil {
+			return summary, err
+		}
+		if known && previous.Path != file.Path {
+			m.remove(previous.Path)
+		}
+		m.manifest.Files[c.Address] = file
+		if known {
+			summary.Updated++
+		} else {
+			summary.Added++
+		}
+	}
+
+	if prune {
+		for address, file := range m.manifest.Files {
+			if _, ok := paths[address]; ok {
+				continue
+			}
+			m.remove(file.Path)
+			if file.Source != "" {
+				m.remove(file.Source)
+			}
+			delete(m.manifest.Files, address)
+			summary.Removed++
+		}
+	}
+
+	return summary, m.saveManifest()
+}
+
+// exists informs if a file of the mirror is on disk
+func (m *Mirror) exists(p string) bool {
+	_, err := os.Stat(filepath.Join(m.dir, p))
+	return err == nil
+}
+
+// write writes a file of the mirror, creating its directories
+func (m *Mirror) write(p string, data []byte) error {
+	full := filepath.Join(m.dir, p)
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(full, data, 0644)
+}
+
+// keep writes a file of the mirror unless it already holds the data
+func (m *Mirror) keep(p string, data []byte) error {
+	if current, err := os.ReadFile(filepath.Join(m.dir, p)); err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+	return m.write(p, data)
+}
+
+// read reads a file of the mirror
+func (m *Mirror) read(p string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(m.dir, p))
+}
+
+// sourcePath is where the source of a url is kept, relative to the mirror directory
+func sourcePath(address string) string {
+	sum := sha256.Sum256([]byte(address))
+	return filepath.Join(SourcesDir, hex.EncodeToString(sum[:]))
+}
+
+// remove removes a file of the mirror, a file already gone is not an error
+func (m *Mirror) remove(p string) {
+	if err := os.Remove(filepath.Join(m.dir, p)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("could not remove [%s] from the mirror: %v", p, err)
+	}
+}
+
+// saveManifest writes the manifest, sorted by url
+func (m *Mirror) saveManifest() error {
+	data, err := json.MarshalIndent(m.manifest, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.dir, ManifestName), data, 0644)
+}
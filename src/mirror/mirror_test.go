@@ -0,0 +1,116 @@
+package mirror_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/mirror"
+)
+
+func newContent(t *testing.T, address string, contentType string, body string) content.Content {
+	c, err := content.NewContentWithBody(address, []byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.ContentType = contentType
+	return c
+}
+
+func readFile(t *testing.T, name string) string {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestMirror_Update(t *testing.T) {
+	dir := t.TempDir()
+	contents := []content.Content{
+		newContent(t, "http://domain.com/", "text/html", `<link rel="stylesheet" href="/css/site.css"><style>p{background:url('/bg.png')}</style>`+
+			`<a href="/docs/guide#intro">guide</a> <a href="/missing">missing</a> <a href="http://other.com/">other</a> <a href="#top">top</a>`),
+		newContent(t, "http://domain.com/docs/guide", "text/html; charset=utf-8", `<a href="../">home</a><img src="/bg.png" style="background: url(/bg.png)">`),
+		newContent(t, "http://domain.com/css/site.css", "text/css", `@import "base.css"; body { background: url("../bg.png") }`),
+		newContent(t, "http://domain.com/bg.png", "image/png", "\x89PNG"),
+	}
+
+	m, err := mirror.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := m.Update(contents, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(mirror.Summary{Added: 4}, summary); diff != "" {
+		t.Errorf("unexpected summary (-expected +actual):\n%s", diff)
+	}
+
+	expected := map[string]string{
+		"index.html": `<link rel="stylesheet" href="css/site.css"><style>p{background:url('bg.png')}</style>` +
+			`<a href="docs/guide.html#intro">guide</a> <a href="http://domain.com/missing">missing</a> <a href="http://other.com/">other</a> <a href="#top">top</a>`,
+		"docs/guide.html": `<a href="../index.html">home</a><img src="../bg.png" style="background: url(../bg.png)">`,
+		"css/site.css":    `@import "http://domain.com/css/base.css"; body { background: url("../bg.png") }`,
+		"bg.png":          "\x89PNG",
+	}
+	for name, body := range expected {
+		if diff := cmp.Diff(body, readFile(t, filepath.Join(dir, filepath.FromSlash(name)))); diff != "" {
+			t.Errorf("unexpected %s (-expected +actual):\n%s", name, diff)
+		}
+	}
+
+	// the image changes and the guide is gone
+	contents = []content.Content{contents[0], contents[2], newContent(t, "http://domain.com/bg.png", "image/png", "\x89PNG2")}
+	m, err = mirror.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err = m.Update(contents, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(mirror.Summary{Updated: 2, Unchanged: 1, Removed: 1}, summary); diff != "" {
+		t.Errorf("unexpected summary (-expected +actual):\n%s", diff)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "docs", "guide.html")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the guide to be removed, got %v", err)
+	}
+	if actual := readFile(t, filepath.Join(dir, "index.html")); actual == expected["index.html"] {
+		t.Errorf("expected the link to the guide to become absolute")
+	}
+}
+
+func TestMirror_UpdateWithoutPrune(t *testing.T) {
+	dir := t.TempDir()
+	m, err := mirror.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Update([]content.Content{newContent(t, "http://domain.com/old", "text/plain", "old")}, false); err != nil {
+		t.Fatal(err)
+	}
+	summary, err := m.Update([]content.Content{newContent(t, "http://domain.com/new", "text/plain", "new")}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(mirror.Summary{Added: 1}, summary); diff != "" {
+		t.Errorf("unexpected summary (-expected +actual):\n%s", diff)
+	}
+	if actual := readFile(t, filepath.Join(dir, "old.txt")); actual != "old" {
+		t.Errorf("expected the old file to be kept, got %s", actual)
+	}
+}
+
+func TestOpen_InvalidManifest(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, mirror.ManifestName), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mirror.Open(dir); !errors.Is(err, mirror.ErrInvalidManifest) {
+		t.Errorf("expected %v, got %v", mirror.ErrInvalidManifest, err)
+	}
+}
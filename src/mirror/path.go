@@ -0,0 +1,96 @@
+package mirror
+
+import (
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// extensions maps media types to the extension their files are saved with
+var extensions = map[string]string{
+	"text/html":                ".html",
+	"application/xhtml+xml":    ".html",
+	"text/css":                 ".css",
+	"text/javascript":          ".js",
+	"application/javascript":   ".js",
+	"application/json":         ".json",
+	"application/ld+json":      ".json",
+	"application/xml":          ".xml",
+	"text/xml":                 ".xml",
+	"application/rss+xml":      ".xml",
+	"application/atom+xml":     ".xml",
+	"text/plain":               ".txt",
+	"application/pdf":          ".pdf",
+	"image/png":                ".png",
+	"image/jpeg":               ".jpg",
+	"image/gif":                ".gif",
+	"image/webp":               ".webp",
+	"image/svg+xml":            ".svg",
+	"image/x-icon":             ".ico",
+	"image/vnd.microsoft.icon": ".ico",
+}
+
+// browsable lists the extensions a browser needs to open html and css files from disk
+var browsable = map[string][]string{
+	".html": {".html", ".htm"},
+	".css":  {".css"},
+}
+
+// Path maps a url to the file it is saved in, relative to the mirror directory: directories get an
+// index.html, queries are kept in the file name and html and css files get an extension browsers
+// recognize when opening them from disk
+func Path(address string, mediaType string) (string, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", err
+	}
+
+	p := u.Path
+	if p == "" || strings.HasSuffix(p, "/") {
+		p += "index.html"
+	}
+	p = path.Clean("/" + p)
+	dir, name := path.Split(p)
+	if name == "" {
+		name = "index.html"
+	}
+
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if expected, ok := extensions[mediaType]; ok {
+		if accepted, ok := browsable[expected]; ok && !contains(accepted, strings.ToLower(ext)) {
+			stem, ext = name, expected
+		} else if ext == "" {
+			ext = expected
+		}
+	}
+	if u.RawQuery != "" {
+		stem += "@" + sanitize(u.RawQuery)
+	}
+
+	return filepath.FromSlash(strings.TrimPrefix(dir+stem+ext, "/")), nil
+}
+
+// sanitize replaces the characters not safe in file names on every platform
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case strings.ContainsRune("._-=&,+", r):
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
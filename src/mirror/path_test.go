@@ -0,0 +1,45 @@
+package mirror_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/thiagolcmelo/webcrawler/src/mirror"
+)
+
+func TestPath(t *testing.T) {
+	type testCase struct {
+		testName     string
+		address      string
+		mediaType    string
+		expectedPath string
+	}
+
+	testCases := []testCase{
+		{testName: "root_is_an_index", address: "http://domain.com/", mediaType: "text/html", expectedPath: "index.html"},
+		{testName: "directory_is_an_index", address: "http://domain.com/docs/", mediaType: "text/html", expectedPath: "docs/index.html"},
+		{testName: "html_file_is_kept", address: "http://domain.com/about.htm", mediaType: "text/html", expectedPath: "about.htm"},
+		{testName: "html_without_extension", address: "http://domain.com/about", mediaType: "text/html", expectedPath: "about.html"},
+		{testName: "html_with_other_extension", address: "http://domain.com/page.php", mediaType: "text/html", expectedPath: "page.php.html"},
+		{testName: "css_without_extension", address: "http://domain.com/theme", mediaType: "text/css", expectedPath: "theme.css"},
+		{testName: "image_without_extension", address: "http://domain.com/logo", mediaType: "image/png", expectedPath: "logo.png"},
+		{testName: "image_with_extension", address: "http://domain.com/img/logo.jpeg", mediaType: "image/jpeg", expectedPath: "img/logo.jpeg"},
+		{testName: "unknown_type_is_kept", address: "http://domain.com/file.tar.gz", mediaType: "application/gzip", expectedPath: "file.tar.gz"},
+		{testName: "query_before_extension", address: "http://domain.com/style.css?v=2", mediaType: "text/css", expectedPath: "style@v=2.css"},
+		{testName: "query_is_sanitized", address: "http://domain.com/search?q=a/b%20c&page=2", mediaType: "text/html", expectedPath: "search@q=a_b_20c&page=2.html"},
+		{testName: "dot_segments_stay_inside", address: "http://domain.com/../../etc/passwd", mediaType: "text/plain", expectedPath: "etc/passwd.txt"},
+		{testName: "escaped_path", address: "http://domain.com/a%20b/", mediaType: "text/html", expectedPath: "a b/index.html"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			actual, err := mirror.Path(tc.address, tc.mediaType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := filepath.FromSlash(tc.expectedPath); actual != expected {
+				t.Errorf("expected %s, got %s", expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package mirror
+
+import (
+	"bytes"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"golang.org/x/net/html"
+)
+
+// cssLinkPattern matches the url() functions and the @import rules of a stylesheet
+var cssLinkPattern = regexp.MustCompile(`(url\(\s*)(?:"([^"]*)"|'([^']*)'|([^)\s'"]*))(\s*\))|(@import\s+)(?:"([^"]*)"|'([^']*)')`)
+
+// linkAttrs lists the attributes holding a link, by element
+var linkAttrs = map[string][]string{
+	"a":      {"href"},
+	"area":   {"href"},
+	"link":   {"href"},
+	"img":    {"src"},
+	"script": {"src"},
+	"iframe": {"src"},
+	"audio":  {"src"},
+	"video":  {"src", "poster"},
+	"source": {"src"},
+	"embed":  {"src"},
+	"input":  {"src"},
+}
+
+// rewriter turns the links of a content into paths relative to its file, links to urls
+// not mirrored in the same host become absolute so they still work from disk
+type rewriter struct {
+	c     *content.Content
+	file  string
+	paths map[string]string
+}
+
+// link rewrites a single link, links to other hosts, fragments and data urls are kept
+func (rw rewriter) link(link string) string {
+	trimmed := strings.TrimSpace(link)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return link
+	}
+	ref, err := url.Parse(trimmed)
+	if err != nil {
+		return link
+	}
+	target, err := content.NewContent(rw.c.Base().ResolveReference(ref).String())
+	if err != nil || target.Hostname() != rw.c.Hostname() || (target.Scheme != "http" && target.Scheme != "https") {
+		return link
+	}
+
+	file, ok := rw.paths[target.Address]
+	if !ok {
+		return rw.c.Base().ResolveReference(ref).String()
+	}
+	rel, err := filepath.Rel(filepath.Dir(rw.file), file)
+	if err != nil {
+		return link
+	}
+	local := (&url.URL{Path: filepath.ToSlash(rel), Fragment: ref.Fragment}).String()
+	return local
+}
+
+// html rewrites the link attributes, the inline styles and the style elements of a page,
+// everything else is copied as is
+func (rw rewriter) html(body []byte) []byte {
+	var out bytes.Buffer
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+	inStyle := false
+	for {
+		tt := tokenizer.Next()
+		switch tt {
+		case html.ErrorToken:
+			return out.Bytes()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			raw := append([]byte{}, tokenizer.Raw()...)
+			token := tokenizer.Token()
+			inStyle = token.Data == "style" && tt == html.StartTagToken
+			changed := false
+			for i, attr := range token.Attr {
+				value := attr.Val
+				if contains(linkAttrs[token.Data], attr.Key) {
+					value = rw.link(attr.Val)
+				} else if attr.Key == "style" {
+					value = string(rw.css([]byte(attr.Val)))
+				}
+				if value != attr.Val {
+					token.Attr[i].Val = value
+					changed = true
+				}
+			}
+			if changed {
+				out.WriteString(token.String())
+			} else {
+				out.Write(raw)
+			}
+		case html.TextToken:
+			if inStyle {
+				out.Write(rw.css(tokenizer.Raw()))
+			} else {
+				out.Write(tokenizer.Raw())
+			}
+		default:
+			inStyle = false
+			out.Write(tokenizer.Raw())
+		}
+	}
+}
+
+// css rewrites the url() functions and the @import rules of a stylesheet
+func (rw rewriter) css(body []byte) []byte {
+	return cssLinkPattern.ReplaceAllFunc(body, func(match []byte) []byte {
+		groups := cssLinkPattern.FindSubmatch(match)
+		if len(groups[1]) > 0 {
+			link, quote := firstGroup(groups[2:5], `"`, `'`, "")
+			return []byte(string(groups[1]) + quote + rw.link(link) + quote + string(groups[5]))
+		}
+		link, quote := firstGroup(groups[7:9], `"`, `'`)
+		return []byte(string(groups[6]) + quote + rw.link(link) + quote)
+	})
+}
+
+// firstGroup returns the first group matched and the quote around it
+func firstGroup(groups [][]byte, quotes ...string) (string, string) {
+	for i, group := range groups {
+		if group != nil {
+			return string(group), quotes[i]
+		}
+	}
+	return "", quotes[len(quotes)-1]
+}
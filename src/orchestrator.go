@@ -2,42 +2,89 @@ package src
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net/url"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/thiagolcmelo/webcrawler/src/analysis"
 	"github.com/thiagolcmelo/webcrawler/src/basic"
 	"github.com/thiagolcmelo/webcrawler/src/content"
 	"github.com/thiagolcmelo/webcrawler/src/dispatcher"
 	"github.com/thiagolcmelo/webcrawler/src/downloader"
 	"github.com/thiagolcmelo/webcrawler/src/events"
+	"github.com/thiagolcmelo/webcrawler/src/extractor"
 	"github.com/thiagolcmelo/webcrawler/src/frontier"
+	"github.com/thiagolcmelo/webcrawler/src/hooks"
+	"github.com/thiagolcmelo/webcrawler/src/memory"
 	"github.com/thiagolcmelo/webcrawler/src/parser"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+	"github.com/thiagolcmelo/webcrawler/src/scraper"
+	"github.com/thiagolcmelo/webcrawler/src/sitemap"
+	"github.com/thiagolcmelo/webcrawler/src/stats"
 	"github.com/thiagolcmelo/webcrawler/src/storage"
+	"github.com/thiagolcmelo/webcrawler/src/warc"
+)
+
+var (
+	// ErrMissingScheme should be used when a url has no scheme
+	ErrMissingScheme = errors.New("url missing scheme")
+	// ErrRepeatedURL should be used when a url was already discovered
+	ErrRepeatedURL = errors.New("repeated url")
+	// ErrRepeatedContent should be used when the content of a url was already seen
+	ErrRepeatedContent = errors.New("repeated content")
 )
 
 // OrchestratorOutputItem bundles the necessary information for exporting the result
-type OrchestratorOutputItem struct {
-	URL         string   `json:"url"`
-	ContentType string   `json:"contentType"`
-	Children    []string `json:"children"`
-}
+type OrchestratorOutputItem = report.Page
+
+// OrchestratorReport bundles the output items and the state in which the crawl finished
+type OrchestratorReport = report.Report
 
 // Orchestrator glues together all components
 type Orchestrator struct {
 	ctx         context.Context
+	cancel      context.CancelCauseFunc
+	downloads   context.Context
+	abort       context.CancelFunc
+	gracePeriod time.Duration
 	wg          sync.WaitGroup
+	done        chan struct{}
+	pending     atomic.Int64
+	dropped     atomic.Int64
+	interrupted atomic.Bool
+	seeds       []string
+	seedGroups  []report.Seed
 	downloaders int
 	frontier    frontier.Frontier
 	storage     storage.Storage
 	events      events.Events
 	downloader  downloader.Downloader
 	parser      parser.Parser
+	extractors  []extractor.Extractor
+	scraper     scraper.Scraper
 	dispatcher  dispatcher.Dispatcher
+	streamer    *report.Streamer
+	archive     *warc.Writer
+	hooks       hooks.Hooks
+	resources   bool
+	anyScheme   bool
+	schemesMu   sync.Mutex
+	schemes     map[string]string
+	outcomesMu  sync.Mutex
+	outcomes    map[string]outcome
+}
+
+// outcome is the error that stopped a url before it was stored, with the status code it got
+type outcome struct {
+	err        *StageError
+	statusCode int
 }
 
 // NewOrchestrator creates a new Orchestrator
@@ -51,21 +98,33 @@ func NewOrchestrator(
 	backoff time.Duration,
 	backoffMultiplier int,
 ) *Orchestrator {
-	return &Orchestrator{
+	ctx, cancel := context.WithCancelCause(ctx)
+	// downloads outlive ctx, so the urls in flight when the crawl is interrupted can finish
+	// within the grace period, they are canceled once it ends or once the crawl is over
+	downloads, abort := context.WithCancel(context.Background())
+	o := &Orchestrator{
 		ctx:         ctx,
+		cancel:      cancel,
+		downloads:   downloads,
+		abort:       abort,
 		wg:          sync.WaitGroup{},
+		done:        make(chan struct{}),
 		downloaders: downloaders,
 		frontier:    frontier,
 		storage:     storage,
 		events:      events,
 		downloader:  basic.NewDownloader(retries, backoff, backoffMultiplier),
-		parser:      basic.NewParser(),
-		dispatcher:  basic.NewDispatcher(events, frontier),
+		parser:      basic.NewRegistry(),
+		extractors:  []extractor.Extractor{basic.NewExtractor(), basic.NewStructuredDataExtractor()},
+		schemes:     map[string]string{},
+		outcomes:    map[string]outcome{},
 	}
+	o.dispatcher = basic.NewDispatcher(events, trackedFrontier{frontier, o})
+	return o
 }
 
-// Start synchronously explore the domain
-func (o *Orchestrator) Start(seed string) {
+// Start synchronously explore the domains of the seeds, each seed is crawled within its own host
+func (o *Orchestrator) Start(seeds ...string) {
 	for i := 0; i < o.downloaders; i++ {
 		go func(i int) {
 			for {
@@ -78,78 +137,296 @@ func (o *Orchestrator) Start(seed string) {
 			}
 		}(i)
 	}
-	// wg is decremented when processURL finishes
-	o.wg.Add(1)
-	o.frontier.Publish(seed)
+	seeds = o.addSeeds(seeds)
+
+	for _, seed := range seeds {
+		// pending is decremented when processURL finishes
+		o.addPending(1)
+		o.frontier.Publish(seed)
+	}
 
 	// wait for downloads complete or context to be canceled
-	c := make(chan struct{})
 	go func() {
-		defer close(c)
 		o.wg.Wait()
+		close(o.done)
+		o.abort()
 	}()
 	select {
-	case <-c:
+	case <-o.done:
 		log.Printf("all urls were processed")
 	case <-o.ctx.Done():
-		log.Printf("timeout exceeded")
+		o.interrupted.Store(true)
+		log.Printf("crawl interrupted: %v", context.Cause(o.ctx))
+		// the downloads in flight are canceled even if nobody drains them
+		time.AfterFunc(o.gracePeriod, o.abort)
 	}
 }
 
-func (o *Orchestrator) discovery(c *content.Content) error {
-	if c.Scheme == "" {
-		o.wg.Add(1)
-		go o.processURL(fmt.Sprintf("https://%s", c.Address))
-		o.wg.Add(1)
-		go o.processURL(fmt.Sprintf("http://%s", c.Address))
+// addSeeds registers the seeds not registered yet, which are returned
+func (o *Orchestrator) addSeeds(seeds []string) []string {
+	added := []string{}
+	for _, seed := range seeds {
+		known := false
+		for _, group := range o.seedGroups {
+			known = known || group.Seed == seed
+		}
+		if known {
+			continue
+		}
+		addresses := seedAddresses(seed)
+		o.seeds = append(o.seeds, addresses...)
+		o.seedGroups = append(o.seedGroups, report.Seed{Seed: seed, Addresses: addresses})
+		if o.streamer != nil {
+			o.streamer.AddSeed(seed, addresses...)
+		}
+		added = append(added, seed)
+	}
+	return added
+}
 
-		o.events.LogDiscoveryEvent(c.Address, false)
-		return fmt.Errorf("url missing schema [%s], trying https and http", c.Address)
+// Stream writes every page through the stream writer as soon as it is stored,
+// it must be called before Start
+func (o *Orchestrator) Stream(sw report.StreamWriter) {
+	o.streamer = report.NewStreamer(sw)
+}
+
+// Scrape runs the scraper on every page after it is parsed, it must be called before Start
+func (o *Orchestrator) Scrape(s scraper.Scraper) {
+	o.scraper = s
+}
+
+// Archive writes the request and response of every url downloaded to the WARC writer,
+// it must be called before Start
+func (o *Orchestrator) Archive(w *warc.Writer) {
+	o.archive = w
+}
+
+// UseDownloader downloads every url with the provided downloader instead of the basic one, like
+// a warc.Archive replaying a crawl or a mirror.Downloader sending conditional requests, it must
+// be called before Start
+func (o *Orchestrator) UseDownloader(d downloader.Downloader) {
+	o.downloader = d
+}
+
+// FollowResources crawls the stylesheets, images, scripts and media loaded by the pages
+// besides their links, it must be called before Start
+func (o *Orchestrator) FollowResources() {
+	o.resources = true
+}
+
+// IgnoreScheme treats the http and https variants of a url as the same url, which is crawled
+// once under the canonical scheme of its host, it must be called before Start
+func (o *Orchestrator) IgnoreScheme() {
+	o.anyScheme = true
+}
+
+// Hook calls the hook on every event of the stage, hooks run in the goroutines processing the
+// urls, it must be called before Start
+func (o *Orchestrator) Hook(stage hooks.Stage, hook hooks.Hook) {
+	o.hooks.Add(stage, hook)
+}
+
+// fire calls the hooks of a stage for a content, the event is only built when there are any
+func (o *Orchestrator) fire(stage hooks.Stage, c content.Content, err error) {
+	if !o.hooks.Has(stage) {
+		return
+	}
+	e := hooks.NewEvent(stage, c)
+	if err != nil {
+		e.Error = rootCause(err).Error()
+	}
+	o.hooks.Fire(e)
+}
+
+// FlushStream flushes the stream and returns the first error found while streaming
+func (o *Orchestrator) FlushStream() error {
+	if o.streamer == nil {
+		return nil
+	}
+	return o.streamer.Flush()
+}
+
+// GracePeriod sets how long in-flight URLs are awaited after the context is done, their downloads
+// are canceled once it ends, right away when it is not set, it must be called before Start
+func (o *Orchestrator) GracePeriod(gracePeriod time.Duration) {
+	o.gracePeriod = gracePeriod
+}
+
+// Drain waits for in-flight URLs after the context is done until they finish or the grace period
+// ends, URLs still waiting in the frontier are dropped and counted as queued
+func (o *Orchestrator) Drain() {
+	if !o.interrupted.Load() {
+		return
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case url := <-o.frontier.Consume():
+				log.Printf("dropping url [%s]", url)
+				o.dropped.Add(1)
+				o.donePending()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-o.done:
+		log.Printf("all in-flight urls were drained")
+	case <-o.downloads.Done():
+		// downloads are also canceled once the crawl is over
+		select {
+		case <-o.done:
+			log.Printf("all in-flight urls were drained")
+		default:
+			log.Printf("grace period exceeded with %d urls in flight", o.pending.Load())
+		}
+	}
+}
+
+// Seeds lists the addresses of the seeds, seeds without scheme have one per scheme
+func (o *Orchestrator) Seeds() []string {
+	return append([]string{}, o.seeds...)
+}
+
+// IsPartial informs if the crawl was interrupted before all URLs were processed
+func (o *Orchestrator) IsPartial() bool {
+	return o.interrupted.Load()
+}
+
+func (o *Orchestrator) addPending(n int) {
+	o.wg.Add(n)
+	o.pending.Add(int64(n))
+}
+
+func (o *Orchestrator) donePending() {
+	o.pending.Add(-1)
+	o.wg.Done()
+}
+
+func (o *Orchestrator) discovery(c *content.Content) error {
+	if o.anyScheme {
+		if canonical, ok := o.canonical(c.Address); ok && canonical != c.Address {
+			withScheme, err := content.NewContent(canonical)
+			if err != nil {
+				return newStageError(StageDiscovery, Failure, c.Address, err)
+			}
+			*c = withScheme
+		}
 	}
 
-	if !o.events.IsAlreadyDiscovered(c.Address) {
+	if !o.events.IsAlreadyDiscovered(c.Address) || (o.anyScheme && !o.events.IsAlreadyDiscovered(otherScheme(c.Address))) {
 		o.events.LogDiscoveryEvent(c.Address, false)
-		return fmt.Errorf("repeated url [%s]", c.Address)
+		return newStageError(StageDiscovery, Skip, c.Address, ErrRepeatedURL)
 	}
 
 	o.events.LogDiscoveryEvent(c.Address, true)
+	o.fire(hooks.OnDiscover, *c, nil)
 	return nil
 }
 
 func (o *Orchestrator) download(c *content.Content) error {
-	err := o.downloader.Download(o.ctx, c)
+	err := o.downloader.Download(o.downloads, c)
+	if c.StatusCode != 0 {
+		o.events.LogResponseEvent(c.Address, c.StatusCode, len(c.Body))
+		// every response is archived whatever its status, so a replay fails the same urls,
+		// and a broken archive does not fail the url
+		if o.archive != nil {
+			if err := o.archive.WriteExchange(*c); err != nil {
+				log.Printf("could not archive url [%s]: %v", c.Address, err)
+			}
+		}
+	}
+	o.fire(hooks.AfterDownload, *c, err)
 	if err != nil {
 		o.events.LogDownloadEvent(c.Address, false)
-		return fmt.Errorf("download failed: %v", err)
+		return newStageError(StageDownload, Failure, c.Address, err)
 	}
 	o.events.LogDownloadEvent(c.Address, true)
+	if o.anyScheme {
+		o.learnScheme(*c)
+	}
 	return nil
 }
 
 func (o *Orchestrator) skipRepeated(c *content.Content) error {
 	if o.storage.IsRepeatedContent(*c) {
-		return fmt.Errorf("repeated content for url [%s]", c.Address)
+		o.events.LogDuplicateEvent(c.Address)
+		return newStageError(StageDeduplication, Skip, c.Address, ErrRepeatedContent)
 	}
 	return nil
 }
 
 func (o *Orchestrator) parse(c *content.Content) error {
 	err := o.parser.Parse(c)
+	if errors.Is(err, parser.ErrUnsupportedContentType) {
+		// the content is still stored, it just has no links to follow
+		c.AddWarning(fmt.Sprintf("not parsed: %v", err))
+		o.fire(hooks.AfterParse, *c, nil)
+		return nil
+	}
 	if err != nil {
 		o.events.LogParseEvent(c.Address, false, 0)
-		return fmt.Errorf("parse failed: %v", err)
+		return newStageError(StageParse, Failure, c.Address, err)
 	}
 	o.events.LogParseEvent(c.Address, true, len(c.Children))
+	o.fire(hooks.AfterParse, *c, nil)
+	return nil
+}
+
+func (o *Orchestrator) scrape(c *content.Content) error {
+	if o.scraper == nil {
+		return nil
+	}
+	if err := o.scraper.Scrape(c); err != nil {
+		return newStageError(StageScrape, Failure, c.Address, err)
+	}
+	return nil
+}
+
+func (o *Orchestrator) extract(c *content.Content) error {
+	for _, e := range o.extractors {
+		if err := e.Extract(c); err != nil {
+			return newStageError(StageExtract, Failure, c.Address, err)
+		}
+	}
+	if o.resources && c.Metadata != nil {
+		for _, resource := range c.Metadata.Resources {
+			followResource(c, resource)
+		}
+	}
+	if o.anyScheme {
+		o.collapseChildren(c)
+	}
+	for _, warning := range c.Warnings {
+		log.Printf("warning for url [%s]: %s", c.Address, warning)
+	}
 	return nil
 }
 
 func (o *Orchestrator) store(c *content.Content) error {
 	err := o.storage.Add(*c)
+	if errors.Is(err, memory.ErrAddingDuplicateURL) || errors.Is(err, memory.ErrAddingDuplicateContent) {
+		// another url with the same content was stored since it was checked for repetition
+		o.events.LogDuplicateEvent(c.Address)
+		return newStageError(StageStore, Skip, c.Address, err)
+	}
 	if err != nil {
 		o.events.LogStoreEvent(c.Address, false)
-		return fmt.Errorf("store failed: %v", err)
+		return newStageError(StageStore, Failure, c.Address, err)
 	}
 	o.events.LogStoreEvent(c.Address, true)
+	o.fire(hooks.OnStore, *c, nil)
+	if o.streamer != nil {
+		// the page is stored, so a broken output does not fail it
+		if err := o.streamer.Add(*c); err != nil {
+			log.Printf("could not stream url [%s]: %v", c.Address, err)
+		}
+	}
 	return nil
 }
 
@@ -157,21 +434,30 @@ func (o *Orchestrator) dispatch(c *content.Content) error {
 	n, err := o.dispatcher.DispatchNewUrls(c.GetChildrenList())
 	if err != nil {
 		o.events.LogDispatchEvent(c.Address, false, n)
-		return fmt.Errorf("dispatch failed: %v", err)
+		// the children would be lost, so the crawl cannot go on without the frontier
+		return newStageError(StageDispatch, Fatal, c.Address, err)
 	}
 	o.events.LogDispatchEvent(c.Address, true, n)
-	// wg is decremented when processURL finishes
-	o.wg.Add(n)
 	return nil
 }
 
 func (o *Orchestrator) processURL(url string) {
-	// wg is incremented upon adding seed and after dispatching
-	defer o.wg.Done()
+	// pending is incremented upon adding seed and when publishing to the frontier
+	defer o.donePending()
+
+	if !hasScheme(url) {
+		o.negotiateScheme(url)
+		return
+	}
+	o.process(url)
+}
 
+// process runs a url through every action and returns the StageError that stopped it, if any
+func (o *Orchestrator) process(url string) error {
 	c, err := content.NewContent(url)
 	if err != nil {
-		log.Printf("error parsing url [%s]: %v", url, err)
+		c.Address = url
+		return o.stop(c, newStageError(StageDiscovery, Failure, url, err))
 	}
 
 	type action func(*content.Content) error
@@ -181,64 +467,317 @@ func (o *Orchestrator) processURL(url string) {
 		o.download,
 		o.skipRepeated,
 		o.parse,
+		o.scrape,
+		o.extract,
 		o.store,
 		o.dispatch,
 	}
 
 	for _, a := range actions {
-		err := a(&c)
-		if err != nil {
-			log.Println(err)
-			return
+		if err := a(&c); err != nil {
+			return o.stop(c, err)
 		}
 	}
+	return nil
 }
 
-// PrintReport writes the result to the provided writer in the specified format
-func (o *Orchestrator) PrintReport(w io.Writer, isJSON bool, isIndented bool) error {
-	allContent := o.storage.GetAllContent()
-	sort.Sort(sortByAddress(allContent))
+// stop accounts for the error that stopped a content and records its outcome, skips are not
+// errors for the events nor the hooks, and fatal errors interrupt the crawl
+func (o *Orchestrator) stop(c content.Content, err error) error {
+	log.Println(err)
 
-	output := make([]OrchestratorOutputItem, len(allContent))
-	for i, c := range allContent {
-		output[i] = OrchestratorOutputItem{
-			URL:         c.Address,
-			ContentType: c.ContentType,
-			Children:    c.GetChildrenList(),
-		}
+	var stageErr *StageError
+	if !errors.As(err, &stageErr) {
+		stageErr = newStageError("", Failure, c.Address, err)
+	}
+	if stageErr.Kind != Skip {
+		o.events.LogErrorEvent(c.Address, rootCause(err).Error())
+		o.fire(hooks.OnError, c, err)
+	}
+	o.record(stageErr, c.StatusCode)
+	if stageErr.Kind == Fatal {
+		o.cancel(err)
+	}
+	return err
+}
+
+// record keeps the outcome of a url that was not stored, a skip does not replace another
+// outcome, so a url repeated later does not hide why it failed
+func (o *Orchestrator) record(err *StageError, statusCode int) {
+	o.outcomesMu.Lock()
+	defer o.outcomesMu.Unlock()
+	if _, ok := o.outcomes[err.URL]; ok && err.Kind == Skip {
+		return
+	}
+	o.outcomes[err.URL] = outcome{err: err, statusCode: statusCode}
+}
+
+// fallBack turns the outcome of a url into a skip, since it is retried under another address
+func (o *Orchestrator) fallBack(err error) {
+	var stageErr *StageError
+	if !errors.As(err, &stageErr) {
+		return
+	}
+	o.outcomesMu.Lock()
+	defer o.outcomesMu.Unlock()
+	if out, ok := o.outcomes[stageErr.URL]; ok {
+		skipped := *out.err
+		skipped.Kind = Skip
+		out.err = &skipped
+		o.outcomes[stageErr.URL] = out
+	}
+}
+
+// outcomesOf lists the skipped and failed urls sorted by url, skipped urls stored anyway, like
+// the ones found repeated by a concurrent discovery, are left out, while a url stored before
+// failing to dispatch its children is still failed
+func (o *Orchestrator) outcomesOf(pages []report.Page) ([]report.Outcome, []report.Outcome) {
+	stored := map[string]struct{}{}
+	for _, p := range pages {
+		stored[p.URL] = struct{}{}
 	}
 
-	if isJSON {
-		var jsonData []byte
-		var err error
-		if isIndented {
-			jsonData, err = json.MarshalIndent(output, "", "    ")
+	o.outcomesMu.Lock()
+	defer o.outcomesMu.Unlock()
+	var skipped, failed []report.Outcome
+	for address, out := range o.outcomes {
+		if _, ok := stored[address]; ok && out.err.Kind == Skip {
+			continue
+		}
+		r := report.Outcome{
+			URL:        address,
+			Stage:      string(out.err.Stage),
+			Reason:     rootCause(out.err).Error(),
+			StatusCode: out.statusCode,
+			Fatal:      out.err.Kind == Fatal,
+		}
+		if out.err.Kind == Skip {
+			skipped = append(skipped, r)
 		} else {
-			jsonData, err = json.Marshal(output)
+			failed = append(failed, r)
 		}
-		if err != nil {
-			return err
+	}
+	sort.Slice(skipped, func(i, j int) bool { return skipped[i].URL < skipped[j].URL })
+	sort.Slice(failed, func(i, j int) bool { return failed[i].URL < failed[j].URL })
+	return skipped, failed
+}
+
+// negotiateScheme crawls a url given without scheme over https, falling back to http only
+// when https cannot be reached at all, so a site is never crawled under both schemes
+func (o *Orchestrator) negotiateScheme(address string) {
+	for _, scheme := range []string{"https", "http"} {
+		// the url is never discovered itself, only the attempts with a scheme are
+		log.Printf("%v [%s], trying %s", ErrMissingScheme, address, scheme)
+		o.events.LogSchemeRetryEvent(address)
+
+		err := o.process(fmt.Sprintf("%s://%s", scheme, address))
+		if !errors.Is(err, basic.ErrExecutingRequest) {
+			return
 		}
-		_, err = w.Write(jsonData)
-		return err
+		if scheme == "https" {
+			o.fallBack(err)
+		}
+	}
+}
+
+// learnScheme makes the scheme a host answered over the canonical one of the host, unless it
+// already has one, a redirect to https within the host counts as answering over https
+func (o *Orchestrator) learnScheme(c content.Content) {
+	scheme := c.Scheme
+	if location, err := url.Parse(c.Location); err == nil && location.Host == c.Host && location.Scheme == "https" {
+		scheme = location.Scheme
+	}
+
+	o.schemesMu.Lock()
+	defer o.schemesMu.Unlock()
+	if _, ok := o.schemes[c.Host]; !ok {
+		o.schemes[c.Host] = scheme
+	}
+}
+
+// canonical returns an http or https address with the canonical scheme of its host, once it is known
+func (o *Orchestrator) canonical(address string) (string, bool) {
+	u, err := url.Parse(address)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false
+	}
+
+	o.schemesMu.Lock()
+	scheme, ok := o.schemes[u.Host]
+	o.schemesMu.Unlock()
+	if !ok {
+		return "", false
 	}
+	u.Scheme = scheme
+	return u.String(), true
+}
+
+// collapseChildren replaces the children of a content by their variants with the canonical scheme
+func (o *Orchestrator) collapseChildren(c *content.Content) {
+	children, elements := c.Children, c.Elements
+	c.Children, c.Elements = nil, nil
+	for child := range children {
+		address := child
+		if canonical, ok := o.canonical(child); ok {
+			address = canonical
+		}
+		c.AddChild(address, elements[child])
+	}
+}
+
+// followResource adds a resource in the same host as a child of the content
+func followResource(c *content.Content, resource string) {
+	r, err := content.NewContent(resource)
+	if err != nil || r.Hostname() != c.Hostname() || (r.Scheme != "http" && r.Scheme != "https") {
+		return
+	}
+	c.AddChild(r.Address, "resource")
+}
 
-	for _, content := range allContent {
-		if _, err := w.Write([]byte(fmt.Sprintf("%s\n", content.Address))); err != nil {
+// rootCause unwraps an error until its innermost cause, which is free of url specific details
+func rootCause(err error) error {
+	for {
+		inner := errors.Unwrap(err)
+		if inner == nil {
 			return err
 		}
-		for _, child := range content.GetChildrenList() {
-			if _, err := w.Write([]byte(fmt.Sprintf("  |- %s\n", child))); err != nil {
-				return err
+		err = inner
+	}
+}
+
+// Stats summarizes the events logged so far
+func (o *Orchestrator) Stats() stats.Stats {
+	return stats.Compute(o.events.GetReport())
+}
+
+// Report builds the report for the stored pages, optionally with a summary of the crawl statistics
+func (o *Orchestrator) Report(withStats bool) OrchestratorReport {
+	r := OrchestratorReport{
+		Partial: o.IsPartial(),
+		Pages:   report.NewPages(o.storage.GetAllContent(), o.seeds),
+	}
+	r.Skipped, r.Failed = o.outcomesOf(r.Pages)
+	if r.Partial {
+		r.Queued = int(o.dropped.Load())
+		r.InFlight = int(o.pending.Load())
+	}
+	if withStats {
+		crawlStats := o.Stats()
+		r.Stats = &crawlStats
+	}
+	if len(o.seedGroups) > 1 {
+		o.groupBySeed(&r, withStats)
+	}
+	return r
+}
+
+// groupBySeed sets the seed of every page and lists the pages of each seed, optionally with
+// the statistics of the events of the urls in its scope
+func (o *Orchestrator) groupBySeed(r *OrchestratorReport, withStats bool) {
+	scopes := report.NewScopes(r.Pages, o.seedGroups)
+	for i := range r.Pages {
+		r.Pages[i].Seed, _ = scopes.Of(r.Pages[i].URL)
+	}
+	r.Seeds = scopes.Group(r.Pages)
+	if !withStats {
+		return
+	}
+
+	perSeed := map[string]map[string][]events.EventInstance{}
+	for address, instances := range o.events.GetReport() {
+		if seed, ok := scopes.Of(address); ok {
+			if perSeed[seed] == nil {
+				perSeed[seed] = map[string][]events.EventInstance{}
 			}
+			perSeed[seed][address] = instances
 		}
 	}
+	for i := range r.Seeds {
+		seedStats := stats.Compute(perSeed[r.Seeds[i].Seed])
+		r.Seeds[i].Stats = &seedStats
+	}
+}
 
-	return nil
+// Statuses maps every url that got a response to its last status code, stored or not
+func (o *Orchestrator) Statuses() map[string]int {
+	statuses := map[string]int{}
+	for address, instances := range o.events.GetReport() {
+		for _, instance := range instances {
+			if instance.EventType == events.Response {
+				statuses[address] = instance.Value
+			}
+		}
+	}
+	return statuses
+}
+
+// Analyze computes the link graph metrics of the stored pages, orphans are the
+// sitemap urls no stored page links to
+func (o *Orchestrator) Analyze(sitemapURLs []string) analysis.Analysis {
+	pages := report.NewPages(o.storage.GetAllContent(), o.seeds)
+	return analysis.Analyze(report.Links(pages), o.seeds, sitemapURLs)
 }
 
-type sortByAddress []content.Content
+// PrintReport writes the report to the provided writer using the provided report writer
+func (o *Orchestrator) PrintReport(w io.Writer, rw report.Writer, withStats bool) error {
+	return rw.Write(w, o.Report(withStats))
+}
+
+// PrintSitemap writes the stored pages as a sitemap, see sitemap.NewWriter for how
+// large sitemaps are split in parts
+func (o *Orchestrator) PrintSitemap(w io.Writer, baseURL string, partName string, partWriter sitemap.PartWriter) error {
+	return sitemap.NewWriter(baseURL, partName, partWriter).Write(w, o.storage.GetAllContent())
+}
+
+// seedAddresses normalizes a seed the way its content address is, seeds
+// without scheme may be crawled over https or http, whichever answers
+func seedAddresses(seed string) []string {
+	if !hasScheme(seed) {
+		addresses := []string{}
+		for _, scheme := range []string{"https", "http"} {
+			if withScheme, err := content.NewContent(fmt.Sprintf("%s://%s", scheme, seed)); err == nil {
+				addresses = append(addresses, withScheme.Address)
+			}
+		}
+		return addresses
+	}
+	c, err := content.NewContent(seed)
+	if err != nil {
+		return []string{seed}
+	}
+	return []string{c.Address}
+}
+
+// hasScheme informs if an address starts with a scheme, hosts with a port like localhost:8080
+// look like a scheme followed by an opaque part, so the scheme must be followed by //
+func hasScheme(address string) bool {
+	return strings.Contains(address, "://")
+}
+
+// otherScheme swaps the scheme of an http or https address for the other one
+func otherScheme(address string) string {
+	if strings.HasPrefix(address, "https://") {
+		return "http://" + strings.TrimPrefix(address, "https://")
+	}
+	if strings.HasPrefix(address, "http://") {
+		return "https://" + strings.TrimPrefix(address, "http://")
+	}
+	return address
+}
 
-func (a sortByAddress) Len() int           { return len(a) }
-func (a sortByAddress) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a sortByAddress) Less(i, j int) bool { return a[i].Address < a[j].Address }
+// trackedFrontier accounts for every URL published so the orchestrator knows
+// how many URLs are still pending
+type trackedFrontier struct {
+	frontier.Frontier
+	o *Orchestrator
+}
+
+// Publish increments the pending URLs before handing the URL to the frontier
+func (tf trackedFrontier) Publish(address string) error {
+	tf.o.addPending(1)
+	if err := tf.Frontier.Publish(address); err != nil {
+		tf.o.donePending()
+		return err
+	}
+	return nil
+}
@@ -4,16 +4,27 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/thiagolcmelo/webcrawler/src"
+	"github.com/thiagolcmelo/webcrawler/src/basic"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/hooks"
 	"github.com/thiagolcmelo/webcrawler/src/memory"
+	"github.com/thiagolcmelo/webcrawler/src/progress"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+	"github.com/thiagolcmelo/webcrawler/src/stats"
+	"github.com/thiagolcmelo/webcrawler/src/warc"
 )
 
 type webpage struct {
@@ -100,7 +111,7 @@ func sampleWebsite(url string) []webpage {
 	return []webpage{webpage0, webpage1, webpage2, webpage3}
 }
 
-func TestOrchestrator(t *testing.T) {
+func sampleServer() (*httptest.Server, map[string]webpage) {
 	website := map[string]webpage{}
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -124,12 +135,18 @@ func TestOrchestrator(t *testing.T) {
 		w.Write([]byte(page.body))
 		w.WriteHeader(http.StatusOK)
 	}))
-	defer server.Close()
 
 	for _, page := range sampleWebsite(server.URL) {
 		website[page.url] = page
 	}
 
+	return server, website
+}
+
+func TestOrchestrator(t *testing.T) {
+	server, website := sampleServer()
+	defer server.Close()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
 
@@ -141,17 +158,23 @@ func TestOrchestrator(t *testing.T) {
 
 	orchestrator := src.NewOrchestrator(ctx, 10, frontier, storage, events, 1, time.Second, 2)
 	orchestrator.Start(server.URL)
-	err := orchestrator.PrintReport(&buf, true, false)
+	err := orchestrator.PrintReport(&buf, report.NewJSONWriter(false), false)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	var actualResult []src.OrchestratorOutputItem
-	err = json.Unmarshal(buf.Bytes(), &actualResult)
+	var actualReport src.OrchestratorReport
+	err = json.Unmarshal(buf.Bytes(), &actualReport)
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	if actualReport.Partial {
+		t.Errorf("expected a complete report")
+	}
+
+	actualResult := actualReport.Pages
+
 	if len(actualResult) != len(website) {
 		t.Errorf("expected %d results, got %d", len(website), len(actualResult))
 	}
@@ -165,5 +188,794 @@ func TestOrchestrator(t *testing.T) {
 				t.Errorf("expected %#v, got %#v", page.expectedChildren, resultItem.Children)
 			}
 		}
+
+		// every page is linked from the seed
+		expectedDepth := 1
+		if resultItem.URL == fmt.Sprintf("%s/", server.URL) {
+			expectedDepth = 0
+		}
+		if resultItem.Depth != expectedDepth {
+			t.Errorf("expected depth %d for %s, got %d", expectedDepth, resultItem.URL, resultItem.Depth)
+		}
+	}
+}
+
+func TestOrchestrator_Interrupted(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.RequestURI == "/slow" {
+			<-release
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<a href="/slow">slow page</a>`))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	frontier := memory.NewFrontier()
+	storage := memory.NewStorage()
+	events := memory.NewEvents()
+
+	orchestrator := src.NewOrchestrator(ctx, 10, frontier, storage, events, 1, time.Second, 2)
+	go func() {
+		// IsAlreadyDiscovered returns false once the url was discovered
+		for events.IsAlreadyDiscovered(fmt.Sprintf("%s/slow", server.URL)) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		cancel()
+	}()
+	orchestrator.GracePeriod(time.Second)
+	orchestrator.Start(server.URL)
+	orchestrator.Drain()
+
+	if !orchestrator.IsPartial() {
+		t.Fatal("expected a partial crawl")
+	}
+
+	var buf bytes.Buffer
+	err := orchestrator.PrintReport(&buf, report.NewJSONWriter(false), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actualReport src.OrchestratorReport
+	err = json.Unmarshal(buf.Bytes(), &actualReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !actualReport.Partial {
+		t.Errorf("expected report to be partial")
+	}
+
+	if len(actualReport.Pages) != 1 {
+		t.Errorf("expected 1 page, got %d", len(actualReport.Pages))
+	}
+}
+
+func TestOrchestrator_GracePeriod(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if r.RequestURI == "/slow" {
+			select {
+			case <-time.After(300 * time.Millisecond):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		fmt.Fprintf(w, `<a href="/slow">slow page</a> at %s`, r.RequestURI)
+	}))
+	defer server.Close()
+
+	type testCase struct {
+		testName       string
+		gracePeriod    time.Duration
+		expectedStored bool
+	}
+
+	testCases := []testCase{
+		{testName: "in_flight_download_finishes_within_grace_period", gracePeriod: 5 * time.Second, expectedStored: true},
+		{testName: "in_flight_download_fails_after_grace_period", gracePeriod: 10 * time.Millisecond, expectedStored: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			// the timeout interrupts the crawl while the slow page is downloading
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			orchestrator := src.NewOrchestrator(ctx, 10, memory.NewFrontier(), memory.NewStorage(), memory.NewEvents(), 3, time.Millisecond, 2)
+			orchestrator.GracePeriod(tc.gracePeriod)
+			orchestrator.Start(server.URL)
+			orchestrator.Drain()
+
+			slow := server.URL + "/slow"
+			stored := false
+			for _, page := range orchestrator.Report(false).Pages {
+				stored = stored || (page.URL == slow && page.StatusCode == http.StatusOK)
+			}
+			if stored != tc.expectedStored {
+				t.Fatalf("expected the slow page to be stored %v, got %v", tc.expectedStored, stored)
+			}
+			if tc.expectedStored {
+				return
+			}
+
+			// the canceled download fails instead of storing an empty page
+			for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+				failed := orchestrator.Report(false).Failed
+				if len(failed) == 1 && failed[0].URL == slow && failed[0].Reason == context.Canceled.Error() {
+					return
+				}
+			}
+			t.Errorf("expected the slow page to fail, got %+v", orchestrator.Report(false).Failed)
+		})
+	}
+}
+
+// downloaderFunc turns a function into a downloader
+type downloaderFunc func(context.Context, *content.Content) error
+
+func (f downloaderFunc) Download(ctx context.Context, c *content.Content) error {
+	return f(ctx, c)
+}
+
+func TestOrchestrator_DownloadsEndWithTheCrawl(t *testing.T) {
+	type testCase struct {
+		testName string
+		timeout  time.Duration
+		block    bool
+	}
+
+	testCases := []testCase{
+		{testName: "crawl_finished", timeout: time.Minute},
+		{testName: "crawl_interrupted_without_drain", timeout: 50 * time.Millisecond, block: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+			defer cancel()
+
+			downloads := make(chan context.Context, 1)
+			orchestrator := src.NewOrchestrator(ctx, 1, memory.NewFrontier(), memory.NewStorage(), memory.NewEvents(), 1, time.Millisecond, 2)
+			orchestrator.UseDownloader(downloaderFunc(func(ctx context.Context, c *content.Content) error {
+				downloads <- ctx
+				if tc.block {
+					<-ctx.Done()
+					return ctx.Err()
+				}
+				c.StatusCode = http.StatusOK
+				c.ContentType = "text/plain"
+				return nil
+			}))
+			// without Drain nothing awaits the urls in flight, so nothing should keep downloading
+			orchestrator.Start("http://domain.com/")
+
+			select {
+			case <-(<-downloads).Done():
+			case <-time.After(time.Second):
+				t.Errorf("expected the downloads to be canceled")
+			}
+		})
+	}
+}
+
+func TestOrchestrator_Stats(t *testing.T) {
+	server, website := sampleServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	defer cancel()
+
+	orchestrator := src.NewOrchestrator(ctx, 10, memory.NewFrontier(), memory.NewStorage(), memory.NewEvents(), 1, time.Second, 2)
+	orchestrator.Start(server.URL)
+
+	var buf bytes.Buffer
+	err := orchestrator.PrintReport(&buf, report.NewJSONWriter(false), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actualReport src.OrchestratorReport
+	err = json.Unmarshal(buf.Bytes(), &actualReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actualReport.Stats == nil {
+		t.Fatal("expected stats in the report")
+	}
+
+	if actual := actualReport.Stats.Stages["store"].Success; actual != len(website) {
+		t.Errorf("expected %d stored pages, got %d", len(website), actual)
+	}
+
+	if actual := actualReport.Stats.DownloadLatency.Count; actual != len(website) {
+		t.Errorf("expected %d download latencies, got %d", len(website), actual)
+	}
+}
+
+func TestOrchestrator_Stream(t *testing.T) {
+	server, website := sampleServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	defer cancel()
+
+	var buf bytes.Buffer
+	streamWriter, err := report.NewStreamWriter("ndjson", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orchestrator := src.NewOrchestrator(ctx, 10, memory.NewFrontier(), memory.NewStorage(), memory.NewEvents(), 1, time.Second, 2)
+	orchestrator.Stream(streamWriter)
+	orchestrator.Start(server.URL)
+	if err := orchestrator.FlushStream(); err != nil {
+		t.Fatal(err)
+	}
+
+	decoder := json.NewDecoder(&buf)
+	actual := map[string]int{}
+	for decoder.More() {
+		var page src.OrchestratorOutputItem
+		if err := decoder.Decode(&page); err != nil {
+			t.Fatal(err)
+		}
+		actual[page.URL] = page.Depth
+	}
+
+	expected := map[string]int{}
+	for url := range website {
+		expected[url] = 1
+	}
+	expected[fmt.Sprintf("%s/", server.URL)] = 0
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected streamed pages (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestOrchestrator_Statuses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.RequestURI != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<a href="/missing">missing page</a>`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	defer cancel()
+
+	orchestrator := src.NewOrchestrator(ctx, 10, memory.NewFrontier(), memory.NewStorage(), memory.NewEvents(), 1, time.Second, 2)
+	orchestrator.Start(server.URL)
+
+	expected := map[string]int{
+		fmt.Sprintf("%s/", server.URL):        http.StatusOK,
+		fmt.Sprintf("%s/missing", server.URL): http.StatusNotFound,
+	}
+	if diff := cmp.Diff(expected, orchestrator.Statuses()); diff != "" {
+		t.Errorf("unexpected statuses (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestOrchestrator_Replay(t *testing.T) {
+	server, website := sampleServer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	defer cancel()
+
+	var archived bytes.Buffer
+	orchestrator := src.NewOrchestrator(ctx, 10, memory.NewFrontier(), memory.NewStorage(), memory.NewEvents(), 1, time.Second, 2)
+	orchestrator.Archive(warc.NewWriter(&archived))
+	orchestrator.Start(server.URL)
+	server.Close()
+
+	archive, err := warc.Load(&archived)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the server is closed, so every page comes from the archive
+	replayed := src.NewOrchestrator(ctx, 10, memory.NewFrontier(), memory.NewStorage(), memory.NewEvents(), 1, time.Second, 2)
+	replayed.UseDownloader(archive)
+	replayed.Start(server.URL)
+
+	actual := map[string][]string{}
+	for _, page := range replayed.Report(false).Pages {
+		actual[page.URL] = page.Children
+	}
+	expected := map[string][]string{}
+	for url, page := range website {
+		expected[url] = page.expectedChildren
+	}
+	less := func(a, b string) bool { return a < b }
+	if diff := cmp.Diff(expected, actual, cmpopts.SortSlices(less)); diff != "" {
+		t.Errorf("unexpected replayed pages (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestOrchestrator_ReplayEveryResponse(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprintf(w, `<a href="/old">old</a><a href="/missing">missing</a><a href="/broken">broken</a> at %s`, r.Host)
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/new":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprintf(w, `new at %s`, r.Host)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	first, second := httptest.NewServer(handler), httptest.NewServer(handler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var archived bytes.Buffer
+	w := warc.NewWriter(&archived)
+	if err := w.WriteInfo(map[string]string{"seeds": first.URL + " " + second.URL}); err != nil {
+		t.Fatal(err)
+	}
+	orchestrator := src.NewOrchestrator(ctx, 10, memory.NewFrontier(), memory.NewStorage(), memory.NewEvents(), 1, time.Millisecond, 2)
+	orchestrator.Archive(w)
+	orchestrator.Start(first.URL, second.URL)
+	first.Close()
+	second.Close()
+
+	archive, err := warc.Load(&archived)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// both seeds are replayed, with the same redirects and failures as the crawl
+	replayed := src.NewOrchestrator(ctx, 10, memory.NewFrontier(), memory.NewStorage(), memory.NewEvents(), 1, time.Millisecond, 2)
+	replayed.UseDownloader(archive)
+	replayed.Start(archive.Seeds()...)
+
+	if diff := cmp.Diff(orchestrator.Statuses(), replayed.Statuses()); diff != "" {
+		t.Errorf("unexpected replayed statuses (-crawled +replayed):\n%s", diff)
+	}
+	ignore := cmpopts.IgnoreFields(report.Page{}, "ElapsedMs")
+	crawled, replayedReport := orchestrator.Report(false), replayed.Report(false)
+	if diff := cmp.Diff(crawled.Pages, replayedReport.Pages, ignore); diff != "" {
+		t.Errorf("unexpected replayed pages (-crawled +replayed):\n%s", diff)
+	}
+	if diff := cmp.Diff(crawled.Failed, replayedReport.Failed); diff != "" || len(crawled.Failed) != 4 {
+		t.Errorf("expected the missing and broken pages of both seeds to fail again, got %d failed (-crawled +replayed):\n%s", len(crawled.Failed), diff)
+	}
+}
+
+func TestOrchestrator_FollowResources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<link rel="stylesheet" href="/site.css"><img src="/logo.png"><img src="http://other.com/logo.png">`))
+		case "/site.css":
+			w.Header().Set("Content-Type", "text/css")
+			w.Write([]byte(`body { background: url(/bg.png) }`))
+		default:
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte(r.RequestURI))
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	defer cancel()
+
+	orchestrator := src.NewOrchestrator(ctx, 10, memory.NewFrontier(), memory.NewStorage(), memory.NewEvents(), 1, time.Second, 2)
+	orchestrator.FollowResources()
+	orchestrator.Start(server.URL)
+
+	actual := []string{}
+	for _, page := range orchestrator.Report(false).Pages {
+		actual = append(actual, page.URL)
+	}
+	expected := []string{
+		fmt.Sprintf("%s/", server.URL),
+		fmt.Sprintf("%s/bg.png", server.URL),
+		fmt.Sprintf("%s/logo.png", server.URL),
+		fmt.Sprintf("%s/site.css", server.URL),
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected pages (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestOrchestrator_MultipleSeeds(t *testing.T) {
+	first, firstWebsite := sampleServer()
+	defer first.Close()
+	second := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(fmt.Sprintf(`<a href="/other">other %s</a>`, r.URL.Path)))
+	}))
+	defer second.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	defer cancel()
+
+	orchestrator := src.NewOrchestrator(ctx, 10, memory.NewFrontier(), memory.NewStorage(), memory.NewEvents(), 1, time.Second, 2)
+	// seeds given twice are crawled once
+	orchestrator.Start(first.URL, second.URL, first.URL)
+
+	r := orchestrator.Report(true)
+	if len(r.Seeds) != 2 {
+		t.Fatalf("expected 2 seeds, got %d", len(r.Seeds))
+	}
+
+	if r.Seeds[0].Seed != first.URL || len(r.Seeds[0].Pages) != len(firstWebsite) {
+		t.Errorf("expected %d pages for %s, got %d for %s", len(firstWebsite), first.URL, len(r.Seeds[0].Pages), r.Seeds[0].Seed)
+	}
+	if r.Seeds[1].Seed != second.URL || len(r.Seeds[1].Pages) != 2 {
+		t.Errorf("expected 2 pages for %s, got %d for %s", second.URL, len(r.Seeds[1].Pages), r.Seeds[1].Seed)
+	}
+
+	for _, group := range r.Seeds {
+		if group.Stats == nil {
+			t.Fatalf("expected stats for %s", group.Seed)
+		}
+		if actual := group.Stats.Stages["store"].Success; actual != len(group.Pages) {
+			t.Errorf("expected %d stored pages for %s, got %d", len(group.Pages), group.Seed, actual)
+		}
+	}
+
+	for _, page := range r.Pages {
+		if _, ok := firstWebsite[page.URL]; ok && page.Seed != first.URL {
+			t.Errorf("expected %s to be in the scope of %s, got %s", page.URL, first.URL, page.Seed)
+		}
+	}
+}
+
+func TestOrchestrator_SchemeNegotiation(t *testing.T) {
+	server, website := sampleServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	defer cancel()
+
+	// the server only answers over http, so https fails and http is tried next
+	orchestrator := src.NewOrchestrator(ctx, 10, memory.NewFrontier(), memory.NewStorage(), memory.NewEvents(), 1, time.Second, 2)
+	orchestrator.Start(strings.TrimPrefix(server.URL, "http://"))
+
+	r := orchestrator.Report(false)
+	if len(r.Pages) != len(website) {
+		t.Fatalf("expected %d pages, got %d", len(website), len(r.Pages))
+	}
+	for _, page := range r.Pages {
+		if _, ok := website[page.URL]; !ok {
+			t.Errorf("unexpected page %s", page.URL)
+		}
+		if page.URL == fmt.Sprintf("%s/", server.URL) && page.Depth != 0 {
+			t.Errorf("expected the seed at depth 0, got %d", page.Depth)
+		}
+	}
+
+	// the https attempt is not a failure, since the seed was crawled over http instead
+	if len(r.Failed) != 0 || len(r.Skipped) != 1 || r.Skipped[0].Stage != string(src.StageDownload) {
+		t.Errorf("expected only the https attempt to be skipped, got %+v skipped and %+v failed", r.Skipped, r.Failed)
+	}
+
+	// the seed without scheme is neither a discovery failure nor an error, only its attempts count
+	s := orchestrator.Stats()
+	if failures := s.Stages["discovery"].Failure; failures != 0 {
+		t.Errorf("expected no failed discoveries, got %d", failures)
+	}
+	for _, e := range s.TopErrors {
+		if e.Reason == src.ErrMissingScheme.Error() {
+			t.Errorf("expected the missing scheme not to be an error, got %+v", s.TopErrors)
+		}
+	}
+}
+
+func TestOrchestrator_IgnoreScheme(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/docs/", http.StatusMovedPermanently)
+		case "/docs/":
+			// relative links are resolved against the final location
+			w.Write([]byte(fmt.Sprintf(`<a href="page">page</a><a href="https://%s/">home</a>`, r.Host)))
+		default:
+			w.Write([]byte(fmt.Sprintf(`<a href="/old">old</a><a href="https://%s/docs/page">page</a> at %s`, r.Host, r.URL.Path)))
+		}
+	}))
+	defer server.Close()
+
+	type testCase struct {
+		testName            string
+		ignoreScheme        bool
+		expected            []string
+		expectedUnreachable int
+	}
+
+	testCases := []testCase{
+		{
+			testName:            "https_links_are_crawled_on_their_own",
+			ignoreScheme:        false,
+			expected:            []string{"/", "/docs/page", "/old"},
+			expectedUnreachable: 2,
+		},
+		{
+			testName:            "https_links_collapse_under_http",
+			ignoreScheme:        true,
+			expected:            []string{"/", "/docs/page", "/old"},
+			expectedUnreachable: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+			defer cancel()
+
+			orchestrator := src.NewOrchestrator(ctx, 10, memory.NewFrontier(), memory.NewStorage(), memory.NewEvents(), 1, time.Second, 2)
+			if tc.ignoreScheme {
+				orchestrator.IgnoreScheme()
+			}
+			orchestrator.Start(server.URL)
+
+			actual := []string{}
+			for _, page := range orchestrator.Report(false).Pages {
+				actual = append(actual, strings.TrimPrefix(page.URL, server.URL))
+				for _, child := range page.Children {
+					if tc.ignoreScheme && !strings.HasPrefix(child, server.URL) {
+						t.Errorf("expected %s to be collapsed under http", child)
+					}
+				}
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected pages (-expected +actual):\n%s", diff)
+			}
+
+			// the server does not answer over https
+			unreachable := 0
+			for _, e := range orchestrator.Stats().TopErrors {
+				if e.Reason == basic.ErrExecutingRequest.Error() {
+					unreachable = e.Count
+				}
+			}
+			if unreachable != tc.expectedUnreachable {
+				t.Errorf("expected %d unreachable urls, got %d", tc.expectedUnreachable, unreachable)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_Hooks(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<a href="/page">page</a><a href="/broken">broken</a><a href="/missing">missing</a>`))
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<a href="/">home</a>`))
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer site.Close()
+
+	var mu sync.Mutex
+	received := []hooks.Event{}
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch hooks.Batch
+		json.NewDecoder(r.Body).Decode(&batch)
+		mu.Lock()
+		received = append(received, batch.Events...)
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	orchestrator := src.NewOrchestrator(ctx, 10, memory.NewFrontier(), memory.NewStorage(), memory.NewEvents(), 1, time.Millisecond, 2)
+	fired := map[hooks.Stage][]string{}
+	for _, stage := range hooks.Stages() {
+		orchestrator.Hook(stage, func(e hooks.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			fired[e.Stage] = append(fired[e.Stage], fmt.Sprintf("%s %d", strings.TrimPrefix(e.URL, site.URL), e.StatusCode))
+		})
+	}
+	webhook := hooks.NewWebhook(receiver.URL, hooks.WebhookOptions{BatchSize: 10})
+	webhook.Subscribe(orchestrator.Hook, []hooks.Stage{hooks.OnError}, 500)
+
+	orchestrator.Start(site.URL)
+	if err := webhook.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[hooks.Stage][]string{
+		hooks.OnDiscover:    {"/ 0", "/broken 0", "/missing 0", "/page 0"},
+		hooks.AfterDownload: {"/ 200", "/broken 500", "/missing 404", "/page 200"},
+		hooks.AfterParse:    {"/ 200", "/page 200"},
+		hooks.OnStore:       {"/ 200", "/page 200"},
+		hooks.OnError:       {"/broken 500", "/missing 404"},
+	}
+	if diff := cmp.Diff(expected, fired, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("unexpected hooks fired (-expected +actual):\n%s", diff)
+	}
+
+	if len(received) != 1 || received[0].URL != site.URL+"/broken" || received[0].Error != basic.ErrResponseStatusNotOK.Error() {
+		t.Errorf("expected the webhook to get the server error only, got %+v", received)
+	}
+}
+
+func TestOrchestrator_Outcomes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.RequestURI {
+		case "/":
+			w.Write([]byte(`<a href="/page">page</a><a href="/broken">broken</a><a href="/missing">missing</a>`))
+		case "/page", "/copy":
+			w.Write([]byte(`<a href="/copy">copy</a>`))
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	counter := progress.NewCounter(memory.NewEvents(), 1)
+	orchestrator := src.NewOrchestrator(ctx, 10, memory.NewFrontier(), memory.NewStorage(), counter, 1, time.Millisecond, 2)
+	orchestrator.Start(server.URL)
+
+	r := orchestrator.Report(false)
+	if len(r.Pages) != 2 {
+		t.Errorf("expected the root and the page to be stored, got %d pages", len(r.Pages))
+	}
+	expectedSkipped := []report.Outcome{
+		{URL: server.URL + "/copy", Stage: string(src.StageDeduplication), Reason: src.ErrRepeatedContent.Error(), StatusCode: http.StatusOK},
+	}
+	if diff := cmp.Diff(expectedSkipped, r.Skipped); diff != "" {
+		t.Errorf("unexpected skipped urls (-expected +actual):\n%s", diff)
+	}
+	expectedFailed := []report.Outcome{
+		{URL: server.URL + "/broken", Stage: string(src.StageDownload), Reason: basic.ErrResponseStatusNotOK.Error(), StatusCode: http.StatusInternalServerError},
+		{URL: server.URL + "/missing", Stage: string(src.StageDownload), Reason: basic.ErrResponseStatusNotOK.Error(), StatusCode: http.StatusNotFound},
+	}
+	if diff := cmp.Diff(expectedFailed, r.Failed); diff != "" {
+		t.Errorf("unexpected failed urls (-expected +actual):\n%s", diff)
+	}
+	if orchestrator.IsPartial() {
+		t.Errorf("expected no fatal error to interrupt the crawl")
+	}
+
+	// skips are not errors, only failures are, and they finish their urls all the same
+	expectedErrors := []stats.ErrorReason{{Reason: basic.ErrResponseStatusNotOK.Error(), Count: 2}}
+	if diff := cmp.Diff(expectedErrors, orchestrator.Stats().TopErrors); diff != "" {
+		t.Errorf("unexpected errors (-expected +actual):\n%s", diff)
+	}
+	if snapshot := counter.Snapshot(); snapshot.Queued != 0 || snapshot.InFlight != 0 {
+		t.Errorf("expected no url left queued or in flight, got %+v", snapshot)
+	}
+}
+
+func TestStageError(t *testing.T) {
+	type testCase struct {
+		testName     string
+		err          error
+		expectedKind src.Kind
+	}
+
+	skip := &src.StageError{Stage: src.StageDiscovery, Kind: src.Skip, URL: "http://domain.com/", Err: src.ErrRepeatedURL}
+	testCases := []testCase{
+		{testName: "skip", err: skip, expectedKind: src.Skip},
+		{testName: "wrapped", err: fmt.Errorf("crawl stopped: %w", &src.StageError{Kind: src.Fatal, Err: errors.New("frontier closed")}), expectedKind: src.Fatal},
+		{testName: "not_a_stage_error", err: errors.New("unknown"), expectedKind: src.Failure},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if kind := src.KindOf(tc.err); kind != tc.expectedKind {
+				t.Errorf("expected %s, got %s", tc.expectedKind, kind)
+			}
+		})
+	}
+
+	if !errors.Is(skip, src.ErrRepeatedURL) {
+		t.Errorf("expected the stage error to wrap %v", src.ErrRepeatedURL)
+	}
+	if expected := "skip at discovery of url [http://domain.com/]: repeated url"; skip.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, skip.Error())
+	}
+}
+
+// closingFrontier stops taking urls after the first one, like a frontier whose queue went away
+type closingFrontier struct {
+	*memory.Frontier
+	published atomic.Int32
+}
+
+func (cf *closingFrontier) Publish(address string) error {
+	if cf.published.Add(1) > 1 {
+		return errors.New("frontier closed")
+	}
+	return cf.Frontier.Publish(address)
+}
+
+func TestOrchestrator_Fatal(t *testing.T) {
+	server, _ := sampleServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	orchestrator := src.NewOrchestrator(ctx, 10, &closingFrontier{Frontier: memory.NewFrontier()}, memory.NewStorage(), memory.NewEvents(), 1, time.Millisecond, 2)
+	orchestrator.Start(server.URL)
+
+	if !orchestrator.IsPartial() {
+		t.Errorf("expected the crawl to be interrupted")
+	}
+	r := orchestrator.Report(false)
+	if len(r.Failed) != 1 || !r.Failed[0].Fatal || r.Failed[0].Stage != string(src.StageDispatch) || r.Failed[0].URL != server.URL+"/" {
+		t.Errorf("expected the seed to fail fatally at dispatch, got %+v", r.Failed)
+	}
+}
+
+func TestOrchestrator_ReportWhileInFlight(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if r.RequestURI == "/" {
+			for i := 0; i < 10; i++ {
+				fmt.Fprintf(w, `<a href="/slow%d">slow page</a>`, i)
+			}
+			return
+		}
+		fmt.Fprintf(w, `<a href="/">home</a> at %s`, r.RequestURI)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the slow pages are held right after their download until released, still in flight
+	var held sync.WaitGroup
+	held.Add(10)
+	release := make(chan struct{})
+	orchestrator := src.NewOrchestrator(ctx, 10, memory.NewFrontier(), memory.NewStorage(), memory.NewEvents(), 1, time.Millisecond, 2)
+	orchestrator.Hook(hooks.AfterDownload, func(e hooks.Event) {
+		if strings.Contains(e.URL, "/slow") {
+			held.Done()
+			<-release
+		}
+	})
+	go func() {
+		held.Wait()
+		cancel()
+	}()
+	orchestrator.GracePeriod(time.Millisecond)
+	orchestrator.Start(server.URL)
+	orchestrator.Drain()
+
+	// the urls in flight after the grace period keep logging events while the report is built
+	close(release)
+	for deadline := time.Now().Add(100 * time.Millisecond); time.Now().Before(deadline); {
+		orchestrator.Stats()
+		orchestrator.Statuses()
+		orchestrator.Report(true)
+	}
+	if !orchestrator.Report(false).Partial {
+		t.Errorf("expected a partial report")
 	}
 }
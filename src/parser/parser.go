@@ -1,6 +1,13 @@
 package parser
 
-import "github.com/thiagolcmelo/webcrawler/src/content"
+import (
+	"errors"
+
+	"github.com/thiagolcmelo/webcrawler/src/content"
+)
+
+// ErrUnsupportedContentType should be used when there is no parser for the type of a content
+var ErrUnsupportedContentType = errors.New("unsupported content type")
 
 // Parser defines an interface for parsing downloaded content
 type Parser interface {
@@ -0,0 +1,134 @@
+package progress
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/thiagolcmelo/webcrawler/src/events"
+)
+
+// Snapshot bundles the progress counts at a given moment
+type Snapshot struct {
+	Discovered int `json:"discovered"`
+	Queued     int `json:"queued"`
+	InFlight   int `json:"inFlight"`
+	Downloaded int `json:"downloaded"`
+	Stored     int `json:"stored"`
+	Failed     int `json:"failed"`
+}
+
+// Counter decorates an Events implementation counting every event logged,
+// the counts are enough to tell how many urls are queued and in flight
+type Counter struct {
+	events.Events
+	seeds         atomic.Int64
+	dispatched    atomic.Int64
+	discoveries   atomic.Int64
+	discovered    atomic.Int64
+	downloaded    atomic.Int64
+	stored        atomic.Int64
+	failed        atomic.Int64
+	finished      atomic.Int64
+	schemeRetries atomic.Int64
+	schemeless    sync.Map
+}
+
+// NewCounter is a factory for Counter, seeds is how many urls start the crawl
+func NewCounter(inner events.Events, seeds int) *Counter {
+	c := &Counter{Events: inner}
+	c.seeds.Store(int64(seeds))
+	return c
+}
+
+// LogDiscoveryEvent counts a discovery, every processed url logs exactly one, a url already
+// discovered is skipped, which finishes it
+func (c *Counter) LogDiscoveryEvent(address string, success bool) {
+	c.discoveries.Add(1)
+	if success {
+		c.discovered.Add(1)
+	} else {
+		c.finished.Add(1)
+	}
+	c.Events.LogDiscoveryEvent(address, success)
+}
+
+// LogDownloadEvent counts a download
+func (c *Counter) LogDownloadEvent(address string, success bool) {
+	c.countOutcome(&c.downloaded, success)
+	c.Events.LogDownloadEvent(address, success)
+}
+
+// LogParseEvent counts a parse failure
+func (c *Counter) LogParseEvent(address string, success bool, children int) {
+	c.countOutcome(nil, success)
+	c.Events.LogParseEvent(address, success, children)
+}
+
+// LogStoreEvent counts a store
+func (c *Counter) LogStoreEvent(address string, success bool) {
+	c.countOutcome(&c.stored, success)
+	c.Events.LogStoreEvent(address, success)
+}
+
+// LogDispatchEvent counts the urls dispatched, a successful dispatch finishes a url
+func (c *Counter) LogDispatchEvent(address string, success bool, children int) {
+	c.countOutcome(nil, success)
+	if success {
+		c.dispatched.Add(int64(children))
+		c.finished.Add(1)
+	}
+	c.Events.LogDispatchEvent(address, success, children)
+}
+
+// LogDuplicateEvent counts a url skipped because its content was already seen, which finishes it
+func (c *Counter) LogDuplicateEvent(address string) {
+	c.finished.Add(1)
+	c.Events.LogDuplicateEvent(address)
+}
+
+// LogErrorEvent counts a url that stopped being processed
+func (c *Counter) LogErrorEvent(address string, reason string) {
+	c.finished.Add(1)
+	c.Events.LogErrorEvent(address, reason)
+}
+
+// LogSchemeRetryEvent counts an attempt of a url without scheme, the first one takes the place
+// of the url, which is never discovered itself, the next ones are processed without going
+// through the frontier, like urls published to it
+func (c *Counter) LogSchemeRetryEvent(address string) {
+	if _, tried := c.schemeless.LoadOrStore(address, struct{}{}); tried {
+		c.schemeRetries.Add(1)
+	}
+	c.Events.LogSchemeRetryEvent(address)
+}
+
+func (c *Counter) countOutcome(successes *atomic.Int64, success bool) {
+	if !success {
+		c.failed.Add(1)
+	} else if successes != nil {
+		successes.Add(1)
+	}
+}
+
+// Snapshot returns the current counts
+func (c *Counter) Snapshot() Snapshot {
+	discoveries := c.discoveries.Load()
+	published := c.seeds.Load() + c.dispatched.Load() + c.schemeRetries.Load()
+
+	return Snapshot{
+		Discovered: int(c.discovered.Load()),
+		Queued:     int(atLeastZero(published - discoveries)),
+		InFlight:   int(atLeastZero(discoveries - c.finished.Load())),
+		Downloaded: int(c.downloaded.Load()),
+		Stored:     int(c.stored.Load()),
+		Failed:     int(c.failed.Load()),
+	}
+}
+
+// atLeastZero clamps the counts that are briefly negative while events of a url are logged
+func atLeastZero(n int64) int64 {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,109 @@
+package progress_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/memory"
+	"github.com/thiagolcmelo/webcrawler/src/progress"
+)
+
+func TestCounter_Snapshot(t *testing.T) {
+	type testCase struct {
+		testName string
+		log      func(c *progress.Counter)
+		expected progress.Snapshot
+	}
+
+	testCases := []testCase{
+		{
+			testName: "seed_is_queued_before_discovery",
+			log:      func(c *progress.Counter) {},
+			expected: progress.Snapshot{Queued: 1},
+		},
+		{
+			testName: "discovered_url_is_in_flight",
+			log: func(c *progress.Counter) {
+				c.LogDiscoveryEvent("url1", true)
+				c.LogDownloadEvent("url1", true)
+			},
+			expected: progress.Snapshot{Discovered: 1, InFlight: 1, Downloaded: 1},
+		},
+		{
+			testName: "dispatched_urls_are_queued",
+			log: func(c *progress.Counter) {
+				c.LogDiscoveryEvent("url1", true)
+				c.LogDownloadEvent("url1", true)
+				c.LogParseEvent("url1", true, 3)
+				c.LogStoreEvent("url1", true)
+				c.LogDispatchEvent("url1", true, 3)
+				c.LogDiscoveryEvent("url2", true)
+			},
+			expected: progress.Snapshot{Discovered: 2, Queued: 2, InFlight: 1, Downloaded: 1, Stored: 1},
+		},
+		{
+			testName: "failures_finish_urls",
+			log: func(c *progress.Counter) {
+				c.LogDiscoveryEvent("url1", true)
+				c.LogDownloadEvent("url1", false)
+				c.LogErrorEvent("url1", "download failed")
+			},
+			expected: progress.Snapshot{Discovered: 1, Failed: 1},
+		},
+		{
+			testName: "skips_finish_urls",
+			log: func(c *progress.Counter) {
+				c.LogDiscoveryEvent("url1", true)
+				c.LogDownloadEvent("url1", true)
+				c.LogStoreEvent("url1", true)
+				c.LogDispatchEvent("url1", true, 2)
+				// url2 was already discovered and url3 has the content of url1
+				c.LogDiscoveryEvent("url2", false)
+				c.LogDiscoveryEvent("url3", true)
+				c.LogDownloadEvent("url3", true)
+				c.LogDuplicateEvent("url3")
+			},
+			expected: progress.Snapshot{Discovered: 2, Downloaded: 2, Stored: 1},
+		},
+		{
+			testName: "url_missing_scheme_is_tried_in_its_place",
+			log: func(c *progress.Counter) {
+				c.LogSchemeRetryEvent("domain.com")
+				c.LogDiscoveryEvent("https://domain.com", true)
+			},
+			expected: progress.Snapshot{Discovered: 1, InFlight: 1},
+		},
+		{
+			testName: "url_missing_scheme_falls_back_to_http",
+			log: func(c *progress.Counter) {
+				c.LogSchemeRetryEvent("domain.com")
+				c.LogDiscoveryEvent("https://domain.com", true)
+				c.LogDownloadEvent("https://domain.com", false)
+				c.LogErrorEvent("https://domain.com", "could not execute request")
+				c.LogSchemeRetryEvent("domain.com")
+				c.LogDiscoveryEvent("http://domain.com", true)
+			},
+			expected: progress.Snapshot{Discovered: 2, InFlight: 1, Failed: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			counter := progress.NewCounter(memory.NewEvents(), 1)
+			tc.log(counter)
+			if diff := cmp.Diff(tc.expected, counter.Snapshot()); diff != "" {
+				t.Errorf("unexpected snapshot (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCounter_ForwardsEvents(t *testing.T) {
+	me := memory.NewEvents()
+	counter := progress.NewCounter(me, 1)
+	counter.LogDiscoveryEvent("url1", true)
+
+	if counter.IsAlreadyDiscovered("url1") {
+		t.Errorf("expected url1 to be discovered in the decorated events")
+	}
+}
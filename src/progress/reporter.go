@@ -0,0 +1,102 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"time"
+)
+
+const (
+	// ttyInterval is how often the progress line is redrawn on a terminal
+	ttyInterval = 250 * time.Millisecond
+	// logInterval is how often a progress line is logged when not on a terminal
+	logInterval = 5 * time.Second
+)
+
+// Reporter periodically writes the progress of a crawl, it redraws a single line
+// on a terminal and falls back to log lines otherwise
+type Reporter struct {
+	w        io.Writer
+	logger   *log.Logger
+	counter  *Counter
+	timeout  time.Duration
+	interval time.Duration
+	isTTY    bool
+	start    time.Time
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewReporter is a factory for Reporter
+func NewReporter(w io.Writer, counter *Counter, timeout time.Duration, isTTY bool) *Reporter {
+	interval := logInterval
+	if isTTY {
+		interval = ttyInterval
+	}
+	return &Reporter{
+		w:        w,
+		logger:   log.New(w, "", log.LstdFlags),
+		counter:  counter,
+		timeout:  timeout,
+		interval: interval,
+		isTTY:    isTTY,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start writes the progress periodically until Stop is called
+func (r *Reporter) Start() {
+	r.start = time.Now()
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		last := r.counter.Snapshot()
+		lastTick := r.start
+		for {
+			select {
+			case now := <-ticker.C:
+				snapshot := r.counter.Snapshot()
+				rate := float64(snapshot.Stored-last.Stored) / now.Sub(lastTick).Seconds()
+				r.write(snapshot, rate, now)
+				last, lastTick = snapshot, now
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop writes the final progress and stops reporting
+func (r *Reporter) Stop() {
+	close(r.stop)
+	<-r.done
+
+	now := time.Now()
+	snapshot := r.counter.Snapshot()
+	rate := 0.0
+	if elapsed := now.Sub(r.start).Seconds(); elapsed > 0 {
+		rate = float64(snapshot.Stored) / elapsed
+	}
+	r.write(snapshot, rate, now)
+	if r.isTTY {
+		fmt.Fprintln(r.w)
+	}
+}
+
+func (r *Reporter) write(s Snapshot, rate float64, now time.Time) {
+	line := fmt.Sprintf(
+		"discovered %d | queued %d | in-flight %d | downloaded %d | stored %d | failed %d | %.1f pages/s | %s / %s",
+		s.Discovered, s.Queued, s.InFlight, s.Downloaded, s.Stored, s.Failed, rate,
+		now.Sub(r.start).Truncate(time.Second), r.timeout,
+	)
+	if r.isTTY {
+		// carriage return and erase the line so it is redrawn in place
+		fmt.Fprintf(r.w, "\r\033[K%s", line)
+		return
+	}
+	r.logger.Println(line)
+}
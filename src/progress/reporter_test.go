@@ -0,0 +1,57 @@
+package progress_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thiagolcmelo/webcrawler/src/memory"
+	"github.com/thiagolcmelo/webcrawler/src/progress"
+)
+
+func TestReporter(t *testing.T) {
+	type testCase struct {
+		testName       string
+		isTTY          bool
+		expectedPrefix string
+		expectedSuffix string
+	}
+
+	testCases := []testCase{
+		{
+			testName:       "redraws_line_on_terminal",
+			isTTY:          true,
+			expectedPrefix: "\r\033[K",
+			expectedSuffix: "\n",
+		},
+		{
+			testName:       "logs_lines_otherwise",
+			isTTY:          false,
+			expectedPrefix: "",
+			expectedSuffix: "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			counter := progress.NewCounter(memory.NewEvents(), 1)
+			counter.LogDiscoveryEvent("url1", true)
+			counter.LogDownloadEvent("url1", true)
+			counter.LogStoreEvent("url1", true)
+
+			var buf bytes.Buffer
+			reporter := progress.NewReporter(&buf, counter, 10*time.Second, tc.isTTY)
+			reporter.Start()
+			reporter.Stop()
+
+			output := buf.String()
+			if !strings.HasPrefix(output, tc.expectedPrefix) || !strings.HasSuffix(output, tc.expectedSuffix) {
+				t.Errorf("unexpected framing in %q", output)
+			}
+			if !strings.Contains(output, "stored 1") || !strings.Contains(output, "/ 10s") {
+				t.Errorf("unexpected progress %q", output)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	pageColumns = []string{"url", "content_type", "status_code", "depth", "links"}
+	edgeColumns = []string{"source", "target", "element", "source_depth"}
+)
+
+// fieldSeparator joins the values of a scraped field matching several elements
+const fieldSeparator = " | "
+
+// CSVWriter writes a CSV row per page, or per link when writing edges
+type CSVWriter struct {
+	w           *csv.Writer
+	perEdge     bool
+	fields      []string
+	wroteHeader bool
+}
+
+// NewCSVWriter is a factory for CSVWriter, rows per page have a column for each scraped field
+func NewCSVWriter(w io.Writer, perEdge bool, fields ...string) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w), perEdge: perEdge, fields: fields}
+}
+
+// WritePage writes the rows of a page, the header goes before the first one
+func (cw *CSVWriter) WritePage(p Page) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+
+	depth := strconv.Itoa(p.Depth)
+	if !cw.perEdge {
+		row := []string{p.URL, p.ContentType, strconv.Itoa(p.StatusCode), depth, strconv.Itoa(len(p.Children))}
+		for _, field := range cw.fields {
+			row = append(row, strings.Join(p.Scraped[field], fieldSeparator))
+		}
+		return cw.w.Write(row)
+	}
+	for _, child := range p.Children {
+		if err := cw.w.Write([]string{p.URL, child, p.Elements[child], depth}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered rows, a stream without pages still gets its header
+func (cw *CSVWriter) Flush() error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *CSVWriter) writeHeader() error {
+	if cw.wroteHeader {
+		return nil
+	}
+	cw.wroteHeader = true
+	if cw.perEdge {
+		return cw.w.Write(edgeColumns)
+	}
+	header := append([]string{}, pageColumns...)
+	for _, field := range cw.fields {
+		// prefixed so scraped fields cannot clash with the page columns
+		header = append(header, "scraped_"+field)
+	}
+	return cw.w.Write(header)
+}
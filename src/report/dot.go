@@ -0,0 +1,45 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DOTWriter writes the link graph in the Graphviz DOT language
+type DOTWriter struct{}
+
+// NewDOTWriter is a factory for DOTWriter
+func NewDOTWriter() *DOTWriter {
+	return &DOTWriter{}
+}
+
+// Write writes every url as a node with status, depth and content type, and every link as an edge
+func (dw *DOTWriter) Write(w io.Writer, r Report) error {
+	nodes, edges := graph(r)
+
+	var b strings.Builder
+	b.WriteString("digraph crawl {\n")
+	for _, n := range nodes {
+		fmt.Fprintf(&b, "  %s [status=%d, depth=%d, content_type=%s];\n",
+			dotQuote(n.URL), n.StatusCode, n.Depth, dotQuote(n.ContentType))
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "  %s -> %s", dotQuote(e.Source), dotQuote(e.Target))
+		if e.Element != "" {
+			fmt.Fprintf(&b, " [element=%s]", dotQuote(e.Element))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote quotes an identifier escaping backslashes and double quotes
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
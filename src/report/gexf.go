@@ -0,0 +1,112 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type gexfNode struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue,omitempty"`
+}
+
+type gexfGraph struct {
+	Mode            string           `xml:"mode,attr"`
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+type gexf struct {
+	XMLName xml.Name  `xml:"gexf"`
+	Xmlns   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+// GEXFWriter writes the link graph as GEXF, the format used by Gephi
+type GEXFWriter struct{}
+
+// NewGEXFWriter is a factory for GEXFWriter
+func NewGEXFWriter() *GEXFWriter {
+	return &GEXFWriter{}
+}
+
+// Write writes every url as a node labeled by the url with status, depth and content type,
+// and every link as an edge
+func (gw *GEXFWriter) Write(w io.Writer, r Report) error {
+	nodes, edges := graph(r)
+
+	doc := gexf{
+		Xmlns:   "http://gexf.net/1.3",
+		Version: "1.3",
+		Graph: gexfGraph{
+			Mode:            "static",
+			DefaultEdgeType: "directed",
+			Attributes: []gexfAttributes{
+				{
+					Class: "node",
+					Attributes: []gexfAttribute{
+						{ID: "status", Title: "status", Type: "integer"},
+						{ID: "depth", Title: "depth", Type: "integer"},
+						{ID: "contentType", Title: "contentType", Type: "string"},
+					},
+				},
+				{
+					Class:      "edge",
+					Attributes: []gexfAttribute{{ID: "element", Title: "element", Type: "string"}},
+				},
+			},
+		},
+	}
+
+	ids := map[string]string{}
+	for i, n := range nodes {
+		ids[n.URL] = strconv.Itoa(i)
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gexfNode{
+			ID:    ids[n.URL],
+			Label: n.URL,
+			AttValues: []gexfAttValue{
+				{For: "status", Value: strconv.Itoa(n.StatusCode)},
+				{For: "depth", Value: strconv.Itoa(n.Depth)},
+				{For: "contentType", Value: n.ContentType},
+			},
+		})
+	}
+	for i, e := range edges {
+		edge := gexfEdge{ID: fmt.Sprintf("%d", i), Source: ids[e.Source], Target: ids[e.Target]}
+		if e.Element != "" {
+			edge.AttValues = []gexfAttValue{{For: "element", Value: e.Element}}
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	return encodeXML(w, doc)
+}
@@ -0,0 +1,117 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/thiagolcmelo/webcrawler/src/report"
+)
+
+func TestDOTWriter_Write(t *testing.T) {
+	var buf bytes.Buffer
+	if err := report.NewDOTWriter().Write(&buf, sampleReport(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"digraph crawl {\n",
+		`  "http://domain.com/page2" [status=200, depth=2, content_type="text/html"];`,
+		`  "http://domain.com/missing" [status=0, depth=3, content_type=""];`,
+		`  "http://domain.com/" -> "http://domain.com/page1" [element="a"];`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestGraphMLWriter_Write(t *testing.T) {
+	var buf bytes.Buffer
+	if err := report.NewGraphMLWriter().Write(&buf, sampleReport(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	var actual struct {
+		Graph struct {
+			Nodes []struct {
+				ID   string `xml:"id,attr"`
+				Data []struct {
+					Key   string `xml:"key,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"data"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	// four pages plus the missing page they link to
+	if len(actual.Graph.Nodes) != 5 {
+		t.Errorf("expected 5 nodes, got %d", len(actual.Graph.Nodes))
+	}
+	if len(actual.Graph.Edges) != 4 {
+		t.Errorf("expected 4 edges, got %d", len(actual.Graph.Edges))
+	}
+
+	ids := map[string]bool{}
+	for _, n := range actual.Graph.Nodes {
+		ids[n.ID] = true
+	}
+	for _, e := range actual.Graph.Edges {
+		if !ids[e.Source] || !ids[e.Target] {
+			t.Errorf("edge %s -> %s references unknown nodes", e.Source, e.Target)
+		}
+	}
+}
+
+func TestGEXFWriter_Write(t *testing.T) {
+	var buf bytes.Buffer
+	if err := report.NewGEXFWriter().Write(&buf, sampleReport(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	var actual struct {
+		Version string `xml:"version,attr"`
+		Graph   struct {
+			Nodes []struct {
+				ID        string `xml:"id,attr"`
+				Label     string `xml:"label,attr"`
+				AttValues []struct {
+					For   string `xml:"for,attr"`
+					Value string `xml:"value,attr"`
+				} `xml:"attvalues>attvalue"`
+			} `xml:"nodes>node"`
+			Edges []struct {
+				Source    string `xml:"source,attr"`
+				Target    string `xml:"target,attr"`
+				AttValues []struct {
+					For   string `xml:"for,attr"`
+					Value string `xml:"value,attr"`
+				} `xml:"attvalues>attvalue"`
+			} `xml:"edges>edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	if actual.Version != "1.3" {
+		t.Errorf("unexpected version %s", actual.Version)
+	}
+	if len(actual.Graph.Nodes) != 5 || actual.Graph.Nodes[0].Label != "http://domain.com/" {
+		t.Errorf("unexpected nodes %#v", actual.Graph.Nodes)
+	}
+	if len(actual.Graph.Nodes[0].AttValues) != 3 {
+		t.Errorf("expected status, depth and content type attributes, got %#v", actual.Graph.Nodes[0].AttValues)
+	}
+	if len(actual.Graph.Edges) != 4 || actual.Graph.Edges[0].AttValues[0].Value != "a" {
+		t.Errorf("unexpected edges %#v", actual.Graph.Edges)
+	}
+}
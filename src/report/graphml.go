@@ -0,0 +1,107 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data,omitempty"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+// GraphMLWriter writes the link graph as GraphML
+type GraphMLWriter struct{}
+
+// NewGraphMLWriter is a factory for GraphMLWriter
+func NewGraphMLWriter() *GraphMLWriter {
+	return &GraphMLWriter{}
+}
+
+// Write writes every url as a node with url, status, depth and content type, and every link as an edge
+func (gw *GraphMLWriter) Write(w io.Writer, r Report) error {
+	nodes, edges := graph(r)
+
+	doc := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "url", For: "node", AttrName: "url", AttrType: "string"},
+			{ID: "status", For: "node", AttrName: "status", AttrType: "int"},
+			{ID: "depth", For: "node", AttrName: "depth", AttrType: "int"},
+			{ID: "contentType", For: "node", AttrName: "contentType", AttrType: "string"},
+			{ID: "element", For: "edge", AttrName: "element", AttrType: "string"},
+		},
+		Graph: graphMLGraph{ID: "crawl", EdgeDefault: "directed"},
+	}
+
+	ids := map[string]string{}
+	for i, n := range nodes {
+		ids[n.URL] = fmt.Sprintf("n%d", i)
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: ids[n.URL],
+			Data: []graphMLData{
+				{Key: "url", Value: n.URL},
+				{Key: "status", Value: strconv.Itoa(n.StatusCode)},
+				{Key: "depth", Value: strconv.Itoa(n.Depth)},
+				{Key: "contentType", Value: n.ContentType},
+			},
+		})
+	}
+	for i, e := range edges {
+		edge := graphMLEdge{ID: fmt.Sprintf("e%d", i), Source: ids[e.Source], Target: ids[e.Target]}
+		if e.Element != "" {
+			edge.Data = []graphMLData{{Key: "element", Value: e.Element}}
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	return encodeXML(w, doc)
+}
+
+func encodeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
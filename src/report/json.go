@@ -0,0 +1,32 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONWriter writes the report as a single JSON document
+type JSONWriter struct {
+	indented bool
+}
+
+// NewJSONWriter is a factory for JSONWriter
+func NewJSONWriter(indented bool) *JSONWriter {
+	return &JSONWriter{indented: indented}
+}
+
+// Write writes the report as JSON, optionally indented
+func (jw *JSONWriter) Write(w io.Writer, r Report) error {
+	var jsonData []byte
+	var err error
+	if jw.indented {
+		jsonData, err = json.MarshalIndent(r, "", "    ")
+	} else {
+		jsonData, err = json.Marshal(r)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(jsonData)
+	return err
+}
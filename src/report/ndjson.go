@@ -0,0 +1,26 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// NDJSONWriter writes a JSON document per page, one per line
+type NDJSONWriter struct {
+	encoder *json.Encoder
+}
+
+// NewNDJSONWriter is a factory for NDJSONWriter
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{encoder: json.NewEncoder(w)}
+}
+
+// WritePage writes a page as a JSON line
+func (nw *NDJSONWriter) WritePage(p Page) error {
+	return nw.encoder.Encode(p)
+}
+
+// Flush does nothing, every line is written as soon as it is encoded
+func (nw *NDJSONWriter) Flush() error {
+	return nil
+}
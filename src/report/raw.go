@@ -0,0 +1,132 @@
+package report
+
+import (
+	"fmt"
+	"io"
+)
+
+// RawWriter writes the report as a dummy tree, each page followed by its children
+type RawWriter struct{}
+
+// NewRawWriter is a factory for RawWriter
+func NewRawWriter() *RawWriter {
+	return &RawWriter{}
+}
+
+// writeSeeds writes a dummy tree per seed, each followed by the seed stats when present, and
+// then the pages not in the scope of any seed
+func (rw *RawWriter) writeSeeds(w io.Writer, r Report) error {
+	pages := map[string]Page{}
+	for _, p := range r.Pages {
+		pages[p.URL] = p
+	}
+
+	for _, group := range r.Seeds {
+		if _, err := fmt.Fprintf(w, "# seed %s: %d pages\n", group.Seed, len(group.Pages)); err != nil {
+			return err
+		}
+		grouped := []Page{}
+		for _, u := range group.Pages {
+			grouped = append(grouped, pages[u])
+			delete(pages, u)
+		}
+		if err := writeTree(w, grouped); err != nil {
+			return err
+		}
+		if group.Stats != nil {
+			if err := group.Stats.WriteText(w); err != nil {
+				return err
+			}
+		}
+	}
+
+	others := []Page{}
+	for _, p := range r.Pages {
+		if _, ok := pages[p.URL]; ok {
+			others = append(others, p)
+		}
+	}
+	if len(others) > 0 {
+		if _, err := fmt.Fprintf(w, "# out of any seed scope: %d pages\n", len(others)); err != nil {
+			return err
+		}
+		return writeTree(w, others)
+	}
+	return nil
+}
+
+// writeTree writes each page followed by its children
+func writeTree(w io.Writer, pages []Page) error {
+	for _, page := range pages {
+		if _, err := w.Write([]byte(fmt.Sprintf("%s\n", page.URL))); err != nil {
+			return err
+		}
+		for _, child := range page.Children {
+			if _, err := w.Write([]byte(fmt.Sprintf("  |- %s\n", child))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeOutcomes writes the urls not stored, each with the stage it stopped at and why
+func writeOutcomes(w io.Writer, title string, outcomes []Outcome) error {
+	if len(outcomes) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "# %s: %d urls\n", title, len(outcomes)); err != nil {
+		return err
+	}
+	for _, o := range outcomes {
+		line := fmt.Sprintf("%s at %s: %s", o.URL, o.Stage, o.Reason)
+		if o.StatusCode != 0 {
+			line += fmt.Sprintf(" (%d)", o.StatusCode)
+		}
+		if o.Fatal {
+			line += ", crawl interrupted"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write writes the report as a dummy tree, followed by the urls not stored, the stats and
+// analysis when present
+func (rw *RawWriter) Write(w io.Writer, r Report) error {
+	if r.Partial {
+		header := fmt.Sprintf("# partial report: %d queued and %d in-flight urls unfinished\n", r.Queued, r.InFlight)
+		if _, err := w.Write([]byte(header)); err != nil {
+			return err
+		}
+	}
+
+	if len(r.Seeds) > 0 {
+		if err := rw.writeSeeds(w, r); err != nil {
+			return err
+		}
+	} else if err := writeTree(w, r.Pages); err != nil {
+		return err
+	}
+
+	if err := writeOutcomes(w, "skipped", r.Skipped); err != nil {
+		return err
+	}
+	if err := writeOutcomes(w, "failed", r.Failed); err != nil {
+		return err
+	}
+
+	if r.Stats != nil {
+		if err := r.Stats.WriteText(w); err != nil {
+			return err
+		}
+	}
+
+	if r.Analysis != nil {
+		return r.Analysis.WriteText(w)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrUnknownReport should be used when a crawl output is neither a JSON report nor NDJSON pages
+var ErrUnknownReport = errors.New("crawl output is not a json report nor ndjson pages")
+
+// Read reads a crawl output written in the json, json-formatted or ndjson formats, along with
+// the bare array of pages get wrote before the report had sections
+func Read(r io.Reader) (Report, error) {
+	decoder := json.NewDecoder(r)
+
+	var first json.RawMessage
+	if err := decoder.Decode(&first); err != nil {
+		if err == io.EOF {
+			return Report{Pages: []Page{}}, nil
+		}
+		return Report{}, fmt.Errorf("%w: %v", ErrUnknownReport, err)
+	}
+
+	if trimmed := bytes.TrimSpace(first); len(trimmed) > 0 && trimmed[0] == '[' {
+		return readPages(first)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(first, &fields); err != nil {
+		return Report{}, fmt.Errorf("%w: %v", ErrUnknownReport, err)
+	}
+
+	if _, ok := fields["pages"]; ok {
+		var report Report
+		if err := json.Unmarshal(first, &report); err != nil {
+			return Report{}, err
+		}
+		return report, nil
+	}
+
+	if _, ok := fields["url"]; !ok {
+		return Report{}, ErrUnknownReport
+	}
+
+	report := Report{Pages: []Page{}}
+	raw := first
+	for {
+		var p Page
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return Report{}, err
+		}
+		report.Pages = append(report.Pages, p)
+
+		if err := decoder.Decode(&raw); err == io.EOF {
+			return report, nil
+		} else if err != nil {
+			return Report{}, err
+		}
+	}
+}
+
+// readPages reads a bare array of pages, which has no depths, so they are computed from the
+// shortest url, where a crawl of a single seed usually starts
+func readPages(data json.RawMessage) (Report, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return Report{}, fmt.Errorf("%w: %v", ErrUnknownReport, err)
+	}
+
+	report := Report{Pages: []Page{}}
+	seed := ""
+	for _, item := range items {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(item, &fields); err != nil {
+			return Report{}, fmt.Errorf("%w: %v", ErrUnknownReport, err)
+		}
+		if _, ok := fields["url"]; !ok {
+			return Report{}, ErrUnknownReport
+		}
+
+		var p Page
+		if err := json.Unmarshal(item, &p); err != nil {
+			return Report{}, err
+		}
+		report.Pages = append(report.Pages, p)
+		if seed == "" || len(p.URL) < len(seed) || (len(p.URL) == len(seed) && p.URL < seed) {
+			seed = p.URL
+		}
+	}
+
+	depths := ClickDepths(report.Pages, []string{seed})
+	for i := range report.Pages {
+		report.Pages[i].Depth = depthOf(depths, report.Pages[i].URL)
+	}
+	return report, nil
+}
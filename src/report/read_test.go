@@ -0,0 +1,88 @@
+package report_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+)
+
+func TestRead(t *testing.T) {
+	for _, format := range []string{"json", "json-formatted", "ndjson"} {
+		t.Run(format, func(t *testing.T) {
+			rw, err := report.NewWriter(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := sampleReport(t)
+			var buf bytes.Buffer
+			if err := rw.Write(&buf, expected); err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := report.Read(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// elements are not part of the output
+			for i := range expected.Pages {
+				expected.Pages[i].Elements = nil
+			}
+			if diff := cmp.Diff(expected.Pages, actual.Pages, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("unexpected pages (-expected +actual):\n%s", diff)
+			}
+			if diff := cmp.Diff([]string{"http://domain.com/"}, report.Seeds(actual.Pages)); diff != "" {
+				t.Errorf("unexpected seeds (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRead_BareArray(t *testing.T) {
+	type testCase struct {
+		testName      string
+		input         string
+		expectedPages []report.Page
+	}
+
+	testCases := []testCase{
+		{
+			testName: "pages",
+			input: `[{"url":"http://domain.com/a","contentType":"text/html","children":["http://domain.com/b"]},` +
+				`{"url":"http://domain.com/","contentType":"text/html","children":["http://domain.com/a"]},` +
+				`{"url":"http://domain.com/b","contentType":"text/plain","children":[]}]`,
+			expectedPages: []report.Page{
+				{URL: "http://domain.com/a", ContentType: "text/html", Depth: 1, Children: []string{"http://domain.com/b"}},
+				{URL: "http://domain.com/", ContentType: "text/html", Depth: 0, Children: []string{"http://domain.com/a"}},
+				{URL: "http://domain.com/b", ContentType: "text/plain", Depth: 2, Children: []string{}},
+			},
+		},
+		{testName: "empty", input: "[]", expectedPages: []report.Page{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			actual, err := report.Read(strings.NewReader(tc.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedPages, actual.Pages); diff != "" {
+				t.Errorf("unexpected pages (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRead_UnknownReport(t *testing.T) {
+	for _, input := range []string{"url,depth\n", `{"something":"else"}`, `[1, 2]`, `[{"something":"else"}]`} {
+		if _, err := report.Read(strings.NewReader(input)); !errors.Is(err, report.ErrUnknownReport) {
+			t.Errorf("expected %v for %q, got %v", report.ErrUnknownReport, input, err)
+		}
+	}
+}
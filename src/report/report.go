@@ -0,0 +1,230 @@
+package report
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thiagolcmelo/webcrawler/src/analysis"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/stats"
+)
+
+// ErrUnknownFormat should be used when a report is requested in a format without a Writer
+var ErrUnknownFormat = errors.New("unknown format")
+
+// Page bundles the necessary information for exporting a crawled page
+type Page struct {
+	URL         string                    `json:"url"`
+	ContentType string                    `json:"contentType"`
+	StatusCode  int                       `json:"statusCode,omitempty"`
+	Depth       int                       `json:"depth"`
+	Seed        string                    `json:"seed,omitempty"`
+	Size        int                       `json:"size"`
+	Hash        string                    `json:"sha256,omitempty"`
+	ElapsedMs   float64                   `json:"elapsedMs"`
+	Redirects   []string                  `json:"redirects,omitempty"`
+	Location    string                    `json:"location,omitempty"`
+	Children    []string                  `json:"children"`
+	Metadata    *content.Metadata         `json:"metadata,omitempty"`
+	Structured  []*content.StructuredItem `json:"structuredData,omitempty"`
+	Scraped     map[string][]string       `json:"scraped,omitempty"`
+	Warnings    []string                  `json:"warnings,omitempty"`
+	Elements    map[string]string         `json:"-"`
+}
+
+// Outcome tells why a url was not stored, the stage it stopped at and the cause
+type Outcome struct {
+	URL        string `json:"url"`
+	Stage      string `json:"stage"`
+	Reason     string `json:"reason"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Fatal      bool   `json:"fatal,omitempty"`
+}
+
+// Report bundles the pages crawled and the state in which the crawl finished, the urls
+// that were not stored are either skipped, like repeated ones, or failed
+type Report struct {
+	Partial  bool               `json:"partial"`
+	Queued   int                `json:"queued"`
+	InFlight int                `json:"inFlight"`
+	Pages    []Page             `json:"pages"`
+	Skipped  []Outcome          `json:"skipped,omitempty"`
+	Failed   []Outcome          `json:"failed,omitempty"`
+	Seeds    []SeedReport       `json:"seeds,omitempty"`
+	Stats    *stats.Stats       `json:"stats,omitempty"`
+	Analysis *analysis.Analysis `json:"analysis,omitempty"`
+}
+
+// Writer defines an interface for writing a report in a given format
+type Writer interface {
+	Write(io.Writer, Report) error
+}
+
+// writers maps each format to a factory for its Writer, given the scraped fields to write
+var writers = map[string]func(fields []string) Writer{
+	"raw":            func([]string) Writer { return NewRawWriter() },
+	"json":           func([]string) Writer { return NewJSONWriter(false) },
+	"json-formatted": func([]string) Writer { return NewJSONWriter(true) },
+	"dot":            func([]string) Writer { return NewDOTWriter() },
+	"graphml":        func([]string) Writer { return NewGraphMLWriter() },
+	"gexf":           func([]string) Writer { return NewGEXFWriter() },
+	"csv":            sorted("csv"),
+	"csv-edges":      sorted("csv-edges"),
+	"ndjson":         sorted("ndjson"),
+}
+
+// Formats lists the formats known by NewWriter
+func Formats() []string {
+	formats := make([]string, 0, len(writers))
+	for format := range writers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// NewWriter creates the Writer for a format, formats with room for them write the scraped fields
+func NewWriter(format string, fields ...string) (Writer, error) {
+	factory, ok := writers[format]
+	if !ok {
+		return nil, fmt.Errorf("%w [%s], it can be %s", ErrUnknownFormat, format, strings.Join(Formats(), ", "))
+	}
+	return factory(fields), nil
+}
+
+// NewPage converts a content into a page, children are sorted
+func NewPage(c content.Content) Page {
+	children := c.GetChildrenList()
+	sort.Strings(children)
+	return Page{
+		URL:         c.Address,
+		ContentType: c.ContentType,
+		StatusCode:  c.StatusCode,
+		Size:        len(c.Body),
+		Hash:        hash(c),
+		ElapsedMs:   float64(c.Elapsed) / float64(time.Millisecond),
+		Redirects:   c.Redirects,
+		Location:    c.Location,
+		Children:    children,
+		Metadata:    c.Metadata,
+		Structured:  c.Structured,
+		Scraped:     c.Scraped,
+		Warnings:    c.Warnings,
+		Elements:    c.Elements,
+	}
+}
+
+// hash encodes the checksum of the body, empty when it was never computed
+func hash(c content.Content) string {
+	if c.BodyHash == [32]byte{} {
+		return ""
+	}
+	return hex.EncodeToString(c.BodyHash[:])
+}
+
+// NewPages converts contents into pages sorted by url along with their click depth from the seeds
+func NewPages(contents []content.Content, seeds []string) []Page {
+	pages := make([]Page, len(contents))
+	for i, c := range contents {
+		pages[i] = NewPage(c)
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].URL < pages[j].URL })
+
+	depths := ClickDepths(pages, seeds)
+	for i := range pages {
+		pages[i].Depth = depthOf(depths, pages[i].URL)
+	}
+	return pages
+}
+
+// ClickDepths computes the shortest number of clicks from any seed to every url linked,
+// urls that cannot be reached are left out
+func ClickDepths(pages []Page, seeds []string) map[string]int {
+	return analysis.ClickDepths(Links(pages), seeds)
+}
+
+// Links maps every page to the urls it links to
+func Links(pages []Page) map[string][]string {
+	links := map[string][]string{}
+	for _, p := range pages {
+		links[p.URL] = p.Children
+	}
+	return links
+}
+
+// Seeds lists the urls of the pages at depth zero, where the crawl started
+func Seeds(pages []Page) []string {
+	seeds := []string{}
+	for _, p := range pages {
+		if p.Depth == 0 {
+			seeds = append(seeds, p.URL)
+		}
+	}
+	return seeds
+}
+
+// depthOf returns the click depth of a url or -1 when it cannot be reached
+func depthOf(depths map[string]int, url string) int {
+	if depth, ok := depths[url]; ok {
+		return depth
+	}
+	return -1
+}
+
+// node is a url in the link graph, pages not stored are nodes without attributes
+type node struct {
+	URL         string
+	ContentType string
+	StatusCode  int
+	Depth       int
+}
+
+// edge is a link between two urls, the element is where the link was found
+type edge struct {
+	Source  string
+	Target  string
+	Element string
+}
+
+// graph lists the nodes and edges of the report pages, nodes are sorted by url and
+// urls linked but not stored are one click deeper than their shallowest parent
+func graph(r Report) ([]node, []edge) {
+	nodes := map[string]node{}
+	for _, p := range r.Pages {
+		nodes[p.URL] = node{URL: p.URL, ContentType: p.ContentType, StatusCode: p.StatusCode, Depth: p.Depth}
+	}
+
+	edges := []edge{}
+	linked := map[string]node{}
+	for _, p := range r.Pages {
+		for _, child := range p.Children {
+			edges = append(edges, edge{Source: p.URL, Target: child, Element: p.Elements[child]})
+			if _, ok := nodes[child]; ok {
+				continue
+			}
+			n, ok := linked[child]
+			if !ok {
+				n = node{URL: child, Depth: -1}
+			}
+			if p.Depth >= 0 && (n.Depth < 0 || p.Depth+1 < n.Depth) {
+				n.Depth = p.Depth + 1
+			}
+			linked[child] = n
+		}
+	}
+
+	sorted := make([]node, 0, len(nodes)+len(linked))
+	for _, n := range nodes {
+		sorted = append(sorted, n)
+	}
+	for _, n := range linked {
+		sorted = append(sorted, n)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].URL < sorted[j].URL })
+	return sorted, edges
+}
@@ -0,0 +1,142 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+)
+
+func newContent(t *testing.T, address string, children ...string) content.Content {
+	c, err := content.NewContent(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.ContentType = "text/html"
+	c.StatusCode = 200
+	for _, child := range children {
+		c.AddChild(child, "a")
+	}
+	return c
+}
+
+func sampleReport(t *testing.T) report.Report {
+	contents := []content.Content{
+		newContent(t, "http://domain.com/page2", "http://domain.com/missing"),
+		newContent(t, "http://domain.com/", "http://domain.com/page1"),
+		newContent(t, "http://domain.com/page1", "http://domain.com/page2", "http://domain.com/"),
+		newContent(t, "http://domain.com/orphan"),
+	}
+	return report.Report{Pages: report.NewPages(contents, []string{"http://domain.com/"})}
+}
+
+func TestReport_NewPages(t *testing.T) {
+	r := sampleReport(t)
+
+	actualURLs := []string{}
+	actualDepths := map[string]int{}
+	for _, p := range r.Pages {
+		actualURLs = append(actualURLs, p.URL)
+		actualDepths[p.URL] = p.Depth
+	}
+
+	expectedURLs := []string{"http://domain.com/", "http://domain.com/orphan", "http://domain.com/page1", "http://domain.com/page2"}
+	if diff := cmp.Diff(expectedURLs, actualURLs); diff != "" {
+		t.Errorf("pages should be sorted (-expected +actual):\n%s", diff)
+	}
+
+	expectedDepths := map[string]int{
+		"http://domain.com/":       0,
+		"http://domain.com/orphan": -1,
+		"http://domain.com/page1":  1,
+		"http://domain.com/page2":  2,
+	}
+	if diff := cmp.Diff(expectedDepths, actualDepths); diff != "" {
+		t.Errorf("unexpected depths (-expected +actual):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{"http://domain.com/", "http://domain.com/page2"}, r.Pages[2].Children); diff != "" {
+		t.Errorf("children should be sorted (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestReport_NewWriter(t *testing.T) {
+	for _, format := range report.Formats() {
+		if _, err := report.NewWriter(format); err != nil {
+			t.Errorf("unexpected error for %s: %v", format, err)
+		}
+	}
+
+	if _, err := report.NewWriter("unknown"); err == nil {
+		t.Errorf("expected error for unknown format")
+	}
+}
+
+func TestJSONWriter_Write(t *testing.T) {
+	type testCase struct {
+		testName string
+		indented bool
+	}
+
+	testCases := []testCase{
+		{testName: "compact", indented: false},
+		{testName: "indented", indented: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := report.NewJSONWriter(tc.indented).Write(&buf, sampleReport(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if strings.Contains(buf.String(), "\n") != tc.indented {
+				t.Errorf("unexpected indentation in %s", buf.String())
+			}
+
+			var actual report.Report
+			if err := json.Unmarshal(buf.Bytes(), &actual); err != nil {
+				t.Fatal(err)
+			}
+			if len(actual.Pages) != 4 || actual.Pages[3].Depth != 2 {
+				t.Errorf("unexpected report %#v", actual)
+			}
+		})
+	}
+}
+
+func TestRawWriter_Write(t *testing.T) {
+	r := sampleReport(t)
+	r.Partial = true
+	r.Queued = 3
+	r.Skipped = []report.Outcome{{URL: "http://domain.com/copy", Stage: "deduplication", Reason: "repeated content", StatusCode: 200}}
+	r.Failed = []report.Outcome{{URL: "http://domain.com/missing", Stage: "download", Reason: "response status not 200", StatusCode: 404}}
+
+	var buf bytes.Buffer
+	if err := report.NewRawWriter().Write(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# partial report: 3 queued and 0 in-flight urls unfinished
+http://domain.com/
+  |- http://domain.com/page1
+http://domain.com/orphan
+http://domain.com/page1
+  |- http://domain.com/
+  |- http://domain.com/page2
+http://domain.com/page2
+  |- http://domain.com/missing
+# skipped: 1 urls
+http://domain.com/copy at deduplication: repeated content (200)
+# failed: 1 urls
+http://domain.com/missing at download: response status not 200 (404)
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("unexpected output (-expected +actual):\n%s", diff)
+	}
+}
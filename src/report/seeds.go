@@ -0,0 +1,89 @@
+package report
+
+import (
+	"net/url"
+	"sort"
+
+	"github.com/thiagolcmelo/webcrawler/src/analysis"
+	"github.com/thiagolcmelo/webcrawler/src/stats"
+)
+
+// Seed is a seed as given and the addresses it is crawled from, seeds without scheme have one per scheme
+type Seed struct {
+	Seed      string
+	Addresses []string
+}
+
+// SeedReport lists the pages in the scope of a seed, along with the statistics of its urls
+type SeedReport struct {
+	Seed  string       `json:"seed"`
+	Pages []string     `json:"pages"`
+	Stats *stats.Stats `json:"stats,omitempty"`
+}
+
+// Scopes tells the seed whose scope a url is in: the seed it is reached from in the fewest clicks,
+// or the first seed in its host when it is not reached from any
+type Scopes struct {
+	seeds   []Seed
+	nearest map[string]string
+}
+
+// NewScopes computes the scopes of the seeds over the links of the pages
+func NewScopes(pages []Page, seeds []Seed) Scopes {
+	addresses := []string{}
+	seedOf := map[string]string{}
+	for _, seed := range seeds {
+		for _, address := range seed.Addresses {
+			addresses = append(addresses, address)
+			if _, ok := seedOf[address]; !ok {
+				seedOf[address] = seed.Seed
+			}
+		}
+	}
+
+	nearest := map[string]string{}
+	for u, address := range analysis.NearestSeeds(Links(pages), addresses) {
+		nearest[u] = seedOf[address]
+	}
+	return Scopes{seeds: seeds, nearest: nearest}
+}
+
+// Of returns the seed whose scope a url is in, if any
+func (s Scopes) Of(address string) (string, bool) {
+	if seed, ok := s.nearest[address]; ok {
+		return seed, true
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", false
+	}
+	for _, seed := range s.seeds {
+		for _, seedAddress := range seed.Addresses {
+			if su, err := url.Parse(seedAddress); err == nil && su.Hostname() == u.Hostname() {
+				return seed.Seed, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Group lists the pages in the scope of each seed, in the order the seeds were given
+func (s Scopes) Group(pages []Page) []SeedReport {
+	groups := make([]SeedReport, len(s.seeds))
+	index := map[string]int{}
+	for i, seed := range s.seeds {
+		groups[i] = SeedReport{Seed: seed.Seed, Pages: []string{}}
+		if _, ok := index[seed.Seed]; !ok {
+			index[seed.Seed] = i
+		}
+	}
+	for _, p := range pages {
+		if seed, ok := s.Of(p.URL); ok {
+			groups[index[seed]].Pages = append(groups[index[seed]].Pages, p.URL)
+		}
+	}
+	for i := range groups {
+		sort.Strings(groups[i].Pages)
+	}
+	return groups
+}
@@ -0,0 +1,105 @@
+package report_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+	"github.com/thiagolcmelo/webcrawler/src/stats"
+)
+
+func sampleSeeds() []report.Seed {
+	return []report.Seed{
+		{Seed: "a.com", Addresses: []string{"https://a.com/", "http://a.com/"}},
+		{Seed: "https://b.com/", Addresses: []string{"https://b.com/"}},
+	}
+}
+
+func samplePages(t *testing.T) []report.Page {
+	contents := []content.Content{
+		newContent(t, "http://a.com/", "http://a.com/page"),
+		newContent(t, "http://a.com/page", "http://a.com/missing"),
+		newContent(t, "https://b.com/", "https://b.com/page"),
+		newContent(t, "https://b.com/page"),
+		newContent(t, "https://b.com/unlinked"),
+		newContent(t, "https://c.com/"),
+	}
+	seeds := []string{}
+	for _, seed := range sampleSeeds() {
+		seeds = append(seeds, seed.Addresses...)
+	}
+	return report.NewPages(contents, seeds)
+}
+
+func TestScopes_Of(t *testing.T) {
+	scopes := report.NewScopes(samplePages(t), sampleSeeds())
+
+	type testCase struct {
+		testName      string
+		url           string
+		expectedSeed  string
+		expectedFound bool
+	}
+
+	testCases := []testCase{
+		{testName: "seed_address", url: "http://a.com/", expectedSeed: "a.com", expectedFound: true},
+		{testName: "reached_from_seed", url: "http://a.com/page", expectedSeed: "a.com", expectedFound: true},
+		{testName: "linked_but_not_stored", url: "http://a.com/missing", expectedSeed: "a.com", expectedFound: true},
+		{testName: "unlinked_in_seed_host", url: "https://b.com/unlinked", expectedSeed: "https://b.com/", expectedFound: true},
+		{testName: "other_host", url: "https://c.com/", expectedSeed: "", expectedFound: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			seed, found := scopes.Of(tc.url)
+			if seed != tc.expectedSeed || found != tc.expectedFound {
+				t.Errorf("expected %s and %v, got %s and %v", tc.expectedSeed, tc.expectedFound, seed, found)
+			}
+		})
+	}
+}
+
+func TestScopes_Group(t *testing.T) {
+	pages := samplePages(t)
+	expected := []report.SeedReport{
+		{Seed: "a.com", Pages: []string{"http://a.com/", "http://a.com/page"}},
+		{Seed: "https://b.com/", Pages: []string{"https://b.com/", "https://b.com/page", "https://b.com/unlinked"}},
+	}
+	if diff := cmp.Diff(expected, report.NewScopes(pages, sampleSeeds()).Group(pages)); diff != "" {
+		t.Errorf("unexpected groups (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestRawWriter_WriteSeeds(t *testing.T) {
+	pages := samplePages(t)
+	r := report.Report{Pages: pages, Seeds: report.NewScopes(pages, sampleSeeds()).Group(pages)}
+	r.Seeds[1].Stats = &stats.Stats{Stages: map[string]stats.StageStats{}}
+
+	var buf bytes.Buffer
+	if err := report.NewRawWriter().Write(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	var statsBuf bytes.Buffer
+	if err := r.Seeds[1].Stats.WriteText(&statsBuf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# seed a.com: 2 pages
+http://a.com/
+  |- http://a.com/page
+http://a.com/page
+  |- http://a.com/missing
+# seed https://b.com/: 3 pages
+https://b.com/
+  |- https://b.com/page
+https://b.com/page
+https://b.com/unlinked
+` + statsBuf.String() + `# out of any seed scope: 1 pages
+https://c.com/
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("unexpected output (-expected +actual):\n%s", diff)
+	}
+}
@@ -0,0 +1,149 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/thiagolcmelo/webcrawler/src/content"
+)
+
+// StreamWriter defines an interface for writing a report one page at a time
+type StreamWriter interface {
+	WritePage(Page) error
+	Flush() error
+}
+
+// streamWriters maps each format that can be streamed to a factory for its StreamWriter,
+// given where to write and the scraped fields to write
+var streamWriters = map[string]func(io.Writer, []string) StreamWriter{
+	"csv":       func(w io.Writer, fields []string) StreamWriter { return NewCSVWriter(w, false, fields...) },
+	"csv-edges": func(w io.Writer, _ []string) StreamWriter { return NewCSVWriter(w, true) },
+	"ndjson":    func(w io.Writer, _ []string) StreamWriter { return NewNDJSONWriter(w) },
+}
+
+// StreamFormats lists the formats known by NewStreamWriter
+func StreamFormats() []string {
+	formats := make([]string, 0, len(streamWriters))
+	for format := range streamWriters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// NewStreamWriter creates the StreamWriter for a format writing to w, formats with room for them write the scraped fields
+func NewStreamWriter(format string, w io.Writer, fields ...string) (StreamWriter, error) {
+	factory, ok := streamWriters[format]
+	if !ok {
+		return nil, fmt.Errorf("format [%s] cannot be streamed, it can be %s", format, strings.Join(StreamFormats(), ", "))
+	}
+	return factory(w, fields), nil
+}
+
+// sortedWriter writes the pages of a finished report, already sorted, through a StreamWriter
+type sortedWriter struct {
+	newStream func(io.Writer, []string) StreamWriter
+	fields    []string
+}
+
+// sorted is the factory of the Writer for a format that can be streamed
+func sorted(format string) func([]string) Writer {
+	return func(fields []string) Writer {
+		return &sortedWriter{newStream: streamWriters[format], fields: fields}
+	}
+}
+
+// Write writes the report pages in order, the report state and stats have no place in a stream
+func (sw *sortedWriter) Write(w io.Writer, r Report) error {
+	stream := sw.newStream(w, sw.fields)
+	for _, p := range r.Pages {
+		if err := stream.WritePage(p); err != nil {
+			return err
+		}
+	}
+	return stream.Flush()
+}
+
+// Streamer writes contents through a StreamWriter as soon as they are stored, the depth
+// of a page is the click depth in which its url was first discovered during the crawl and,
+// with several seeds, its seed is the one of the page it was first discovered in
+type Streamer struct {
+	mu     sync.Mutex
+	stream StreamWriter
+	depths map[string]int
+	seeds  map[string]string
+	groups map[string]struct{}
+	err    error
+}
+
+// NewStreamer is a factory for Streamer
+func NewStreamer(stream StreamWriter) *Streamer {
+	return &Streamer{
+		stream: stream,
+		depths: map[string]int{},
+		seeds:  map[string]string{},
+		groups: map[string]struct{}{},
+	}
+}
+
+// AddSeeds registers urls found at depth zero, each one a seed of its own
+func (s *Streamer) AddSeeds(seeds ...string) {
+	for _, seed := range seeds {
+		s.AddSeed(seed, seed)
+	}
+}
+
+// AddSeed registers the addresses a seed is crawled from, found at depth zero
+func (s *Streamer) AddSeed(seed string, addresses ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups[seed] = struct{}{}
+	for _, address := range addresses {
+		s.depths[address] = 0
+		if _, ok := s.seeds[address]; !ok {
+			s.seeds[address] = seed
+		}
+	}
+}
+
+// Add writes the page of a content and flushes it, so it reaches the output right away
+func (s *Streamer) Add(c content.Content) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	page := NewPage(c)
+	page.Depth = depthOf(s.depths, page.URL)
+	if len(s.groups) > 1 {
+		page.Seed = s.seeds[page.URL]
+	}
+	if page.Depth >= 0 {
+		for _, child := range page.Children {
+			if _, ok := s.depths[child]; !ok {
+				s.depths[child] = page.Depth + 1
+				s.seeds[child] = s.seeds[page.URL]
+			}
+		}
+	}
+
+	err := s.stream.WritePage(page)
+	if err == nil {
+		err = s.stream.Flush()
+	}
+	if err != nil && s.err == nil {
+		s.err = err
+	}
+	return err
+}
+
+// Flush flushes the stream and returns the first error found while streaming
+func (s *Streamer) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.stream.Flush(); err != nil && s.err == nil {
+		s.err = err
+	}
+	return s.err
+}
@@ -0,0 +1,230 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+)
+
+func TestCSVWriter_WritePage(t *testing.T) {
+	type testCase struct {
+		testName string
+		perEdge  bool
+		expected string
+	}
+
+	testCases := []testCase{
+		{
+			testName: "row_per_page",
+			perEdge:  false,
+			expected: `url,content_type,status_code,depth,links
+http://domain.com/,text/html,200,0,1
+http://domain.com/orphan,text/html,200,-1,0
+http://domain.com/page1,text/html,200,1,2
+http://domain.com/page2,text/html,200,2,1
+`,
+		},
+		{
+			testName: "row_per_edge",
+			perEdge:  true,
+			expected: `source,target,element,source_depth
+http://domain.com/,http://domain.com/page1,a,0
+http://domain.com/page1,http://domain.com/,a,1
+http://domain.com/page1,http://domain.com/page2,a,1
+http://domain.com/page2,http://domain.com/missing,a,2
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			var buf bytes.Buffer
+			cw := report.NewCSVWriter(&buf, tc.perEdge)
+			for _, p := range sampleReport(t).Pages {
+				if err := cw.WritePage(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := cw.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.expected, buf.String()); diff != "" {
+				t.Errorf("unexpected output (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCSVWriter_WriteScrapedFields(t *testing.T) {
+	var buf bytes.Buffer
+	cw := report.NewCSVWriter(&buf, false, "links", "price")
+	err := cw.WritePage(report.Page{
+		URL:     "http://domain.com/",
+		Scraped: map[string][]string{"links": {"/a", "/b"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `url,content_type,status_code,depth,links,scraped_links,scraped_price
+http://domain.com/,,0,0,0,/a | /b,
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("unexpected output (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestCSVWriter_FlushWritesHeader(t *testing.T) {
+	var buf bytes.Buffer
+	if err := report.NewCSVWriter(&buf, false).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "url,content_type,status_code,depth,links\n" {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestNDJSONWriter_Write(t *testing.T) {
+	rw, err := report.NewWriter("ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := rw.Write(&buf, sampleReport(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		var p report.Page
+		if err := json.Unmarshal([]byte(line), &p); err != nil {
+			t.Fatal(err)
+		}
+		if p.URL != sampleReport(t).Pages[i].URL {
+			t.Errorf("expected %s at line %d, got %s", sampleReport(t).Pages[i].URL, i, p.URL)
+		}
+	}
+}
+
+func TestNewStreamWriter(t *testing.T) {
+	for _, format := range report.StreamFormats() {
+		if _, err := report.NewStreamWriter(format, &bytes.Buffer{}); err != nil {
+			t.Errorf("unexpected error for %s: %v", format, err)
+		}
+	}
+
+	if _, err := report.NewStreamWriter("json", &bytes.Buffer{}); err == nil {
+		t.Errorf("expected error for a format that cannot be streamed")
+	}
+}
+
+type fakeStreamWriter struct {
+	pages []report.Page
+	err   error
+}
+
+func (fsw *fakeStreamWriter) WritePage(p report.Page) error {
+	fsw.pages = append(fsw.pages, p)
+	return fsw.err
+}
+
+func (fsw *fakeStreamWriter) Flush() error {
+	return nil
+}
+
+func TestStreamer_Add(t *testing.T) {
+	fsw := &fakeStreamWriter{}
+	streamer := report.NewStreamer(fsw)
+	streamer.AddSeeds("http://domain.com/")
+
+	// pages arrive in the order they are stored, parents before their children
+	for _, c := range []struct {
+		address  string
+		children []string
+	}{
+		{"http://domain.com/", []string{"http://domain.com/page1"}},
+		{"http://domain.com/page1", []string{"http://domain.com/page2", "http://domain.com/"}},
+		{"http://domain.com/page2", nil},
+		{"http://domain.com/orphan", nil},
+	} {
+		if err := streamer.Add(newContent(t, c.address, c.children...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	actual := map[string]int{}
+	for _, p := range fsw.pages {
+		actual[p.URL] = p.Depth
+	}
+	expected := map[string]int{
+		"http://domain.com/":       0,
+		"http://domain.com/page1":  1,
+		"http://domain.com/page2":  2,
+		"http://domain.com/orphan": -1,
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected depths (-expected +actual):\n%s", diff)
+	}
+
+	if err := streamer.Flush(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestStreamer_FlushReturnsFirstError(t *testing.T) {
+	expectedErr := errors.New("broken pipe")
+	streamer := report.NewStreamer(&fakeStreamWriter{err: expectedErr})
+
+	if err := streamer.Add(newContent(t, "http://domain.com/")); !errors.Is(err, expectedErr) {
+		t.Errorf("expected %v, got %v", expectedErr, err)
+	}
+	if err := streamer.Flush(); !errors.Is(err, expectedErr) {
+		t.Errorf("expected %v, got %v", expectedErr, err)
+	}
+}
+
+func TestStreamer_AddSeed(t *testing.T) {
+	fsw := &fakeStreamWriter{}
+	streamer := report.NewStreamer(fsw)
+	streamer.AddSeed("a.com", "https://a.com/", "http://a.com/")
+	streamer.AddSeed("https://b.com/", "https://b.com/")
+
+	for _, c := range []content.Content{
+		newContent(t, "http://a.com/", "http://a.com/page"),
+		newContent(t, "https://b.com/", "https://b.com/page"),
+		newContent(t, "http://a.com/page"),
+		newContent(t, "https://b.com/page"),
+	} {
+		if err := streamer.Add(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	actual := map[string]string{}
+	for _, p := range fsw.pages {
+		actual[p.URL] = p.Seed
+	}
+	expected := map[string]string{
+		"http://a.com/":      "a.com",
+		"http://a.com/page":  "a.com",
+		"https://b.com/":     "https://b.com/",
+		"https://b.com/page": "https://b.com/",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected seeds (-expected +actual):\n%s", diff)
+	}
+}
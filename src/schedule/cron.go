@@ -0,0 +1,195 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSchedule should be used when a schedule is not a valid cron expression
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// shortcuts are the named schedules, as in cron
+var shortcuts = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field describes the values one of the five fields of a cron expression accepts
+type field struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	minutes    = field{name: "minute", min: 0, max: 59}
+	hours      = field{name: "hour", min: 0, max: 23}
+	daysOfWeek = field{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+	days       = field{name: "day of month", min: 1, max: 31}
+	months     = field{name: "month", min: 1, max: 12, names: []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+)
+
+// bits is a set of the values a field matches
+type bits uint64
+
+func (b bits) has(v int) bool {
+	return b&(1<<uint(v)) != 0
+}
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	spec    string
+	minute  bits
+	hour    bits
+	day     bits
+	month   bits
+	weekday bits
+	// anyDay and anyWeekday tell the fields that start with *, as in cron a day matches both
+	// when either is *, and matches any of them otherwise
+	anyDay     bool
+	anyWeekday bool
+}
+
+// Parse reads a cron expression with the minute, hour, day of month, month and day of week
+// fields, each a * or a list of values and ranges with optional steps, like "*/15 9-17 * * mon-fri".
+// Months and days of week can be named and the shortcuts @yearly, @monthly, @weekly, @daily and
+// @hourly are accepted
+func Parse(spec string) (Schedule, error) {
+	expression := strings.TrimSpace(spec)
+	if shortcut, ok := shortcuts[strings.ToLower(expression)]; ok {
+		expression = shortcut
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("%w [%s]: expected 5 fields, got %d", ErrInvalidSchedule, spec, len(fields))
+	}
+
+	s := Schedule{
+		spec:       spec,
+		anyDay:     strings.HasPrefix(fields[2], "*"),
+		anyWeekday: strings.HasPrefix(fields[4], "*"),
+	}
+	var err error
+	for i, target := range []struct {
+		bits  *bits
+		field field
+	}{{&s.minute, minutes}, {&s.hour, hours}, {&s.day, days}, {&s.month, months}, {&s.weekday, daysOfWeek}} {
+		if *target.bits, err = parseField(fields[i], target.field); err != nil {
+			return Schedule{}, fmt.Errorf("%w [%s]: %v", ErrInvalidSchedule, spec, err)
+		}
+	}
+	// 7 is also sunday
+	if s.weekday.has(7) {
+		s.weekday |= 1
+	}
+	return s, nil
+}
+
+// parseField reads a comma separated list of ranges
+func parseField(text string, f field) (bits, error) {
+	var b bits
+	for _, part := range strings.Split(text, ",") {
+		rangeText, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step [%s] in the %s", stepText, f.name)
+			}
+		}
+
+		var low, high int
+		switch {
+		case rangeText == "*":
+			low, high = f.min, f.max
+		case strings.Contains(rangeText, "-"):
+			lowText, highText, _ := strings.Cut(rangeText, "-")
+			var err error
+			if low, err = f.value(lowText); err != nil {
+				return 0, err
+			}
+			if high, err = f.value(highText); err != nil {
+				return 0, err
+			}
+		default:
+			var err error
+			if low, err = f.value(rangeText); err != nil {
+				return 0, err
+			}
+			high = low
+			if hasStep {
+				high = f.max
+			}
+		}
+		if low > high {
+			return 0, fmt.Errorf("invalid range [%s] in the %s", rangeText, f.name)
+		}
+
+		for v := low; v <= high; v += step {
+			b |= 1 << uint(v)
+		}
+	}
+	return b, nil
+}
+
+// value reads a number or a name within the bounds of the field
+func (f field) value(text string) (int, error) {
+	for i, name := range f.names {
+		if name != "" && strings.EqualFold(text, name) {
+			return i, nil
+		}
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s [%s], it must be between %d and %d", f.name, text, f.min, f.max)
+	}
+	return v, nil
+}
+
+// String returns the expression the schedule was parsed from
+func (s Schedule) String() string {
+	return s.spec
+}
+
+// Next returns the first time after t the schedule matches, in the location of t, or the zero
+// time when it never matches, like on february 30
+func (s Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+
+	// every valid schedule matches within a leap cycle
+	limit := t.AddDate(8, 0, 0)
+	for t.Before(limit) {
+		year, month, day := t.Date()
+		switch {
+		case !s.month.has(int(month)):
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+		case !s.matchesDay(t):
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+		case !s.hour.has(t.Hour()):
+			t = time.Date(year, month, day, t.Hour()+1, 0, 0, 0, loc)
+		case !s.minute.has(t.Minute()):
+			t = time.Date(year, month, day, t.Hour(), t.Minute()+1, 0, 0, loc)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s Schedule) matchesDay(t time.Time) bool {
+	day, weekday := s.day.has(t.Day()), s.weekday.has(int(t.Weekday()))
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
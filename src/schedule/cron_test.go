@@ -0,0 +1,59 @@
+package schedule_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/thiagolcmelo/webcrawler/src/schedule"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	type testCase struct {
+		testName string
+		spec     string
+		after    string
+		expected string
+	}
+
+	testCases := []testCase{
+		{testName: "every_minute", spec: "* * * * *", after: "2024-03-10 10:15:30", expected: "2024-03-10 10:16:00"},
+		{testName: "nightly", spec: "0 2 * * *", after: "2024-03-10 10:15:00", expected: "2024-03-11 02:00:00"},
+		{testName: "same_day", spec: "30 11 * * *", after: "2024-03-10 10:15:00", expected: "2024-03-10 11:30:00"},
+		{testName: "exactly_on_time_is_next_occurrence", spec: "0 2 * * *", after: "2024-03-11 02:00:00", expected: "2024-03-12 02:00:00"},
+		{testName: "steps", spec: "*/20 * * * *", after: "2024-03-10 10:41:00", expected: "2024-03-10 11:00:00"},
+		{testName: "range_with_step", spec: "0 9-17/4 * * *", after: "2024-03-10 13:00:00", expected: "2024-03-10 17:00:00"},
+		{testName: "list", spec: "5,35 * * * *", after: "2024-03-10 10:06:00", expected: "2024-03-10 10:35:00"},
+		{testName: "weekdays_by_name", spec: "0 8 * * mon-fri", after: "2024-03-09 09:00:00", expected: "2024-03-11 08:00:00"},
+		{testName: "sunday_as_seven", spec: "0 0 * * 7", after: "2024-03-10 10:00:00", expected: "2024-03-17 00:00:00"},
+		{testName: "month_by_name", spec: "0 0 1 jun *", after: "2024-03-10 10:00:00", expected: "2024-06-01 00:00:00"},
+		{testName: "day_of_month_or_week", spec: "0 0 13 * fri", after: "2024-03-10 10:00:00", expected: "2024-03-13 00:00:00"},
+		{testName: "leap_day", spec: "0 0 29 2 *", after: "2024-03-10 10:00:00", expected: "2028-02-29 00:00:00"},
+		{testName: "shortcut", spec: "@monthly", after: "2024-12-10 10:00:00", expected: "2025-01-01 00:00:00"},
+		{testName: "never", spec: "0 0 30 2 *", after: "2024-03-10 10:00:00", expected: "0001-01-01 00:00:00"},
+	}
+
+	const layout = "2006-01-02 15:04:05"
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			s, err := schedule.Parse(tc.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			after, _ := time.Parse(layout, tc.after)
+			if actual := s.Next(after).Format(layout); actual != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "* * * * funday", "@often"} {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := schedule.Parse(spec); !errors.Is(err, schedule.ErrInvalidSchedule) {
+				t.Errorf("expected %v, got %v", schedule.ErrInvalidSchedule, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/thiagolcmelo/webcrawler/src/diff"
+	"github.com/thiagolcmelo/webcrawler/src/jobs"
+	"github.com/thiagolcmelo/webcrawler/src/stats"
+)
+
+// ErrInvalidRunFile should be used when a persisted run cannot be read
+var ErrInvalidRunFile = errors.New("invalid run file")
+
+// Run is a crawl of a scheduled job. The diff compares it to the previous finished run of the
+// same job, when there is one
+type Run struct {
+	Name     string       `json:"name"`
+	Job      jobs.Job     `json:"job"`
+	Stats    *stats.Stats `json:"stats,omitempty"`
+	Previous string       `json:"previous,omitempty"`
+	Diff     *diff.Diff   `json:"diff,omitempty"`
+}
+
+// History persists the runs of every scheduled job as JSON files, in a directory per job
+type History struct {
+	dir string
+}
+
+// OpenHistory creates the directory of the history if it does not exist yet
+func OpenHistory(dir string) (*History, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &History{dir: dir}, nil
+}
+
+// Save writes a run, replacing the previous version of it
+func (h *History) Save(run Run) error {
+	dir := filepath.Join(h.dir, run.Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// written through a temporary file, so a crash never leaves it half written
+	tmp, err := os.CreateTemp(dir, run.Job.ID+".json.*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, run.Job.ID+".json"))
+}
+
+// Delete removes a run
+func (h *History) Delete(run Run) error {
+	err := os.Remove(filepath.Join(h.dir, run.Name, run.Job.ID+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Names lists the jobs with runs, sorted
+func (h *History) Names() ([]string, error) {
+	entries, err := os.ReadDir(h.dir)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// Runs reads the runs of a job, oldest first
+func (h *History) Runs(name string) ([]Run, error) {
+	dir := filepath.Join(h.dir, name)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Run{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []Run{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var run Run
+		if err := json.Unmarshal(data, &run); err != nil || run.Job.ID == "" {
+			return nil, fmt.Errorf("%w [%s]", ErrInvalidRunFile, filepath.Join(name, entry.Name()))
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].Job.Created.Equal(runs[j].Job.Created) {
+			return runs[i].Job.Created.Before(runs[j].Job.Created)
+		}
+		return runs[i].Job.ID < runs[j].Job.ID
+	})
+	return runs, nil
+}
@@ -0,0 +1,279 @@
+// Package schedule crawls jobs on cron schedules, keeping a history of their runs where every
+// run is compared to the previous one and runs older than a retention are pruned
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thiagolcmelo/webcrawler/src/diff"
+	"github.com/thiagolcmelo/webcrawler/src/jobs"
+)
+
+var (
+	// ErrInvalidName should be used when the name of a scheduled job is empty, repeated or
+	// cannot be used as a directory name
+	ErrInvalidName = errors.New("invalid name")
+	// ErrInvalidRetention should be used when a retention is not a duration
+	ErrInvalidRetention = errors.New("invalid retention")
+	// ErrUnknownName should be used when no scheduled job has the requested name
+	ErrUnknownName = errors.New("unknown scheduled job")
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Entry is a job crawled on a schedule, its request is the same a job of serve takes
+type Entry struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	jobs.Request
+}
+
+// Config lists the scheduled jobs and for how long their runs are kept, like "720h" or "30d",
+// runs are kept forever when it is empty
+type Config struct {
+	Retention string  `json:"retention"`
+	Jobs      []Entry `json:"jobs"`
+}
+
+// LoadConfig reads a JSON config, unknown fields are rejected so typos do not go unnoticed
+func LoadConfig(r io.Reader) (Config, error) {
+	var config Config
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// ParseRetention reads a duration as time.ParseDuration does, also accepting a number of days
+func ParseRetention(text string) (time.Duration, error) {
+	if text == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(text, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%w [%s], it can be a duration like 720h or a number of days like 30d", ErrInvalidRetention, text)
+	}
+	return d, nil
+}
+
+// scheduled is an entry along with its parsed schedule
+type scheduled struct {
+	Entry
+	schedule Schedule
+}
+
+// Scheduler submits the scheduled jobs to a manager when their schedules match, and records
+// their runs in a history
+type Scheduler struct {
+	manager   *jobs.Manager
+	history   *History
+	retention time.Duration
+	entries   []scheduled
+}
+
+// NewScheduler validates the config and creates a Scheduler for its jobs
+func NewScheduler(manager *jobs.Manager, history *History, config Config) (*Scheduler, error) {
+	retention, err := ParseRetention(config.Retention)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Scheduler{manager: manager, history: history, retention: retention}
+	names := map[string]bool{}
+	for _, e := range config.Jobs {
+		if !validName.MatchString(e.Name) {
+			return nil, fmt.Errorf("%w [%s], it must start with a letter or digit followed by letters, digits, dots, dashes or underscores", ErrInvalidName, e.Name)
+		}
+		if names[e.Name] {
+			return nil, fmt.Errorf("%w [%s], it is used by more than one job", ErrInvalidName, e.Name)
+		}
+		names[e.Name] = true
+
+		schedule, err := Parse(e.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("job [%s]: %w", e.Name, err)
+		}
+		if err := jobs.Validate(e.Request); err != nil {
+			return nil, fmt.Errorf("job [%s]: %w", e.Name, err)
+		}
+		s.entries = append(s.entries, scheduled{Entry: e, schedule: schedule})
+	}
+	return s, nil
+}
+
+// Next returns when every scheduled job runs next, by name
+func (s *Scheduler) Next(now time.Time) map[string]time.Time {
+	next := map[string]time.Time{}
+	for _, e := range s.entries {
+		next[e.Name] = e.schedule.Next(now)
+	}
+	return next
+}
+
+// Run crawls every job when its schedule matches until the context is done, a run still going
+// then is canceled and recorded with its partial results. Runs left unfinished by a previous
+// process are recorded first. A job is never run twice at the same time: when a run takes
+// longer than the schedule, the times missed are skipped. Notify is called after every run
+func (s *Scheduler) Run(ctx context.Context, notify func(Run, error)) {
+	if notify == nil {
+		notify = func(Run, error) {}
+	}
+
+	var wg sync.WaitGroup
+	for _, e := range s.entries {
+		wg.Add(1)
+		go func(e scheduled) {
+			defer wg.Done()
+			s.resume(ctx, e.Name, notify)
+
+			for {
+				next := e.schedule.Next(time.Now())
+				if next.IsZero() {
+					return
+				}
+				timer := time.NewTimer(time.Until(next))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+				notify(s.run(ctx, e))
+			}
+		}(e)
+	}
+	wg.Wait()
+}
+
+// Trigger runs a scheduled job right away and waits for it
+func (s *Scheduler) Trigger(ctx context.Context, name string) (Run, error) {
+	for _, e := range s.entries {
+		if e.Name == name {
+			return s.run(ctx, e)
+		}
+	}
+	return Run{Name: name}, fmt.Errorf("%w [%s]", ErrUnknownName, name)
+}
+
+// resume records the runs of a job the history has as not done
+func (s *Scheduler) resume(ctx context.Context, name string, notify func(Run, error)) {
+	runs, err := s.history.Runs(name)
+	if err != nil {
+		notify(Run{Name: name}, err)
+		return
+	}
+	for _, run := range runs {
+		if !run.Job.IsDone() {
+			notify(s.complete(ctx, run))
+		}
+	}
+}
+
+// run submits a job and records its run once it is done
+func (s *Scheduler) run(ctx context.Context, e scheduled) (Run, error) {
+	job, err := s.manager.Submit(e.Request)
+	if err != nil {
+		return Run{Name: e.Name}, err
+	}
+	run := Run{Name: e.Name, Job: job}
+	if err := s.history.Save(run); err != nil {
+		return run, err
+	}
+	return s.complete(ctx, run)
+}
+
+// complete waits for the job of a run, canceling it when the context is done, and records the
+// run with its stats and its diff to the previous finished run
+func (s *Scheduler) complete(ctx context.Context, run Run) (Run, error) {
+	job, err := s.manager.Wait(ctx, run.Job.ID)
+	if ctx.Err() != nil {
+		if _, err := s.manager.Cancel(run.Job.ID); err != nil && !errors.Is(err, jobs.ErrJobFinished) {
+			return run, err
+		}
+		job, err = s.manager.Wait(context.Background(), run.Job.ID)
+	}
+	if err != nil {
+		return run, err
+	}
+	run.Job = job
+
+	if r, err := s.manager.Report(job.ID); err == nil {
+		run.Stats = r.Stats
+		if job.State == jobs.Finished {
+			if previous, ok := s.previous(run); ok {
+				if old, err := s.manager.Report(previous.Job.ID); err == nil {
+					d := diff.Compare(old, r)
+					run.Previous = previous.Job.ID
+					run.Diff = &d
+				}
+			}
+		}
+	}
+
+	if err := s.history.Save(run); err != nil {
+		return run, err
+	}
+	return run, s.prune(run.Name, time.Now())
+}
+
+// previous finds the last finished run of a job before a run
+func (s *Scheduler) previous(run Run) (Run, bool) {
+	runs, err := s.history.Runs(run.Name)
+	if err != nil {
+		return Run{}, false
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		r := runs[i]
+		if r.Job.ID != run.Job.ID && r.Job.State == jobs.Finished && r.Job.Created.Before(run.Job.Created) {
+			return r, true
+		}
+	}
+	return Run{}, false
+}
+
+// prune removes the runs of a job, and their results, that finished longer than the retention
+// ago. The last finished run is kept regardless, so the next run has something to compare to
+func (s *Scheduler) prune(name string, now time.Time) error {
+	if s.retention <= 0 {
+		return nil
+	}
+	runs, err := s.history.Runs(name)
+	if err != nil {
+		return err
+	}
+
+	last := ""
+	for _, run := range runs {
+		if run.Job.State == jobs.Finished {
+			last = run.Job.ID
+		}
+	}
+	for _, run := range runs {
+		if run.Job.ID == last || !run.Job.IsDone() || run.Job.Finished == nil || now.Sub(*run.Job.Finished) <= s.retention {
+			continue
+		}
+		if err := s.manager.Delete(run.Job.ID); err != nil && !errors.Is(err, jobs.ErrUnknownJob) {
+			return err
+		}
+		if err := s.history.Delete(run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package schedule_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thiagolcmelo/webcrawler/src/config"
+	"github.com/thiagolcmelo/webcrawler/src/jobs"
+	"github.com/thiagolcmelo/webcrawler/src/schedule"
+)
+
+// changingSite serves a root page linking to a page that is only there on the first visit
+func changingSite() *httptest.Server {
+	var visits atomic.Int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.URL.Path {
+		case "/":
+			if visits.Add(1) == 1 {
+				w.Write([]byte(`<title>v1</title><a href="/page">page</a>`))
+			} else {
+				w.Write([]byte(`<title>v2</title>`))
+			}
+		case "/page":
+			w.Write([]byte(`<a href="/">home</a>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func newScheduler(t *testing.T, cfg schedule.Config) (*schedule.Scheduler, *schedule.History, *jobs.Manager) {
+	dir := t.TempDir()
+	store, err := jobs.OpenStore(dir + "/jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager, err := jobs.NewManager(context.Background(), store, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(manager.Close)
+	history, err := schedule.OpenHistory(dir + "/history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := schedule.NewScheduler(manager, history, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, history, manager
+}
+
+func TestScheduler_Trigger(t *testing.T) {
+	site := changingSite()
+	defer site.Close()
+
+	cfg := schedule.Config{Jobs: []schedule.Entry{
+		{Name: "site", Schedule: "@daily", Request: jobs.Request{Seeds: []string{site.URL}, Options: map[string]interface{}{"stats": true}}},
+	}}
+	s, history, _ := newScheduler(t, cfg)
+
+	first, err := s.Trigger(context.Background(), "site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Job.State != jobs.Finished || first.Stats == nil || first.Diff != nil {
+		t.Errorf("expected the first run to finish with stats and nothing to compare to, got %+v", first)
+	}
+
+	second, err := s.Trigger(context.Background(), "site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Previous != first.Job.ID || second.Diff == nil {
+		t.Fatalf("expected the second run to be compared to the first, got %+v", second)
+	}
+	counts := second.Diff.Counts
+	if counts.Removed != 1 || counts.ContentChanges != 1 || counts.TitleChanges != 1 || counts.EdgesRemoved != 2 {
+		t.Errorf("expected the page, the content, the title and the links to and from the page to change, got %+v", counts)
+	}
+
+	runs, err := history.Runs("site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].Job.ID != first.Job.ID || runs[1].Job.ID != second.Job.ID {
+		t.Errorf("expected both runs in the history, oldest first, got %d", len(runs))
+	}
+
+	if _, err := s.Trigger(context.Background(), "other"); !errors.Is(err, schedule.ErrUnknownName) {
+		t.Errorf("expected %v, got %v", schedule.ErrUnknownName, err)
+	}
+}
+
+func TestScheduler_Retention(t *testing.T) {
+	site := changingSite()
+	defer site.Close()
+
+	cfg := schedule.Config{Retention: "1ns", Jobs: []schedule.Entry{
+		{Name: "site", Schedule: "@daily", Request: jobs.Request{Seeds: []string{site.URL}}},
+	}}
+	s, history, manager := newScheduler(t, cfg)
+
+	first, err := s.Trigger(context.Background(), "site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.Trigger(context.Background(), "site")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the last finished run is kept for the next one to be compared to
+	runs, err := history.Runs("site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Job.ID != second.Job.ID {
+		t.Errorf("expected only the last run to be kept, got %d", len(runs))
+	}
+	if _, err := manager.Get(first.Job.ID); !errors.Is(err, jobs.ErrUnknownJob) {
+		t.Errorf("expected the job of the pruned run to be deleted, got %v", err)
+	}
+}
+
+func TestScheduler_Run(t *testing.T) {
+	site := changingSite()
+	defer site.Close()
+
+	cfg := schedule.Config{Jobs: []schedule.Entry{
+		{Name: "site", Schedule: "* * * * *", Request: jobs.Request{Seeds: []string{site.URL}}},
+	}}
+	s, _, _ := newScheduler(t, cfg)
+
+	next := s.Next(time.Now())["site"]
+	if until := time.Until(next); until <= 0 || until > time.Minute {
+		t.Errorf("expected the job to run within a minute, got %s", next)
+	}
+
+	// nothing runs before the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	s.Run(ctx, func(schedule.Run, error) { called = true })
+	if called {
+		t.Errorf("expected no run")
+	}
+}
+
+func TestNewScheduler_Invalid(t *testing.T) {
+	type testCase struct {
+		testName    string
+		config      schedule.Config
+		expectedErr error
+	}
+
+	request := jobs.Request{Seeds: []string{"https://domain.com"}}
+	testCases := []testCase{
+		{
+			testName:    "invalid_name",
+			config:      schedule.Config{Jobs: []schedule.Entry{{Name: "../site", Schedule: "@daily", Request: request}}},
+			expectedErr: schedule.ErrInvalidName,
+		},
+		{
+			testName:    "repeated_name",
+			config:      schedule.Config{Jobs: []schedule.Entry{{Name: "site", Schedule: "@daily", Request: request}, {Name: "site", Schedule: "@hourly", Request: request}}},
+			expectedErr: schedule.ErrInvalidName,
+		},
+		{
+			testName:    "invalid_schedule",
+			config:      schedule.Config{Jobs: []schedule.Entry{{Name: "site", Schedule: "nightly", Request: request}}},
+			expectedErr: schedule.ErrInvalidSchedule,
+		},
+		{
+			testName:    "invalid_request",
+			config:      schedule.Config{Jobs: []schedule.Entry{{Name: "site", Schedule: "@daily", Request: jobs.Request{Seeds: request.Seeds, Profile: "rude"}}}},
+			expectedErr: config.ErrUnknownProfile,
+		},
+		{
+			testName:    "invalid_retention",
+			config:      schedule.Config{Retention: "a month"},
+			expectedErr: schedule.ErrInvalidRetention,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if _, err := schedule.NewScheduler(nil, nil, tc.config); !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := schedule.LoadConfig(strings.NewReader(`{
+		"retention": "30d",
+		"jobs": [{"name": "docs", "schedule": "0 2 * * *", "seeds": ["https://domain.com"], "profile": "polite", "options": {"timeout": "5m"}}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Jobs) != 1 || cfg.Jobs[0].Name != "docs" || cfg.Jobs[0].Seeds[0] != "https://domain.com" || cfg.Jobs[0].Options["timeout"] != "5m" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if retention, err := schedule.ParseRetention(cfg.Retention); err != nil || retention != 30*24*time.Hour {
+		t.Errorf("expected a retention of 30 days, got %s", retention)
+	}
+
+	if _, err := schedule.LoadConfig(strings.NewReader(`{"jobs": [{"name": "docs", "cron": "@daily"}]}`)); err == nil {
+		t.Errorf("expected an error for an unknown field")
+	}
+}
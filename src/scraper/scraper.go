@@ -0,0 +1,64 @@
+package scraper
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/thiagolcmelo/webcrawler/src/content"
+)
+
+// Scraper defines an interface for pulling named fields out of downloaded content
+type Scraper interface {
+	Scrape(*content.Content) error
+}
+
+// Field is a named value pulled from the elements matched by a CSS selector or an
+// XPath expression, their text unless an attribute is given
+type Field struct {
+	Name       string   `json:"name"`
+	CSS        string   `json:"css,omitempty"`
+	XPath      string   `json:"xpath,omitempty"`
+	Attr       string   `json:"attr,omitempty"`
+	All        bool     `json:"all,omitempty"`
+	Regex      string   `json:"regex,omitempty"`
+	Transforms []string `json:"transforms,omitempty"`
+}
+
+// Rule lists the fields scraped from the urls matching a regular expression
+type Rule struct {
+	Pattern string  `json:"pattern"`
+	Fields  []Field `json:"fields"`
+}
+
+// Rules bundles the scraping rules, every rule matching a url is applied
+type Rules struct {
+	Rules []Rule `json:"rules"`
+}
+
+// LoadRules reads JSON scraping rules, unknown fields are rejected so typos do not go unnoticed
+func LoadRules(r io.Reader) (Rules, error) {
+	var rules Rules
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return Rules{}, err
+	}
+	return rules, nil
+}
+
+// FieldNames lists the names of all fields in the rules, sorted
+func (r Rules) FieldNames() []string {
+	seen := map[string]struct{}{}
+	names := []string{}
+	for _, rule := range r.Rules {
+		for _, field := range rule.Fields {
+			if _, ok := seen[field.Name]; !ok {
+				seen[field.Name] = struct{}{}
+				names = append(names, field.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,33 @@
+package scraper_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/scraper"
+)
+
+func TestLoadRules(t *testing.T) {
+	rules, err := scraper.LoadRules(strings.NewReader(`{
+		"rules": [
+			{"pattern": "/product/", "fields": [
+				{"name": "price", "css": ".price", "transforms": ["number"]},
+				{"name": "name", "xpath": "//h1"}
+			]},
+			{"pattern": "/blog/", "fields": [{"name": "name", "css": "h1"}]}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"name", "price"}, rules.FieldNames()); diff != "" {
+		t.Errorf("unexpected field names (-expected +actual):\n%s", diff)
+	}
+
+	_, err = scraper.LoadRules(strings.NewReader(`{"rules": [{"pattern": ".", "fields": [{"name": "x", "selector": "h1"}]}]}`))
+	if err == nil {
+		t.Errorf("expected unknown fields to be rejected")
+	}
+}
@@ -0,0 +1,284 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thiagolcmelo/webcrawler/src/content"
+)
+
+// MaxURLs is how many URLs the protocol allows in a single sitemap file
+const MaxURLs = 50000
+
+const namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+var (
+	// ErrMissingPartWriter should be used when a sitemap index is needed but its parts cannot be written
+	ErrMissingPartWriter = errors.New("sitemap exceeds the url limit and there is nowhere to write its parts")
+	// ErrInvalidSitemap should be used when a sitemap cannot be read
+	ErrInvalidSitemap = errors.New("invalid sitemap")
+)
+
+type urlEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	URLs    []urlEntry `xml:"url"`
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name       `xml:"sitemapindex"`
+	Xmlns    string         `xml:"xmlns,attr"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+// PartWriter creates the writer for a sitemap file referenced by the sitemap index
+type PartWriter func(name string) (io.WriteCloser, error)
+
+// Writer writes the sitemap for a list of contents
+type Writer struct {
+	baseURL    string
+	partName   string
+	partWriter PartWriter
+}
+
+// NewWriter is a factory for Writer, once there are more than MaxURLs urls the sitemap is split in
+// files written through partWriter, named after partName (e.g. sitemap-1.xml for sitemap.xml),
+// and listed in a sitemap index written to the main writer with their locations under baseURL
+func NewWriter(baseURL string, partName string, partWriter PartWriter) *Writer {
+	return &Writer{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		partName:   partName,
+		partWriter: partWriter,
+	}
+}
+
+// IsIncluded informs if a content belongs in a sitemap, only canonical HTML pages
+// with successful responses do, a redirected one only when it stays on the same host
+func IsIncluded(c content.Content) bool {
+	if c.StatusCode != 0 && (c.StatusCode < 200 || c.StatusCode >= 300) {
+		return false
+	}
+	if !strings.HasPrefix(strings.ToLower(c.ContentType), "text/html") {
+		return false
+	}
+	if c.Location != "" {
+		location, err := url.Parse(c.Location)
+		if err != nil || c.URL == nil || location.Host != c.URL.Host {
+			return false
+		}
+	}
+	return c.Canonical == "" || c.Canonical == Loc(c)
+}
+
+// Loc returns the url a content is listed under, the one of the final response when redirected
+func Loc(c content.Content) string {
+	if c.Location != "" {
+		return c.Location
+	}
+	return c.Address
+}
+
+// LastModified returns the Last-Modified header of a content formatted as a W3C datetime
+func LastModified(c content.Content) string {
+	if c.Header == nil {
+		return ""
+	}
+	t, err := http.ParseTime(c.Header.Get("Last-Modified"))
+	if err != nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Write writes a sitemap, or a sitemap index and its parts, for the contents included
+func (sw *Writer) Write(w io.Writer, contents []content.Content) error {
+	entries := []urlEntry{}
+	seen := map[string]struct{}{}
+	for _, c := range contents {
+		if !IsIncluded(c) {
+			continue
+		}
+		// several urls redirecting to the same one are listed once
+		loc := Loc(c)
+		if _, ok := seen[loc]; ok {
+			continue
+		}
+		seen[loc] = struct{}{}
+		entries = append(entries, urlEntry{Loc: loc, LastMod: LastModified(c)})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Loc < entries[j].Loc })
+
+	if len(entries) <= MaxURLs {
+		return encode(w, urlSet{Xmlns: namespace, URLs: entries})
+	}
+
+	if sw.partWriter == nil {
+		return ErrMissingPartWriter
+	}
+
+	index := sitemapIndex{Xmlns: namespace}
+	for i := 0; i*MaxURLs < len(entries); i++ {
+		end := (i + 1) * MaxURLs
+		if end > len(entries) {
+			end = len(entries)
+		}
+		part := entries[i*MaxURLs : end]
+
+		name := partFileName(sw.partName, i+1)
+		if err := sw.writePart(name, part); err != nil {
+			return err
+		}
+		index.Sitemaps = append(index.Sitemaps, sitemapEntry{
+			Loc:     fmt.Sprintf("%s/%s", sw.baseURL, name),
+			LastMod: latest(part),
+		})
+	}
+
+	return encode(w, index)
+}
+
+func (sw *Writer) writePart(name string, entries []urlEntry) error {
+	pw, err := sw.partWriter(name)
+	if err != nil {
+		return err
+	}
+	if err := encode(pw, urlSet{Xmlns: namespace, URLs: entries}); err != nil {
+		pw.Close()
+		return err
+	}
+	return pw.Close()
+}
+
+// partFileName derives the name of a part from the sitemap name, the index goes before the
+// extension whatever it is, sitemap.xml becomes sitemap-1.xml and out.txt becomes out-1.txt
+func partFileName(name string, i int) string {
+	if name == "" {
+		name = "sitemap.xml"
+	}
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	return fmt.Sprintf("%s-%d%s", base, i, ext)
+}
+
+// latest returns the most recent lastmod, W3C datetimes in UTC sort lexically
+func latest(entries []urlEntry) string {
+	lastMod := ""
+	for _, e := range entries {
+		if e.LastMod > lastMod {
+			lastMod = e.LastMod
+		}
+	}
+	return lastMod
+}
+
+func encode(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Read reads the urls listed in a sitemap, or the sitemaps listed in a sitemap index
+func Read(r io.Reader) (urls []string, sitemaps []string, err error) {
+	var document struct {
+		XMLName  xml.Name
+		URLs     []urlEntry     `xml:"url"`
+		Sitemaps []sitemapEntry `xml:"sitemap"`
+	}
+	if err := xml.NewDecoder(r).Decode(&document); err != nil {
+		return nil, nil, err
+	}
+
+	switch document.XMLName.Local {
+	case "urlset":
+		for _, u := range document.URLs {
+			urls = append(urls, strings.TrimSpace(u.Loc))
+		}
+	case "sitemapindex":
+		for _, s := range document.Sitemaps {
+			sitemaps = append(sitemaps, strings.TrimSpace(s.Loc))
+		}
+	default:
+		return nil, nil, fmt.Errorf("%w: unexpected root element [%s]", ErrInvalidSitemap, document.XMLName.Local)
+	}
+	return urls, sitemaps, nil
+}
+
+// Load reads the urls of a sitemap from a file or an http(s) url, the sitemaps of an index are
+// loaded too, and the urls are normalized like the ones crawled so they compare to the pages
+func Load(location string) ([]string, error) {
+	urls, sitemaps, err := load(location)
+	if err != nil {
+		return nil, err
+	}
+	// indexes cannot list other indexes, so there is a single level to follow
+	for _, s := range sitemaps {
+		partURLs, _, err := load(s)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, partURLs...)
+	}
+	return normalize(urls), nil
+}
+
+// normalize gives the urls the address of their content, like https://x.com/ for https://x.com,
+// urls that cannot be parsed are kept as they are
+func normalize(urls []string) []string {
+	normalized := make([]string, len(urls))
+	for i, u := range urls {
+		normalized[i] = u
+		if c, err := content.NewContent(u); err == nil {
+			normalized[i] = c.Address
+		}
+	}
+	return normalized
+}
+
+func load(location string) ([]string, []string, error) {
+	var r io.ReadCloser
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		resp, err := http.Get(location)
+		if err != nil {
+			return nil, nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, nil, fmt.Errorf("%w: [%s] responded %d", ErrInvalidSitemap, location, resp.StatusCode)
+		}
+		r = resp.Body
+	} else {
+		f, err := os.Open(location)
+		if err != nil {
+			return nil, nil, err
+		}
+		r = f
+	}
+	defer r.Close()
+	return Read(r)
+}
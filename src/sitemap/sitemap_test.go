@@ -0,0 +1,286 @@
+package sitemap_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/basic"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/sitemap"
+)
+
+type urlSet struct {
+	URLs []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+}
+
+type sitemapIndex struct {
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+type bufferCloser struct {
+	bytes.Buffer
+}
+
+func (bc *bufferCloser) Close() error { return nil }
+
+func newPage(t *testing.T, address string, contentType string, statusCode int) content.Content {
+	c, err := content.NewContent(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.ContentType = contentType
+	c.StatusCode = statusCode
+	return c
+}
+
+func TestSitemapWriter_Write(t *testing.T) {
+	modified := newPage(t, "http://domain.com/modified", "text/html; charset=utf-8", http.StatusOK)
+	modified.Header.Set("Last-Modified", "Sun, 14 May 2023 12:00:00 GMT")
+
+	nonCanonical := newPage(t, "http://domain.com/page?sort=asc", "text/html", http.StatusOK)
+	nonCanonical.Canonical = "http://domain.com/page"
+
+	contents := []content.Content{
+		newPage(t, "http://domain.com/page", "text/html", http.StatusOK),
+		modified,
+		nonCanonical,
+		newPage(t, "http://domain.com/image.png", "image/png", http.StatusOK),
+		newPage(t, "http://domain.com/missing", "text/html", http.StatusNotFound),
+	}
+
+	var buf bytes.Buffer
+	err := sitemap.NewWriter("http://domain.com", "sitemap.xml", nil).Write(&buf, contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actual urlSet
+	if err := xml.Unmarshal(buf.Bytes(), &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	actualLocs := []string{}
+	for _, u := range actual.URLs {
+		actualLocs = append(actualLocs, u.Loc)
+	}
+	expectedLocs := []string{"http://domain.com/modified", "http://domain.com/page"}
+	if diff := cmp.Diff(expectedLocs, actualLocs); diff != "" {
+		t.Errorf("unexpected urls (-expected +actual):\n%s", diff)
+	}
+
+	if actual.URLs[0].LastMod != "2023-05-14T12:00:00Z" {
+		t.Errorf("unexpected lastmod %s", actual.URLs[0].LastMod)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"`)) {
+		t.Errorf("missing sitemap namespace")
+	}
+}
+
+func TestSitemapWriter_WriteIndex(t *testing.T) {
+	contents := make([]content.Content, sitemap.MaxURLs+1)
+	for i := range contents {
+		contents[i] = newPage(t, fmt.Sprintf("http://domain.com/page%06d", i), "text/html", http.StatusOK)
+	}
+
+	err := sitemap.NewWriter("http://domain.com", "sitemap.xml", nil).Write(io.Discard, contents)
+	if !errors.Is(err, sitemap.ErrMissingPartWriter) {
+		t.Fatalf("expected %v, got %v", sitemap.ErrMissingPartWriter, err)
+	}
+
+	type testCase struct {
+		testName      string
+		partName      string
+		expectedParts []string
+	}
+
+	testCases := []testCase{
+		{testName: "xml_name", partName: "sitemap.xml", expectedParts: []string{"sitemap-1.xml", "sitemap-2.xml"}},
+		{testName: "other_extension", partName: "out.txt", expectedParts: []string{"out-1.txt", "out-2.txt"}},
+		{testName: "no_extension", partName: "sitemap", expectedParts: []string{"sitemap-1", "sitemap-2"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			parts := map[string]*bufferCloser{}
+			partWriter := func(name string) (io.WriteCloser, error) {
+				parts[name] = &bufferCloser{}
+				return parts[name], nil
+			}
+
+			var buf bytes.Buffer
+			err := sitemap.NewWriter("http://domain.com/", tc.partName, partWriter).Write(&buf, contents)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var index sitemapIndex
+			if err := xml.Unmarshal(buf.Bytes(), &index); err != nil {
+				t.Fatal(err)
+			}
+
+			actualLocs := []string{}
+			for _, s := range index.Sitemaps {
+				actualLocs = append(actualLocs, s.Loc)
+			}
+			expectedLocs := []string{}
+			for _, name := range tc.expectedParts {
+				expectedLocs = append(expectedLocs, "http://domain.com/"+name)
+			}
+			if diff := cmp.Diff(expectedLocs, actualLocs); diff != "" {
+				t.Errorf("unexpected sitemaps (-expected +actual):\n%s", diff)
+			}
+
+			expectedSizes := map[string]int{tc.expectedParts[0]: sitemap.MaxURLs, tc.expectedParts[1]: 1}
+			for name, expectedSize := range expectedSizes {
+				part, ok := parts[name]
+				if !ok {
+					t.Fatalf("missing part %s", name)
+				}
+				var actual urlSet
+				if err := xml.Unmarshal(part.Bytes(), &actual); err != nil {
+					t.Fatal(err)
+				}
+				if len(actual.URLs) != expectedSize {
+					t.Errorf("expected %d urls in %s, got %d", expectedSize, name, len(actual.URLs))
+				}
+			}
+		})
+	}
+}
+
+func TestSitemapWriter_WriteRedirected(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<p>elsewhere</p>`))
+	}))
+	defer other.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old", "/older":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/away":
+			http.Redirect(w, r, other.URL+"/page", http.StatusMovedPermanently)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<p>new</p>`))
+		}
+	}))
+	defer server.Close()
+
+	downloader := basic.NewDownloader(1, 0, 1)
+	contents := []content.Content{}
+	for _, path := range []string{"/old", "/older", "/new", "/away"} {
+		c, err := content.NewContent(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := downloader.Download(context.Background(), &c); err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, c)
+	}
+
+	var buf bytes.Buffer
+	if err := sitemap.NewWriter(server.URL, "sitemap.xml", nil).Write(&buf, contents); err != nil {
+		t.Fatal(err)
+	}
+
+	var actual urlSet
+	if err := xml.Unmarshal(buf.Bytes(), &actual); err != nil {
+		t.Fatal(err)
+	}
+	actualLocs := []string{}
+	for _, u := range actual.URLs {
+		actualLocs = append(actualLocs, u.Loc)
+	}
+	// the redirects are listed under the url they end at, once, and the one to another host is left out
+	expectedLocs := []string{server.URL + "/new"}
+	if diff := cmp.Diff(expectedLocs, actualLocs); diff != "" {
+		t.Errorf("unexpected urls (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestRead(t *testing.T) {
+	type testCase struct {
+		testName         string
+		input            string
+		expectedURLs     []string
+		expectedSitemaps []string
+		expectedErr      error
+	}
+
+	testCases := []testCase{
+		{
+			testName:     "urlset",
+			input:        `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc> http://domain.com/page </loc></url></urlset>`,
+			expectedURLs: []string{"http://domain.com/page"},
+		},
+		{
+			testName:         "sitemap_index",
+			input:            `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><sitemap><loc>http://domain.com/sitemap-1.xml</loc></sitemap></sitemapindex>`,
+			expectedSitemaps: []string{"http://domain.com/sitemap-1.xml"},
+		},
+		{
+			testName:    "other_documents_are_invalid",
+			input:       `<html></html>`,
+			expectedErr: sitemap.ErrInvalidSitemap,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			urls, sitemaps, err := sitemap.Read(bytes.NewBufferString(tc.input))
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected %v, got %v", tc.expectedErr, err)
+			}
+			if diff := cmp.Diff(tc.expectedURLs, urls); diff != "" {
+				t.Errorf("unexpected urls (-expected +actual):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedSitemaps, sitemaps); diff != "" {
+				t.Errorf("unexpected sitemaps (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			fmt.Fprintf(w, `<sitemapindex><sitemap><loc>%s/sitemap-1.xml</loc></sitemap></sitemapindex>`, server.URL)
+		case "/sitemap-1.xml":
+			fmt.Fprint(w, `<urlset><url><loc>http://domain.com/page</loc></url><url><loc>http://domain.com</loc></url></urlset>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	urls, err := sitemap.Load(server.URL + "/sitemap.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the root without path is the address of the root page
+	if diff := cmp.Diff([]string{"http://domain.com/page", "http://domain.com/"}, urls); diff != "" {
+		t.Errorf("unexpected urls (-expected +actual):\n%s", diff)
+	}
+
+	if _, err := sitemap.Load(server.URL + "/missing.xml"); !errors.Is(err, sitemap.ErrInvalidSitemap) {
+		t.Errorf("expected %v, got %v", sitemap.ErrInvalidSitemap, err)
+	}
+}
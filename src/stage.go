@@ -0,0 +1,87 @@
+package src
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Stage is an action of the pipeline a url goes through
+type Stage string
+
+const (
+	// StageDiscovery checks the url was not discovered before
+	StageDiscovery Stage = "discovery"
+	// StageDownload downloads the url
+	StageDownload Stage = "download"
+	// StageDeduplication checks the content was not seen under another url
+	StageDeduplication Stage = "deduplication"
+	// StageParse finds the children of the content
+	StageParse Stage = "parse"
+	// StageScrape runs the scraper on the content
+	StageScrape Stage = "scrape"
+	// StageExtract runs the extractors on the content
+	StageExtract Stage = "extract"
+	// StageStore stores the content
+	StageStore Stage = "store"
+	// StageDispatch publishes the children not discovered yet to the frontier
+	StageDispatch Stage = "dispatch"
+)
+
+// Kind tells what an error means for the url it stopped and for the crawl
+type Kind int
+
+const (
+	// Skip stops a url that does not need processing, like a repeated one
+	Skip Kind = iota
+	// Failure stops a url that could not be processed, the crawl goes on
+	Failure
+	// Fatal stops a url and interrupts the crawl, which cannot go on without the stage
+	Fatal
+)
+
+// String names the kind as it is reported
+func (k Kind) String() string {
+	switch k {
+	case Skip:
+		return "skip"
+	case Failure:
+		return "failure"
+	case Fatal:
+		return "fatal"
+	default:
+		return fmt.Sprintf("kind(%d)", int(k))
+	}
+}
+
+// StageError is the error that stopped a url at a stage of the pipeline
+type StageError struct {
+	Stage Stage
+	Kind  Kind
+	URL   string
+	Err   error
+}
+
+// newStageError wraps the error of a stage for a url
+func newStageError(stage Stage, kind Kind, url string, err error) *StageError {
+	return &StageError{Stage: stage, Kind: kind, URL: url, Err: err}
+}
+
+// Error describes the error with its stage and url
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s at %s of url [%s]: %v", e.Kind, e.Stage, e.URL, e.Err)
+}
+
+// Unwrap returns the error of the stage
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// KindOf returns the kind of the stage error wrapped by err, errors from outside the
+// pipeline are failures
+func KindOf(err error) Kind {
+	var stageErr *StageError
+	if errors.As(err, &stageErr) {
+		return stageErr.Kind
+	}
+	return Failure
+}
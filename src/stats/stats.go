@@ -0,0 +1,225 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/thiagolcmelo/webcrawler/src/events"
+)
+
+// topErrorsLimit is how many error reasons are kept in the summary
+const topErrorsLimit = 5
+
+// stages lists the pipeline stages summarized, in pipeline order
+var stages = []events.EventType{
+	events.Discovery,
+	events.Download,
+	events.Parse,
+	events.Store,
+	events.Dispatch,
+}
+
+// StageStats counts the outcomes of a pipeline stage
+type StageStats struct {
+	Success int `json:"success"`
+	Failure int `json:"failure"`
+}
+
+// LatencyStats describes the distribution of a duration in milliseconds
+type LatencyStats struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"minMs"`
+	Mean  float64 `json:"meanMs"`
+	P50   float64 `json:"p50Ms"`
+	P90   float64 `json:"p90Ms"`
+	P99   float64 `json:"p99Ms"`
+	Max   float64 `json:"maxMs"`
+}
+
+// ErrorReason counts how many urls stopped being processed for a reason
+type ErrorReason struct {
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+}
+
+// FanOutStats describes how many new urls each page dispatched
+type FanOutStats struct {
+	Total int     `json:"total"`
+	Mean  float64 `json:"mean"`
+	Max   int     `json:"max"`
+}
+
+// Stats bundles a summary of all events logged during a crawl
+type Stats struct {
+	Stages          map[string]StageStats `json:"stages"`
+	Duration        float64               `json:"durationSeconds"`
+	PagesPerSecond  float64               `json:"pagesPerSecond"`
+	DownloadLatency LatencyStats          `json:"downloadLatency"`
+	TopErrors       []ErrorReason         `json:"topErrors"`
+	Duplicates      int                   `json:"duplicates"`
+	DispatchFanOut  FanOutStats           `json:"dispatchFanOut"`
+}
+
+// Compute aggregates the events reported by an events.Events implementation
+func Compute(report map[string][]events.EventInstance) Stats {
+	s := Stats{
+		Stages:    map[string]StageStats{},
+		TopErrors: []ErrorReason{},
+	}
+	for _, stage := range stages {
+		s.Stages[stage.String()] = StageStats{}
+	}
+
+	var first, last time.Time
+	latencies := []time.Duration{}
+	reasons := map[string]int{}
+	dispatches := 0
+
+	for _, addressEvents := range report {
+		var discoveredAt time.Time
+		for _, evt := range addressEvents {
+			if first.IsZero() || evt.Time.Before(first) {
+				first = evt.Time
+			}
+			if evt.Time.After(last) {
+				last = evt.Time
+			}
+
+			switch evt.EventType {
+			case events.Duplicate:
+				s.Duplicates++
+				continue
+			case events.Error:
+				reasons[evt.Reason]++
+				continue
+			case events.Response, events.SchemeRetry:
+				continue
+			case events.Discovery:
+				if evt.Success {
+					discoveredAt = evt.Time
+				}
+			case events.Download:
+				if !discoveredAt.IsZero() {
+					latencies = append(latencies, evt.Time.Sub(discoveredAt))
+				}
+			case events.Dispatch:
+				if evt.Success {
+					dispatches++
+					s.DispatchFanOut.Total += evt.Value
+					if evt.Value > s.DispatchFanOut.Max {
+						s.DispatchFanOut.Max = evt.Value
+					}
+				}
+			}
+
+			stageStats := s.Stages[evt.EventType.String()]
+			if evt.Success {
+				stageStats.Success++
+			} else {
+				stageStats.Failure++
+			}
+			s.Stages[evt.EventType.String()] = stageStats
+		}
+	}
+
+	s.Duration = last.Sub(first).Seconds()
+	if s.Duration > 0 {
+		s.PagesPerSecond = float64(s.Stages[events.Store.String()].Success) / s.Duration
+	}
+	if dispatches > 0 {
+		s.DispatchFanOut.Mean = float64(s.DispatchFanOut.Total) / float64(dispatches)
+	}
+	s.DownloadLatency = computeLatency(latencies)
+	s.TopErrors = topErrors(reasons, topErrorsLimit)
+
+	return s
+}
+
+func computeLatency(latencies []time.Duration) LatencyStats {
+	if len(latencies) == 0 {
+		return LatencyStats{}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+
+	return LatencyStats{
+		Count: len(latencies),
+		Min:   milliseconds(latencies[0]),
+		Mean:  milliseconds(total / time.Duration(len(latencies))),
+		P50:   milliseconds(percentile(latencies, 50)),
+		P90:   milliseconds(percentile(latencies, 90)),
+		P99:   milliseconds(percentile(latencies, 99)),
+		Max:   milliseconds(latencies[len(latencies)-1]),
+	}
+}
+
+// percentile uses the nearest rank method over sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func topErrors(reasons map[string]int, limit int) []ErrorReason {
+	top := make([]ErrorReason, 0, len(reasons))
+	for reason, count := range reasons {
+		top = append(top, ErrorReason{Reason: reason, Count: count})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Reason < top[j].Reason
+	})
+	if len(top) > limit {
+		top = top[:limit]
+	}
+	return top
+}
+
+// WriteText writes a human readable summary to the provided writer
+func (s Stats) WriteText(w io.Writer) error {
+	lines := []string{
+		"stats:",
+		fmt.Sprintf("  duration: %.2fs", s.Duration),
+		fmt.Sprintf("  pages per second: %.2f", s.PagesPerSecond),
+	}
+	for _, stage := range stages {
+		stageStats := s.Stages[stage.String()]
+		lines = append(lines, fmt.Sprintf("  %s: %d succeeded, %d failed", stage, stageStats.Success, stageStats.Failure))
+	}
+	lines = append(lines,
+		fmt.Sprintf("  duplicates skipped: %d", s.Duplicates),
+		fmt.Sprintf("  download latency (ms): min %.1f, mean %.1f, p50 %.1f, p90 %.1f, p99 %.1f, max %.1f",
+			s.DownloadLatency.Min, s.DownloadLatency.Mean, s.DownloadLatency.P50,
+			s.DownloadLatency.P90, s.DownloadLatency.P99, s.DownloadLatency.Max),
+		fmt.Sprintf("  dispatch fan-out: %d total, %.2f mean, %d max",
+			s.DispatchFanOut.Total, s.DispatchFanOut.Mean, s.DispatchFanOut.Max),
+	)
+	if len(s.TopErrors) > 0 {
+		lines = append(lines, "  top errors:")
+		for _, e := range s.TopErrors {
+			lines = append(lines, fmt.Sprintf("    %d x %s", e.Count, e.Reason))
+		}
+	}
+
+	for _, line := range lines {
+		if _, err := w.Write([]byte(line + "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package stats_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/events"
+	"github.com/thiagolcmelo/webcrawler/src/stats"
+)
+
+func sampleReport() map[string][]events.EventInstance {
+	start := time.Date(2023, 5, 14, 12, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	return map[string][]events.EventInstance{
+		"http://url1.com/": {
+			{EventType: events.Discovery, Success: true, Time: at(0)},
+			{EventType: events.Download, Success: true, Time: at(100)},
+			{EventType: events.Parse, Success: true, Value: 3, Time: at(110)},
+			{EventType: events.Store, Success: true, Time: at(120)},
+			{EventType: events.Dispatch, Success: true, Value: 2, Time: at(130)},
+		},
+		"http://url1.com/a": {
+			{EventType: events.Discovery, Success: true, Time: at(200)},
+			{EventType: events.Download, Success: true, Time: at(500)},
+			{EventType: events.Parse, Success: true, Value: 1, Time: at(510)},
+			{EventType: events.Store, Success: true, Time: at(520)},
+			{EventType: events.Dispatch, Success: true, Value: 0, Time: at(530)},
+			{EventType: events.Discovery, Success: false, Time: at(900)},
+		},
+		"http://url1.com/b": {
+			{EventType: events.Discovery, Success: true, Time: at(200)},
+			{EventType: events.Download, Success: true, Time: at(400)},
+			{EventType: events.Duplicate, Time: at(400)},
+		},
+		"http://url1.com/c": {
+			{EventType: events.Discovery, Success: true, Time: at(200)},
+			{EventType: events.Download, Success: false, Time: at(1200)},
+			{EventType: events.Error, Reason: "response status not 200", Time: at(1200)},
+		},
+	}
+}
+
+func TestStats_Compute(t *testing.T) {
+	actual := stats.Compute(sampleReport())
+
+	expected := stats.Stats{
+		Stages: map[string]stats.StageStats{
+			"discovery": {Success: 4, Failure: 1},
+			"download":  {Success: 3, Failure: 1},
+			"parse":     {Success: 2, Failure: 0},
+			"store":     {Success: 2, Failure: 0},
+			"dispatch":  {Success: 2, Failure: 0},
+		},
+		Duration:       1.2,
+		PagesPerSecond: 2 / 1.2,
+		DownloadLatency: stats.LatencyStats{
+			Count: 4,
+			Min:   100,
+			Mean:  400,
+			P50:   200,
+			P90:   1000,
+			P99:   1000,
+			Max:   1000,
+		},
+		TopErrors: []stats.ErrorReason{
+			{Reason: "response status not 200", Count: 1},
+		},
+		Duplicates: 1,
+		DispatchFanOut: stats.FanOutStats{
+			Total: 2,
+			Mean:  1,
+			Max:   2,
+		},
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected stats (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestStats_ComputeEmpty(t *testing.T) {
+	actual := stats.Compute(map[string][]events.EventInstance{})
+
+	if actual.PagesPerSecond != 0 || actual.DownloadLatency.Count != 0 || len(actual.TopErrors) != 0 {
+		t.Errorf("expected empty stats, got %#v", actual)
+	}
+}
+
+func TestStats_WriteText(t *testing.T) {
+	var buf bytes.Buffer
+	err := stats.Compute(sampleReport()).WriteText(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"download: 3 succeeded, 1 failed",
+		"duplicates skipped: 1",
+		"1 x response status not 200",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in %q", expected, buf.String())
+		}
+	}
+}
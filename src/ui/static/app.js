@@ -0,0 +1,329 @@
+'use strict';
+
+// el creates an element with its text and attributes
+function el(tag, text, attrs) {
+	const e = document.createElement(tag);
+	if (text !== undefined && text !== null) {
+		e.textContent = String(text);
+	}
+	for (const [key, value] of Object.entries(attrs || {})) {
+		e.setAttribute(key, value);
+	}
+	return e;
+}
+
+async function getJSON(path) {
+	const resp = await fetch(path);
+	const body = await resp.json();
+	if (!resp.ok) {
+		throw new Error(body.error || resp.statusText);
+	}
+	return body;
+}
+
+function pageLink(url) {
+	return el('a', url, {href: '#/page?url=' + encodeURIComponent(url)});
+}
+
+function statusClass(status) {
+	return status >= 200 && status < 300 ? 'status-ok' : 'status-error';
+}
+
+// pages
+
+let rows = [];
+let sortKey = 'url';
+let sortAsc = true;
+let searchTimer = null;
+
+async function loadRows() {
+	const q = document.getElementById('search').value;
+	rows = await getJSON('api/pages?q=' + encodeURIComponent(q));
+	renderRows();
+}
+
+function renderRows() {
+	const sorted = rows.slice().sort((a, b) => {
+		const x = a[sortKey], y = b[sortKey];
+		const cmp = x < y ? -1 : x > y ? 1 : 0;
+		return sortAsc ? cmp : -cmp;
+	});
+
+	const tbody = document.getElementById('rows');
+	tbody.replaceChildren();
+	for (const row of sorted) {
+		const tr = el('tr');
+		const url = el('td', null, {class: 'url'});
+		url.append(pageLink(row.url));
+		if (row.title) {
+			url.append(el('div', row.title, {class: 'element'}));
+		}
+		tr.append(
+			url,
+			el('td', row.statusCode, {class: statusClass(row.statusCode)}),
+			el('td', row.contentType),
+			el('td', row.depth),
+			el('td', row.inlinks),
+			el('td', row.outlinks),
+		);
+		tbody.append(tr);
+	}
+	document.getElementById('count').textContent = sorted.length + ' pages';
+}
+
+document.getElementById('search').addEventListener('input', () => {
+	clearTimeout(searchTimer);
+	searchTimer = setTimeout(loadRows, 200);
+});
+
+for (const th of document.querySelectorAll('th[data-sort]')) {
+	th.addEventListener('click', () => {
+		sortAsc = sortKey === th.dataset.sort ? !sortAsc : true;
+		sortKey = th.dataset.sort;
+		renderRows();
+	});
+}
+
+// page
+
+function linkList(id, links) {
+	const ul = document.getElementById(id);
+	ul.replaceChildren();
+	for (const link of links) {
+		const li = el('li');
+		if (link.crawled) {
+			li.append(pageLink(link.url));
+		} else {
+			li.append(el('span', link.url, {class: 'missing', title: 'not in the report'}));
+		}
+		if (link.element) {
+			li.append(' ', el('span', link.element, {class: 'element'}));
+		}
+		ul.append(li);
+	}
+	document.getElementById(id + '-count').textContent = '(' + links.length + ')';
+}
+
+async function showPage(url) {
+	const page = await getJSON('api/page?url=' + encodeURIComponent(url));
+	document.getElementById('page-url').textContent = page.url;
+
+	const details = document.getElementById('page-details');
+	details.replaceChildren();
+	const add = (name, value) => {
+		if (value !== undefined && value !== null && value !== '') {
+			details.append(el('dt', name), el('dd', value));
+		}
+	};
+	add('Status', page.statusCode);
+	add('Content type', page.contentType);
+	add('Depth', page.depth);
+	add('Seed', page.seed);
+	add('Size', page.size + ' bytes');
+	add('Download', page.elapsedMs.toFixed(1) + ' ms');
+	add('Redirects', (page.redirects || []).join(' → '));
+	add('Location', page.location);
+	if (page.metadata) {
+		add('Title', page.metadata.title);
+		add('Description', page.metadata.description);
+	}
+	add('Warnings', (page.warnings || []).join('; '));
+
+	linkList('inlinks', page.inlinks);
+	linkList('outlinks', page.outlinks);
+}
+
+// graph
+
+let graphRendered = false;
+
+async function showGraph() {
+	if (graphRendered) {
+		return;
+	}
+	graphRendered = true;
+
+	const graph = await getJSON('api/graph');
+	document.getElementById('graph-note').textContent = graph.nodes.length + ' pages and ' + graph.edges.length + ' links' +
+		(graph.truncated ? ', only the most linked pages are drawn' : '') + '. Click a page to open it.';
+
+	const canvas = document.getElementById('graph-canvas');
+	const ctx = canvas.getContext('2d');
+	const w = canvas.width, h = canvas.height;
+
+	const index = new Map();
+	const nodes = graph.nodes.map((n, i) => {
+		index.set(n.url, i);
+		const angle = 2 * Math.PI * i / graph.nodes.length;
+		return {...n, x: w / 2 + Math.cos(angle) * w / 3, y: h / 2 + Math.sin(angle) * h / 3, vx: 0, vy: 0};
+	});
+	const edges = graph.edges.map(e => [index.get(e.from), index.get(e.to)]);
+
+	// a simple force layout: nodes repel each other, links pull them together
+	const k = Math.sqrt(w * h / Math.max(nodes.length, 1)) * 0.5;
+	for (let step = 0; step < 300; step++) {
+		for (const a of nodes) {
+			for (const b of nodes) {
+				if (a === b) {
+					continue;
+				}
+				const dx = a.x - b.x, dy = a.y - b.y;
+				const d2 = Math.max(dx * dx + dy * dy, 0.01);
+				a.vx += dx / d2 * k * k * 0.01;
+				a.vy += dy / d2 * k * k * 0.01;
+			}
+		}
+		for (const [i, j] of edges) {
+			const a = nodes[i], b = nodes[j];
+			const dx = b.x - a.x, dy = b.y - a.y;
+			const d = Math.sqrt(dx * dx + dy * dy) || 1;
+			const f = (d - k) * 0.01;
+			a.vx += dx / d * f; a.vy += dy / d * f;
+			b.vx -= dx / d * f; b.vy -= dy / d * f;
+		}
+		for (const n of nodes) {
+			n.vx += (w / 2 - n.x) * 0.001;
+			n.vy += (h / 2 - n.y) * 0.001;
+			n.x = Math.min(w - 10, Math.max(10, n.x + n.vx));
+			n.y = Math.min(h - 10, Math.max(10, n.y + n.vy));
+			n.vx *= 0.5;
+			n.vy *= 0.5;
+		}
+	}
+
+	const radius = n => 3 + Math.min(Math.sqrt(n.inlinks), 8);
+	ctx.clearRect(0, 0, w, h);
+	ctx.strokeStyle = 'rgba(80, 100, 130, 0.25)';
+	for (const [i, j] of edges) {
+		ctx.beginPath();
+		ctx.moveTo(nodes[i].x, nodes[i].y);
+		ctx.lineTo(nodes[j].x, nodes[j].y);
+		ctx.stroke();
+	}
+	for (const n of nodes) {
+		ctx.beginPath();
+		ctx.fillStyle = n.depth === 0 ? '#d0602a' : '#2b6aa0';
+		ctx.arc(n.x, n.y, radius(n), 0, 2 * Math.PI);
+		ctx.fill();
+	}
+
+	const nodeAt = event => {
+		const rect = canvas.getBoundingClientRect();
+		const x = (event.clientX - rect.left) * w / rect.width;
+		const y = (event.clientY - rect.top) * h / rect.height;
+		return nodes.find(n => (n.x - x) ** 2 + (n.y - y) ** 2 <= (radius(n) + 2) ** 2);
+	};
+	canvas.addEventListener('mousemove', event => {
+		const n = nodeAt(event);
+		canvas.title = n ? n.url : '';
+		canvas.style.cursor = n ? 'pointer' : 'default';
+	});
+	canvas.addEventListener('click', event => {
+		const n = nodeAt(event);
+		if (n) {
+			location.hash = '#/page?url=' + encodeURIComponent(n.url);
+		}
+	});
+}
+
+// summary
+
+function summaryTable(title, headers, rows) {
+	const section = el('div');
+	section.append(el('h3', title + ' (' + rows.length + ')'));
+	if (rows.length === 0) {
+		section.append(el('p', 'none'));
+		return section;
+	}
+	const table = el('table');
+	const head = el('tr');
+	for (const header of headers) {
+		head.append(el('th', header));
+	}
+	table.append(head);
+	for (const cells of rows) {
+		const tr = el('tr');
+		for (const cell of cells) {
+			const td = el('td', null, {class: 'url'});
+			td.append(cell instanceof Node ? cell : document.createTextNode(String(cell)));
+			tr.append(td);
+		}
+		table.append(tr);
+	}
+	section.append(table);
+	return section;
+}
+
+function urlList(urls) {
+	const ul = el('ul');
+	for (const url of urls) {
+		const li = el('li');
+		li.append(pageLink(url));
+		ul.append(li);
+	}
+	return ul;
+}
+
+async function showSummary() {
+	const s = await getJSON('api/summary');
+	const section = document.getElementById('summary');
+	section.replaceChildren();
+
+	section.append(el('p', s.pages + ' pages' + (s.partial ? ', the crawl was interrupted' : '')));
+	section.append(summaryTable('Statuses', ['Status', 'Pages'], Object.entries(s.statuses)));
+	section.append(summaryTable('Content types', ['Content type', 'Pages'], Object.entries(s.contentTypes)));
+
+	if (s.errors) {
+		section.append(summaryTable('Errors', ['Reason', 'URLs'], s.errors.map(e => [e.reason, e.count])));
+	} else {
+		section.append(el('h3', 'Errors'), el('p', 'error reasons are only known for reports written with stats'));
+	}
+	section.append(summaryTable('Links to urls not in the report', ['URL', 'Linked from'], s.unreached.map(u => [u.url, u.linkedFrom])));
+	section.append(summaryTable('Warnings', ['URL', 'Warning'], s.warnings.map(w => [pageLink(w.url), w.warning])));
+
+	if (s.skippedDuplicates !== undefined) {
+		section.append(el('h3', 'Repeated content'), el('p', s.skippedDuplicates + ' urls skipped because their content was already seen'));
+	}
+	section.append(summaryTable('Duplicate titles', ['Title', 'Pages'], s.duplicateTitles.map(d => [d.value, urlList(d.urls)])));
+	section.append(summaryTable('Duplicate descriptions', ['Description', 'Pages'], s.duplicateDescriptions.map(d => [d.value, urlList(d.urls)])));
+}
+
+// routing
+
+async function route() {
+	const hash = location.hash || '#/pages';
+	const [path, query] = hash.slice(1).split('?');
+	const name = path.slice(1) || 'pages';
+
+	for (const section of document.querySelectorAll('main > section')) {
+		section.hidden = section.id !== name;
+	}
+	for (const a of document.querySelectorAll('nav a')) {
+		a.classList.toggle('active', a.getAttribute('href') === '#' + path);
+	}
+
+	const error = document.getElementById('error');
+	error.hidden = true;
+	try {
+		switch (name) {
+		case 'pages':
+			await loadRows();
+			break;
+		case 'page':
+			await showPage(new URLSearchParams(query).get('url'));
+			break;
+		case 'graph':
+			await showGraph();
+			break;
+		case 'summary':
+			await showSummary();
+			break;
+		}
+	} catch (err) {
+		error.textContent = err.message;
+		error.hidden = false;
+	}
+}
+
+window.addEventListener('hashchange', route);
+route();
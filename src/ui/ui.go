@@ -0,0 +1,325 @@
+// Package ui serves a web interface for browsing the pages of a crawl report, its assets are
+// embedded in the binary so it works without network access
+package ui
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/thiagolcmelo/webcrawler/src/report"
+	"github.com/thiagolcmelo/webcrawler/src/stats"
+)
+
+//go:embed static
+var static embed.FS
+
+// Row is a page as listed in the url table
+type Row struct {
+	URL         string `json:"url"`
+	StatusCode  int    `json:"statusCode"`
+	ContentType string `json:"contentType"`
+	Depth       int    `json:"depth"`
+	Title       string `json:"title"`
+	Inlinks     int    `json:"inlinks"`
+	Outlinks    int    `json:"outlinks"`
+	Warnings    int    `json:"warnings"`
+}
+
+// Link is a link of a page, crawled informs if its target is a page of the report
+type Link struct {
+	URL     string `json:"url"`
+	Element string `json:"element,omitempty"`
+	Crawled bool   `json:"crawled"`
+}
+
+// PageView is a page along with the pages linking to it and the ones it links to
+type PageView struct {
+	report.Page
+	Inlinks  []Link `json:"inlinks"`
+	Outlinks []Link `json:"outlinks"`
+}
+
+// Node is a page in the link graph
+type Node struct {
+	URL     string `json:"url"`
+	Depth   int    `json:"depth"`
+	Inlinks int    `json:"inlinks"`
+}
+
+// Edge is a link between two pages in the link graph
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Graph is the link graph between the pages of a report
+type Graph struct {
+	Nodes     []Node `json:"nodes"`
+	Edges     []Edge `json:"edges"`
+	Truncated bool   `json:"truncated"`
+}
+
+// Unreached is a url linked from the pages that is not a page of the report, because it failed,
+// was a repeated content or was not reached before the crawl finished
+type Unreached struct {
+	URL        string `json:"url"`
+	LinkedFrom int    `json:"linkedFrom"`
+}
+
+// Warning is a problem found in a page while it was processed
+type Warning struct {
+	URL     string `json:"url"`
+	Warning string `json:"warning"`
+}
+
+// Duplicate is a value shared by several pages
+type Duplicate struct {
+	Value string   `json:"value"`
+	URLs  []string `json:"urls"`
+}
+
+// Summary bundles the errors and duplicates of a crawl, the error reasons and the count of
+// duplicates skipped are only known when the report has stats
+type Summary struct {
+	Pages                 int                 `json:"pages"`
+	Partial               bool                `json:"partial"`
+	Statuses              map[int]int         `json:"statuses"`
+	ContentTypes          map[string]int      `json:"contentTypes"`
+	Errors                []stats.ErrorReason `json:"errors,omitempty"`
+	Unreached             []Unreached         `json:"unreached"`
+	Warnings              []Warning           `json:"warnings"`
+	SkippedDuplicates     *int                `json:"skippedDuplicates,omitempty"`
+	DuplicateTitles       []Duplicate         `json:"duplicateTitles"`
+	DuplicateDescriptions []Duplicate         `json:"duplicateDescriptions"`
+}
+
+// Server serves the interface and the JSON API it reads the report from
+type Server struct {
+	report  report.Report
+	pages   map[string]report.Page
+	inlinks map[string][]string
+	mux     *http.ServeMux
+}
+
+// NewServer indexes the pages of a report and creates a Server for it
+func NewServer(r report.Report) *Server {
+	s := &Server{
+		report:  r,
+		pages:   map[string]report.Page{},
+		inlinks: map[string][]string{},
+		mux:     http.NewServeMux(),
+	}
+	for _, p := range r.Pages {
+		s.pages[p.URL] = p
+	}
+	for _, p := range r.Pages {
+		for _, child := range p.Children {
+			s.inlinks[child] = append(s.inlinks[child], p.URL)
+		}
+	}
+	for _, from := range s.inlinks {
+		sort.Strings(from)
+	}
+
+	assets, _ := fs.Sub(static, "static")
+	s.mux.Handle("/", http.FileServer(http.FS(assets)))
+	s.mux.HandleFunc("/api/pages", s.handlePages)
+	s.mux.HandleFunc("/api/page", s.handlePage)
+	s.mux.HandleFunc("/api/graph", s.handleGraph)
+	s.mux.HandleFunc("/api/summary", s.handleSummary)
+	return s
+}
+
+// ServeHTTP routes a request to its handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Rows lists the pages sorted by url, the ones whose url, title, status or content type
+// contain the query when it is not empty
+func (s *Server) Rows(query string) []Row {
+	query = strings.ToLower(query)
+	rows := []Row{}
+	for _, p := range s.report.Pages {
+		row := Row{
+			URL:         p.URL,
+			StatusCode:  p.StatusCode,
+			ContentType: mediaType(p.ContentType),
+			Depth:       p.Depth,
+			Inlinks:     len(s.inlinks[p.URL]),
+			Outlinks:    len(p.Children),
+			Warnings:    len(p.Warnings),
+		}
+		if p.Metadata != nil {
+			row.Title = p.Metadata.Title
+		}
+		if query != "" && !matches(row, query) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].URL < rows[j].URL })
+	return rows
+}
+
+// matches informs if the query, already in lower case, is found in the fields shown in a row
+func matches(row Row, query string) bool {
+	for _, field := range []string{row.URL, row.Title, row.ContentType, strconv.Itoa(row.StatusCode)} {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// Page returns a page with its inlinks and outlinks, false if it is not in the report
+func (s *Server) Page(url string) (PageView, bool) {
+	p, ok := s.pages[url]
+	if !ok {
+		return PageView{}, false
+	}
+	view := PageView{Page: p, Inlinks: []Link{}, Outlinks: []Link{}}
+	for _, from := range s.inlinks[url] {
+		view.Inlinks = append(view.Inlinks, Link{URL: from, Element: s.pages[from].Elements[url], Crawled: true})
+	}
+	for _, to := range p.Children {
+		_, crawled := s.pages[to]
+		view.Outlinks = append(view.Outlinks, Link{URL: to, Element: p.Elements[to], Crawled: crawled})
+	}
+	return view, true
+}
+
+// Graph returns the links between pages, with more than limit pages only the most linked ones are kept
+func (s *Server) Graph(limit int) Graph {
+	nodes := []Node{}
+	for _, p := range s.report.Pages {
+		nodes = append(nodes, Node{URL: p.URL, Depth: p.Depth, Inlinks: len(s.inlinks[p.URL])})
+	}
+	g := Graph{Edges: []Edge{}}
+	if limit > 0 && len(nodes) > limit {
+		sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].Inlinks > nodes[j].Inlinks })
+		nodes, g.Truncated = nodes[:limit], true
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].URL < nodes[j].URL })
+	g.Nodes = nodes
+
+	kept := map[string]bool{}
+	for _, n := range nodes {
+		kept[n.URL] = true
+	}
+	for _, n := range nodes {
+		for _, child := range s.pages[n.URL].Children {
+			if kept[child] && child != n.URL {
+				g.Edges = append(g.Edges, Edge{From: n.URL, To: child})
+			}
+		}
+	}
+	return g
+}
+
+// Summary gathers the errors and duplicates of the crawl
+func (s *Server) Summary() Summary {
+	summary := Summary{
+		Pages:                 len(s.report.Pages),
+		Partial:               s.report.Partial,
+		Statuses:              map[int]int{},
+		ContentTypes:          map[string]int{},
+		Unreached:             []Unreached{},
+		Warnings:              []Warning{},
+		DuplicateTitles:       duplicates(s.report.Pages, func(p report.Page) string { return p.Metadata.Title }),
+		DuplicateDescriptions: duplicates(s.report.Pages, func(p report.Page) string { return p.Metadata.Description }),
+	}
+	if s.report.Stats != nil {
+		summary.Errors = s.report.Stats.TopErrors
+		summary.SkippedDuplicates = &s.report.Stats.Duplicates
+	}
+
+	for _, p := range s.report.Pages {
+		summary.Statuses[p.StatusCode]++
+		summary.ContentTypes[mediaType(p.ContentType)]++
+		for _, warning := range p.Warnings {
+			summary.Warnings = append(summary.Warnings, Warning{URL: p.URL, Warning: warning})
+		}
+	}
+	for url, from := range s.inlinks {
+		if _, ok := s.pages[url]; !ok {
+			summary.Unreached = append(summary.Unreached, Unreached{URL: url, LinkedFrom: len(from)})
+		}
+	}
+	sort.Slice(summary.Unreached, func(i, j int) bool { return summary.Unreached[i].URL < summary.Unreached[j].URL })
+	sort.SliceStable(summary.Warnings, func(i, j int) bool { return summary.Warnings[i].URL < summary.Warnings[j].URL })
+	return summary
+}
+
+// duplicates groups the pages with metadata sharing a non empty value, sorted by value
+func duplicates(pages []report.Page, value func(report.Page) string) []Duplicate {
+	groups := map[string][]string{}
+	for _, p := range pages {
+		if p.Metadata == nil {
+			continue
+		}
+		if v := value(p); v != "" {
+			groups[v] = append(groups[v], p.URL)
+		}
+	}
+
+	shared := []Duplicate{}
+	for v, urls := range groups {
+		if len(urls) > 1 {
+			sort.Strings(urls)
+			shared = append(shared, Duplicate{Value: v, URLs: urls})
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool { return shared[i].Value < shared[j].Value })
+	return shared
+}
+
+// mediaType strips the parameters of a content type
+func mediaType(contentType string) string {
+	return strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+}
+
+func (s *Server) handlePages(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Rows(r.URL.Query().Get("q")))
+}
+
+func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
+	url := r.URL.Query().Get("url")
+	view, ok := s.Page(url)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "page not in the report [" + url + "]"})
+		return
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+// defaultGraphLimit keeps the graph small enough to be laid out in the browser
+const defaultGraphLimit = 300
+
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
+	limit := defaultGraphLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid limit [" + value + "]"})
+			return
+		}
+		limit = n
+	}
+	writeJSON(w, http.StatusOK, s.Graph(limit))
+}
+
+func (s *Server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Summary())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
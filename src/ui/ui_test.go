@@ -0,0 +1,190 @@
+package ui_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/report"
+	"github.com/thiagolcmelo/webcrawler/src/stats"
+	"github.com/thiagolcmelo/webcrawler/src/ui"
+)
+
+func sampleReport() report.Report {
+	page := func(url string, title string, contentType string, depth int, children ...string) report.Page {
+		return report.Page{
+			URL:         url,
+			ContentType: contentType,
+			StatusCode:  200,
+			Depth:       depth,
+			Children:    children,
+			Metadata:    &content.Metadata{Title: title},
+		}
+	}
+	pages := []report.Page{
+		page("http://domain.com/", "Home", "text/html; charset=utf-8", 0, "http://domain.com/about", "http://domain.com/blog", "http://domain.com/missing"),
+		page("http://domain.com/about", "About", "text/html", 1, "http://domain.com/"),
+		page("http://domain.com/blog", "About", "text/html", 1, "http://domain.com/", "http://domain.com/missing"),
+		page("http://domain.com/logo.png", "", "image/png", -1),
+	}
+	pages[3].Metadata = nil
+	pages[1].Warnings = []string{"not parsed: broken"}
+	return report.Report{
+		Pages: pages,
+		Stats: &stats.Stats{Duplicates: 2, TopErrors: []stats.ErrorReason{{Reason: "response status not 200", Count: 1}}},
+	}
+}
+
+func TestServer_Rows(t *testing.T) {
+	type testCase struct {
+		testName string
+		query    string
+		expected []string
+	}
+
+	testCases := []testCase{
+		{testName: "no_query", query: "", expected: []string{"http://domain.com/", "http://domain.com/about", "http://domain.com/blog", "http://domain.com/logo.png"}},
+		{testName: "by_url", query: "BLOG", expected: []string{"http://domain.com/blog"}},
+		{testName: "by_title", query: "about", expected: []string{"http://domain.com/about", "http://domain.com/blog"}},
+		{testName: "by_content_type", query: "image/", expected: []string{"http://domain.com/logo.png"}},
+		{testName: "no_match", query: "nothing", expected: []string{}},
+	}
+
+	s := ui.NewServer(sampleReport())
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			actual := []string{}
+			for _, row := range s.Rows(tc.query) {
+				actual = append(actual, row.URL)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected rows (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+
+	home := s.Rows("")[0]
+	expected := ui.Row{URL: "http://domain.com/", StatusCode: 200, ContentType: "text/html", Title: "Home", Inlinks: 2, Outlinks: 3}
+	if diff := cmp.Diff(expected, home); diff != "" {
+		t.Errorf("unexpected row (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestServer_Page(t *testing.T) {
+	s := ui.NewServer(sampleReport())
+
+	view, ok := s.Page("http://domain.com/blog")
+	if !ok {
+		t.Fatal("expected the page to be found")
+	}
+	expectedInlinks := []ui.Link{{URL: "http://domain.com/", Crawled: true}}
+	if diff := cmp.Diff(expectedInlinks, view.Inlinks); diff != "" {
+		t.Errorf("unexpected inlinks (-expected +actual):\n%s", diff)
+	}
+	expectedOutlinks := []ui.Link{{URL: "http://domain.com/", Crawled: true}, {URL: "http://domain.com/missing", Crawled: false}}
+	if diff := cmp.Diff(expectedOutlinks, view.Outlinks); diff != "" {
+		t.Errorf("unexpected outlinks (-expected +actual):\n%s", diff)
+	}
+
+	if _, ok := s.Page("http://domain.com/missing"); ok {
+		t.Errorf("expected a url that is not a page not to be found")
+	}
+}
+
+func TestServer_Graph(t *testing.T) {
+	s := ui.NewServer(sampleReport())
+
+	g := s.Graph(0)
+	if len(g.Nodes) != 4 || len(g.Edges) != 4 || g.Truncated {
+		t.Errorf("expected 4 nodes and 4 edges between pages, got %d and %d", len(g.Nodes), len(g.Edges))
+	}
+
+	// the home page is the most linked one
+	g = s.Graph(1)
+	if len(g.Nodes) != 1 || g.Nodes[0].URL != "http://domain.com/" || len(g.Edges) != 0 || !g.Truncated {
+		t.Errorf("unexpected truncated graph %+v", g)
+	}
+}
+
+func TestServer_Summary(t *testing.T) {
+	summary := ui.NewServer(sampleReport()).Summary()
+
+	if diff := cmp.Diff(map[string]int{"text/html": 3, "image/png": 1}, summary.ContentTypes); diff != "" {
+		t.Errorf("unexpected content types (-expected +actual):\n%s", diff)
+	}
+	if diff := cmp.Diff([]ui.Unreached{{URL: "http://domain.com/missing", LinkedFrom: 2}}, summary.Unreached); diff != "" {
+		t.Errorf("unexpected unreached urls (-expected +actual):\n%s", diff)
+	}
+	if diff := cmp.Diff([]ui.Warning{{URL: "http://domain.com/about", Warning: "not parsed: broken"}}, summary.Warnings); diff != "" {
+		t.Errorf("unexpected warnings (-expected +actual):\n%s", diff)
+	}
+	expectedTitles := []ui.Duplicate{{Value: "About", URLs: []string{"http://domain.com/about", "http://domain.com/blog"}}}
+	if diff := cmp.Diff(expectedTitles, summary.DuplicateTitles); diff != "" {
+		t.Errorf("unexpected duplicate titles (-expected +actual):\n%s", diff)
+	}
+	if summary.SkippedDuplicates == nil || *summary.SkippedDuplicates != 2 || len(summary.Errors) != 1 {
+		t.Errorf("expected the duplicates skipped and the errors from the stats, got %+v", summary)
+	}
+}
+
+func TestServer_ServeHTTP(t *testing.T) {
+	type testCase struct {
+		testName            string
+		path                string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}
+
+	testCases := []testCase{
+		{testName: "index", path: "/", expectedStatus: http.StatusOK, expectedContentType: "text/html; charset=utf-8", expectedBody: `<script src="app.js">`},
+		{testName: "script", path: "/app.js", expectedStatus: http.StatusOK, expectedContentType: "text/javascript; charset=utf-8", expectedBody: "function route"},
+		{testName: "stylesheet", path: "/style.css", expectedStatus: http.StatusOK, expectedContentType: "text/css; charset=utf-8", expectedBody: "canvas"},
+		{testName: "pages", path: "/api/pages?q=blog", expectedStatus: http.StatusOK, expectedContentType: "application/json", expectedBody: `"url":"http://domain.com/blog"`},
+		{testName: "page", path: "/api/page?url=http%3A%2F%2Fdomain.com%2Fabout", expectedStatus: http.StatusOK, expectedContentType: "application/json", expectedBody: `"inlinks":[{"crawled":true,"url":"http://domain.com/"}]`},
+		{testName: "unknown_page", path: "/api/page?url=nowhere", expectedStatus: http.StatusNotFound, expectedContentType: "application/json", expectedBody: "page not in the report [nowhere]"},
+		{testName: "graph", path: "/api/graph?limit=2", expectedStatus: http.StatusOK, expectedContentType: "application/json", expectedBody: `"truncated":true`},
+		{testName: "invalid_graph_limit", path: "/api/graph?limit=all", expectedStatus: http.StatusBadRequest, expectedContentType: "application/json", expectedBody: "invalid limit [all]"},
+		{testName: "summary", path: "/api/summary", expectedStatus: http.StatusOK, expectedContentType: "application/json", expectedBody: `"skippedDuplicates":2`},
+	}
+
+	server := httptest.NewServer(ui.NewServer(sampleReport()))
+	defer server.Close()
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tc.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			var body strings.Builder
+			if strings.HasPrefix(tc.expectedContentType, "application/json") {
+				// compact the json so the expected body does not depend on the encoder
+				var v interface{}
+				json.NewDecoder(resp.Body).Decode(&v)
+				data, _ := json.Marshal(v)
+				body.Write(data)
+			} else {
+				data, _ := io.ReadAll(resp.Body)
+				body.Write(data)
+			}
+
+			if resp.StatusCode != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, resp.StatusCode)
+			}
+			if contentType := resp.Header.Get("Content-Type"); contentType != tc.expectedContentType {
+				t.Errorf("expected content type %s, got %s", tc.expectedContentType, contentType)
+			}
+			if !strings.Contains(body.String(), tc.expectedBody) {
+				t.Errorf("expected %q in:\n%s", tc.expectedBody, body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package warc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/thiagolcmelo/webcrawler/src/basic"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+)
+
+// response is an archived HTTP response
+type response struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+// Archive serves the responses of a WARC file, it implements the Downloader interface
+// so a crawl can be replayed without network access
+type Archive struct {
+	responses map[string]response
+	urls      []string
+	seeds     []string
+}
+
+// maxRedirects is how many redirects are followed before giving up, like the http client
+const maxRedirects = 10
+
+// Load reads every response and revisit record of a WARC file, revisits take the payload
+// of the response they refer to, and the seeds of the crawl from its warcinfo record
+func Load(r io.Reader) (*Archive, error) {
+	wr, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Archive{responses: map[string]response{}}
+	byID := map[string]response{}
+	for {
+		record, err := wr.Next()
+		if err == io.EOF {
+			return a, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if record.Type == TypeWarcinfo && a.seeds == nil {
+			a.seeds = infoSeeds(record.Block)
+			continue
+		}
+		if record.Type != TypeResponse && record.Type != TypeRevisit {
+			continue
+		}
+
+		resp, err := parseResponse(record.Block)
+		if err != nil {
+			return nil, fmt.Errorf("%w: response for [%s]: %v", ErrInvalidRecord, record.TargetURI, err)
+		}
+		if record.Type == TypeRevisit {
+			original, ok := byID[record.Field("WARC-Refers-To")]
+			if !ok {
+				original, ok = a.responses[record.Field("WARC-Refers-To-Target-URI")]
+			}
+			if !ok {
+				return nil, fmt.Errorf("%w: revisit for [%s] refers to an unknown record", ErrInvalidRecord, record.TargetURI)
+			}
+			resp.body = original.body
+		}
+
+		byID[record.ID] = resp
+		if _, ok := a.responses[record.TargetURI]; !ok {
+			a.urls = append(a.urls, record.TargetURI)
+		}
+		a.responses[record.TargetURI] = resp
+	}
+}
+
+// URLs lists the urls with a response in the order they were archived
+func (a *Archive) URLs() []string {
+	return a.urls
+}
+
+// Seeds lists the seeds of the crawl, the first url archived when the warcinfo record does
+// not list them
+func (a *Archive) Seeds() []string {
+	if len(a.seeds) > 0 {
+		return a.seeds
+	}
+	if len(a.urls) > 0 {
+		return a.urls[:1]
+	}
+	return []string{}
+}
+
+// Download fills the content with its archived response, following the archived redirects,
+// responses other than 200 fail as they did when downloaded
+func (a *Archive) Download(ctx context.Context, c *content.Content) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	address := c.Address
+	resp, ok := a.responses[address]
+	for ok && isRedirect(resp.statusCode) && resp.header.Get("Location") != "" {
+		if len(c.Hops) >= maxRedirects {
+			return fmt.Errorf("%w: stopped after %d redirects", basic.ErrExecutingRequest, maxRedirects)
+		}
+		base, err := url.Parse(address)
+		if err != nil {
+			return err
+		}
+		next, err := base.Parse(resp.header.Get("Location"))
+		if err != nil {
+			return err
+		}
+		c.Redirects = append(c.Redirects, address)
+		c.Hops = append(c.Hops, content.Hop{URL: address, StatusCode: resp.statusCode, Header: resp.header.Clone()})
+		address = next.String()
+		resp, ok = a.responses[address]
+	}
+	if !ok {
+		return fmt.Errorf("%w [%s]", ErrNotArchived, address)
+	}
+
+	c.StatusCode = resp.statusCode
+	c.Header = resp.header.Clone()
+	if len(c.Redirects) > 0 {
+		c.Location = address
+	}
+	if resp.statusCode != http.StatusOK {
+		return basic.ErrResponseStatusNotOK
+	}
+	c.ContentType = resp.header.Get("Content-Type")
+	c.Body = resp.body
+	c.CreateChecksum()
+	return nil
+}
+
+// isRedirect informs if a status code is one the http client follows
+func isRedirect(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	default:
+		return false
+	}
+}
+
+// infoSeeds reads the space separated seeds field of a warcinfo block, if any
+func infoSeeds(block []byte) []string {
+	for _, line := range strings.Split(string(block), "\r\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "seeds") {
+			return strings.Fields(value)
+		}
+	}
+	return nil
+}
+
+// parseResponse reads the HTTP response of a record block, revisits have no body
+func parseResponse(block []byte) (response, error) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(block)), nil)
+	if err != nil {
+		return response{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	// revisits keep the length of the payload they leave out
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return response{}, err
+	}
+	return response{statusCode: resp.StatusCode, header: resp.Header, body: body}, nil
+}
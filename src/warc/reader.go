@@ -0,0 +1,99 @@
+package warc
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reader reads the records of a gzip compressed WARC file
+type Reader struct {
+	br *bufio.Reader
+}
+
+// NewReader creates a Reader, the gzip members are read as a single stream
+func NewReader(r io.Reader) (*Reader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+	}
+	return &Reader{br: bufio.NewReader(gz)}, nil
+}
+
+// Next reads the next record, it returns io.EOF once there are no records left
+func (wr *Reader) Next() (Record, error) {
+	version, err := wr.readLine()
+	for err == nil && version == "" {
+		// tolerates extra line breaks between records
+		version, err = wr.readLine()
+	}
+	if err == io.EOF && version == "" {
+		return Record{}, io.EOF
+	}
+	if err != nil {
+		return Record{}, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+	}
+	if !strings.HasPrefix(version, "WARC/") {
+		return Record{}, fmt.Errorf("%w: unexpected version line [%s]", ErrInvalidRecord, version)
+	}
+
+	r := Record{Fields: map[string]string{}}
+	length := -1
+	for {
+		line, err := wr.readLine()
+		if err != nil {
+			return Record{}, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+		}
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return Record{}, fmt.Errorf("%w: malformed field [%s]", ErrInvalidRecord, line)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+		switch strings.ToLower(name) {
+		case "warc-type":
+			r.Type = value
+		case "warc-record-id":
+			r.ID = value
+		case "warc-date":
+			if r.Date, err = time.Parse(time.RFC3339Nano, value); err != nil {
+				return Record{}, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+			}
+		case "warc-target-uri":
+			r.TargetURI = strings.Trim(value, "<>")
+		case "content-type":
+			r.ContentType = value
+		case "content-length":
+			if length, err = strconv.Atoi(value); err != nil || length < 0 {
+				return Record{}, fmt.Errorf("%w: invalid content length [%s]", ErrInvalidRecord, value)
+			}
+		default:
+			r.Fields[name] = value
+		}
+	}
+	if length < 0 {
+		return Record{}, fmt.Errorf("%w: missing content length", ErrInvalidRecord)
+	}
+
+	r.Block = make([]byte, length)
+	if _, err := io.ReadFull(wr.br, r.Block); err != nil {
+		return Record{}, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+	}
+	return r, nil
+}
+
+// readLine reads a line without its line break
+func (wr *Reader) readLine() (string, error) {
+	line, err := wr.br.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
@@ -0,0 +1,62 @@
+// Package warc writes and reads crawls in the WARC 1.1 format, one gzip member per record
+package warc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thiagolcmelo/webcrawler/src/basic"
+)
+
+const (
+	// Version is the WARC version written in every record
+	Version = "WARC/1.1"
+
+	// TypeWarcinfo describes the crawl that wrote the records following it
+	TypeWarcinfo = "warcinfo"
+	// TypeRequest holds the HTTP request sent for a url
+	TypeRequest = "request"
+	// TypeResponse holds the full HTTP response received for a url
+	TypeResponse = "response"
+	// TypeRevisit holds the HTTP response headers of a url whose payload was archived before
+	TypeRevisit = "revisit"
+
+	// ProfileIdenticalPayload is the revisit profile for payloads identical to an earlier response
+	ProfileIdenticalPayload = "http://netpreserve.org/warc/1.1/revisit/identical-payload-digest"
+
+	dateLayout = "2006-01-02T15:04:05.000000000Z"
+)
+
+var (
+	// ErrInvalidRecord should be used when a record cannot be read
+	ErrInvalidRecord = errors.New("invalid warc record")
+	// ErrNotArchived should be used when a url has no response in the archive, it fails like a
+	// request that could not be executed
+	ErrNotArchived = fmt.Errorf("%w, url not archived", basic.ErrExecutingRequest)
+)
+
+// Record is a WARC record, the named fields not listed in the struct are kept in Fields
+type Record struct {
+	Type        string
+	ID          string
+	Date        time.Time
+	TargetURI   string
+	ContentType string
+	Fields      map[string]string
+	Block       []byte
+}
+
+// Field returns a named field not listed in the struct, or an empty string, names are case insensitive
+func (r Record) Field(name string) string {
+	if value, ok := r.Fields[name]; ok {
+		return value
+	}
+	for field, value := range r.Fields {
+		if strings.EqualFold(field, name) {
+			return value
+		}
+	}
+	return ""
+}
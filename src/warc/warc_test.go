@@ -0,0 +1,305 @@
+package warc_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/thiagolcmelo/webcrawler/src/basic"
+	"github.com/thiagolcmelo/webcrawler/src/content"
+	"github.com/thiagolcmelo/webcrawler/src/warc"
+)
+
+func newContent(t *testing.T, address string, body string) content.Content {
+	c, err := content.NewContentWithBody(address, []byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.StatusCode = http.StatusOK
+	c.Header.Set("Content-Type", "text/html")
+	c.ContentType = "text/html"
+	return c
+}
+
+func readAll(t *testing.T, r io.Reader) []warc.Record {
+	wr, err := warc.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := []warc.Record{}
+	for {
+		record, err := wr.Next()
+		if err == io.EOF {
+			return records
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+}
+
+func TestWriter_WriteExchange(t *testing.T) {
+	var buf bytes.Buffer
+	w := warc.NewWriter(&buf)
+	if err := w.WriteInfo(map[string]string{"software": "webcrawler"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []content.Content{
+		newContent(t, "http://domain.com/", "<a href='/copy'>home</a>"),
+		newContent(t, "http://domain.com/copy", "<a href='/copy'>home</a>"),
+	} {
+		if err := w.WriteExchange(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records := readAll(t, &buf)
+	actual := []string{}
+	for _, r := range records {
+		actual = append(actual, r.Type+" "+r.TargetURI)
+	}
+	expected := []string{
+		"warcinfo ",
+		"request http://domain.com/",
+		"response http://domain.com/",
+		"request http://domain.com/copy",
+		"revisit http://domain.com/copy",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected records (-expected +actual):\n%s", diff)
+	}
+
+	if string(records[0].Block) != "software: webcrawler\r\n" {
+		t.Errorf("unexpected warcinfo block %q", records[0].Block)
+	}
+	if records[1].Field("warc-concurrent-to") != records[2].ID {
+		t.Errorf("expected the request to be concurrent to its response")
+	}
+	if !strings.HasPrefix(string(records[1].Block), "GET / HTTP/1.1\r\nHost: domain.com\r\n") {
+		t.Errorf("unexpected request block %q", records[1].Block)
+	}
+	if !strings.HasSuffix(string(records[2].Block), "\r\n\r\n<a href='/copy'>home</a>") {
+		t.Errorf("expected the response block to end with the body, got %q", records[2].Block)
+	}
+
+	revisit := records[4]
+	if revisit.Field("WARC-Refers-To") != records[2].ID {
+		t.Errorf("expected the revisit to refer to %s, got %s", records[2].ID, revisit.Field("WARC-Refers-To"))
+	}
+	if revisit.Field("WARC-Payload-Digest") != records[2].Field("WARC-Payload-Digest") {
+		t.Errorf("expected the revisit to have the payload digest of the response")
+	}
+	if strings.Contains(string(revisit.Block), "home") {
+		t.Errorf("expected the revisit block to leave the payload out, got %q", revisit.Block)
+	}
+}
+
+func TestWriter_GzipMemberPerRecord(t *testing.T) {
+	var buf bytes.Buffer
+	w := warc.NewWriter(&buf)
+	for i := 0; i < 3; i++ {
+		if err := w.Write(warc.Record{Type: warc.TypeWarcinfo}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	members := 0
+	for {
+		gz.Multistream(false)
+		if _, err := io.Copy(io.Discard, gz); err != nil {
+			t.Fatal(err)
+		}
+		members++
+		if err := gz.Reset(&buf); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if members != 3 {
+		t.Errorf("expected 3 gzip members, got %d", members)
+	}
+}
+
+func TestReader_Next(t *testing.T) {
+	type testCase struct {
+		testName    string
+		warc        string
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{
+			testName:    "valid_record",
+			warc:        "WARC/1.1\r\nWARC-Type: resource\r\nContent-Length: 2\r\n\r\nok\r\n\r\n",
+			expectedErr: io.EOF,
+		},
+		{
+			testName:    "missing_version",
+			warc:        "HTTP/1.1 200 OK\r\n\r\n",
+			expectedErr: warc.ErrInvalidRecord,
+		},
+		{
+			testName:    "missing_content_length",
+			warc:        "WARC/1.1\r\nWARC-Type: resource\r\n\r\nok\r\n\r\n",
+			expectedErr: warc.ErrInvalidRecord,
+		},
+		{
+			testName:    "truncated_block",
+			warc:        "WARC/1.1\r\nWARC-Type: resource\r\nContent-Length: 20\r\n\r\nok",
+			expectedErr: warc.ErrInvalidRecord,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			gz.Write([]byte(tc.warc))
+			gz.Close()
+
+			wr, err := warc.NewReader(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for err == nil {
+				_, err = wr.Next()
+			}
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+// redirected is a content reached from its address through a permanent redirect to location
+func redirected(t *testing.T, address string, location string, body string) content.Content {
+	c := newContent(t, location, body)
+	c.Address = address
+	c.Redirects = []string{address}
+	c.Hops = []content.Hop{{URL: address, StatusCode: http.StatusMovedPermanently, Header: http.Header{"Location": {location}}}}
+	c.Location = location
+	return c
+}
+
+// missing is a content that got a not found response
+func missing(t *testing.T, address string) content.Content {
+	c, err := content.NewContent(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.StatusCode = http.StatusNotFound
+	return c
+}
+
+func TestWriter_WriteExchange_EveryResponse(t *testing.T) {
+	var buf bytes.Buffer
+	w := warc.NewWriter(&buf)
+	for _, c := range []content.Content{
+		redirected(t, "http://domain.com/old", "http://domain.com/new", "new"),
+		missing(t, "http://domain.com/missing"),
+	} {
+		if err := w.WriteExchange(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	actual := []string{}
+	for _, r := range readAll(t, &buf) {
+		line := r.Type + " " + r.TargetURI
+		if r.Type == warc.TypeResponse {
+			line += " " + strings.SplitN(string(r.Block), "\r\n", 2)[0]
+		}
+		actual = append(actual, line)
+	}
+	expected := []string{
+		"request http://domain.com/old",
+		"response http://domain.com/old HTTP/1.1 301 Moved Permanently",
+		"request http://domain.com/new",
+		"response http://domain.com/new HTTP/1.1 200 OK",
+		"request http://domain.com/missing",
+		"response http://domain.com/missing HTTP/1.1 404 Not Found",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected records (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestArchive_Download(t *testing.T) {
+	var buf bytes.Buffer
+	w := warc.NewWriter(&buf)
+	if err := w.WriteInfo(map[string]string{"seeds": "http://domain.com/ http://other.com/"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []content.Content{
+		newContent(t, "http://domain.com/", "same body"),
+		newContent(t, "http://domain.com/copy", "same body"),
+		redirected(t, "http://domain.com/old", "http://domain.com/new", "new body"),
+		missing(t, "http://domain.com/missing"),
+	} {
+		if err := w.WriteExchange(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	archive, err := warc.Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedURLs := []string{"http://domain.com/", "http://domain.com/copy", "http://domain.com/old", "http://domain.com/new", "http://domain.com/missing"}
+	if diff := cmp.Diff(expectedURLs, archive.URLs()); diff != "" {
+		t.Errorf("unexpected urls (-expected +actual):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"http://domain.com/", "http://other.com/"}, archive.Seeds()); diff != "" {
+		t.Errorf("unexpected seeds (-expected +actual):\n%s", diff)
+	}
+
+	type testCase struct {
+		testName         string
+		url              string
+		expectedBody     string
+		expectedLocation string
+		expectedErr      error
+	}
+
+	testCases := []testCase{
+		{testName: "response", url: "http://domain.com/", expectedBody: "same body"},
+		{testName: "revisit_takes_the_original_payload", url: "http://domain.com/copy", expectedBody: "same body"},
+		{testName: "redirect_is_followed", url: "http://domain.com/old", expectedBody: "new body", expectedLocation: "http://domain.com/new"},
+		{testName: "status_not_ok", url: "http://domain.com/missing", expectedBody: "", expectedErr: basic.ErrResponseStatusNotOK},
+		{testName: "url_not_archived", url: "http://domain.com/gone", expectedBody: "", expectedErr: warc.ErrNotArchived},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			c, err := content.NewContent(tc.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = archive.Download(context.Background(), &c)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected %v, got %v", tc.expectedErr, err)
+			}
+			if string(c.Body) != tc.expectedBody {
+				t.Errorf("expected body %q, got %q", tc.expectedBody, c.Body)
+			}
+			if tc.expectedErr == nil && (c.StatusCode != http.StatusOK || c.ContentType != "text/html") {
+				t.Errorf("expected status 200 and text/html, got %d and %s", c.StatusCode, c.ContentType)
+			}
+			if c.Location != tc.expectedLocation {
+				t.Errorf("expected location %q, got %q", tc.expectedLocation, c.Location)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/thiagolcmelo/webcrawler/src/content"
+)
+
+// Writer writes records as gzip members, it is safe for concurrent use
+type Writer struct {
+	mu       sync.Mutex
+	w        io.Writer
+	payloads map[string]Record
+}
+
+// NewWriter creates a Writer
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w, payloads: map[string]Record{}}
+}
+
+// WriteInfo writes a warcinfo record whose block lists the fields, sorted by name
+func (ww *Writer) WriteInfo(fields map[string]string) error {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var block bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&block, "%s: %s\r\n", name, fields[name])
+	}
+	return ww.Write(Record{Type: TypeWarcinfo, ContentType: "application/warc-fields", Block: block.Bytes()})
+}
+
+// Write writes a record, the id and date are filled in when empty
+func (ww *Writer) Write(r Record) error {
+	ww.mu.Lock()
+	defer ww.mu.Unlock()
+	return ww.write(r)
+}
+
+// WriteExchange writes the requests and the responses of a downloaded content, whatever their
+// status, each redirect followed first and the final response under the url that gave it, the
+// final response is a revisit when a response with the same payload was written before
+func (ww *Writer) WriteExchange(c content.Content) error {
+	ww.mu.Lock()
+	defer ww.mu.Unlock()
+
+	for _, hop := range c.Hops {
+		response := Record{
+			ID:          newID(),
+			Date:        time.Now().UTC(),
+			Type:        TypeResponse,
+			TargetURI:   hop.URL,
+			ContentType: "application/http; msgtype=response",
+			Block:       httpResponse(hop.StatusCode, hop.Header, nil, true),
+		}
+		if err := ww.writePair(response); err != nil {
+			return err
+		}
+	}
+
+	target := c.Address
+	if c.Location != "" {
+		target = c.Location
+	}
+	digest := payloadDigest(c.Body)
+	response := Record{
+		ID:          newID(),
+		Date:        time.Now().UTC(),
+		TargetURI:   target,
+		ContentType: "application/http; msgtype=response",
+		Fields:      map[string]string{"WARC-Payload-Digest": digest},
+	}
+	if original, ok := ww.payloads[digest]; ok && len(c.Body) > 0 {
+		response.Type = TypeRevisit
+		response.Fields["WARC-Profile"] = ProfileIdenticalPayload
+		response.Fields["WARC-Refers-To"] = original.ID
+		response.Fields["WARC-Refers-To-Target-URI"] = original.TargetURI
+		response.Fields["WARC-Refers-To-Date"] = original.Date.Format(dateLayout)
+		response.Block = httpResponse(c.StatusCode, c.Header, c.Body, false)
+	} else {
+		response.Type = TypeResponse
+		response.Block = httpResponse(c.StatusCode, c.Header, c.Body, true)
+		if len(c.Body) > 0 {
+			ww.payloads[digest] = response
+		}
+	}
+	return ww.writePair(response)
+}
+
+// writePair writes a response preceded by the request it answers, the caller must hold the lock
+func (ww *Writer) writePair(response Record) error {
+	request := Record{
+		Type:        TypeRequest,
+		Date:        response.Date,
+		TargetURI:   response.TargetURI,
+		ContentType: "application/http; msgtype=request",
+		Fields:      map[string]string{"WARC-Concurrent-To": response.ID},
+		Block:       httpRequest(response.TargetURI),
+	}
+	if err := ww.write(request); err != nil {
+		return err
+	}
+	return ww.write(response)
+}
+
+// write writes a record as its own gzip member, the caller must hold the lock
+func (ww *Writer) write(r Record) error {
+	if r.ID == "" {
+		r.ID = newID()
+	}
+	if r.Date.IsZero() {
+		r.Date = time.Now().UTC()
+	}
+
+	var header bytes.Buffer
+	header.WriteString(Version + "\r\n")
+	fmt.Fprintf(&header, "WARC-Type: %s\r\n", r.Type)
+	fmt.Fprintf(&header, "WARC-Record-ID: %s\r\n", r.ID)
+	fmt.Fprintf(&header, "WARC-Date: %s\r\n", r.Date.UTC().Format(dateLayout))
+	if r.TargetURI != "" {
+		fmt.Fprintf(&header, "WARC-Target-URI: %s\r\n", r.TargetURI)
+	}
+	names := make([]string, 0, len(r.Fields))
+	for name := range r.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&header, "%s: %s\r\n", name, r.Fields[name])
+	}
+	if r.ContentType != "" {
+		fmt.Fprintf(&header, "Content-Type: %s\r\n", r.ContentType)
+	}
+	fmt.Fprintf(&header, "Content-Length: %d\r\n\r\n", len(r.Block))
+
+	gz := gzip.NewWriter(ww.w)
+	for _, part := range [][]byte{header.Bytes(), r.Block, []byte("\r\n\r\n")} {
+		if _, err := gz.Write(part); err != nil {
+			return err
+		}
+	}
+	return gz.Close()
+}
+
+// httpRequest builds the request sent for a url, the client defaults are left out
+func httpRequest(address string) []byte {
+	requestURI, host := address, ""
+	if u, err := url.Parse(address); err == nil {
+		requestURI, host = u.RequestURI(), u.Host
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "GET %s HTTP/1.1\r\nHost: %s\r\n\r\n", requestURI, host)
+	return b.Bytes()
+}
+
+// httpResponse builds a response received, its length is the one of the body as read, which
+// the client may have decompressed
+func httpResponse(statusCode int, header http.Header, body []byte, withBody bool) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "HTTP/1.1 %d %s\r\n", statusCode, http.StatusText(statusCode))
+	header = header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Del("Transfer-Encoding")
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	header.Write(&b)
+	b.WriteString("\r\n")
+	if withBody {
+		b.Write(body)
+	}
+	return b.Bytes()
+}
+
+// payloadDigest is the SHA-1 of a payload in base 32, as usual in WARC files
+func payloadDigest(payload []byte) string {
+	sum := sha1.Sum(payload)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
+
+// newID creates a random record id as a version 4 uuid
+func newID() string {
+	var u [16]byte
+	rand.Read(u[:])
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}